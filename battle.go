@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Meduza3/talisman/engine"
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// battleRoundsShown is how much of the transcript fits on the battle screen.
+const battleRoundsShown = 8
+
+// drawBattle draws the fight on the open card: both sides' health, the
+// rounds so far and the moves the hero can make. Input is handled in the
+// main loop.
+func drawBattle(g *Game) {
+	b, hero := g.Battle, g.Current()
+	w, h := int32(700), int32(440)
+	x := int32(screenWidth)/2 - w/2
+	y := int32(screenHeight-menuHeight)/2 - h/2 + 20
+
+	rl.DrawRectangle(0, 0, screenWidth, screenHeight, rl.NewColor(0, 0, 0, 120))
+	rl.DrawRectangle(x-6, y-6, w+12, h+12, rl.NewColor(0, 0, 0, 120))
+	rl.DrawRectangle(x, y, w, h, rl.NewColor(45, 35, 25, 250))
+	rl.DrawRectangleLines(x, y, w, h, hudAccent)
+
+	// title bar
+	rl.DrawRectangle(x, y, w, 50, rl.NewColor(30, 25, 20, 200))
+	drawText(b.Card.Title, x+20, y+14, 24, hudAccent)
+	stat := fmt.Sprintf("Fought with %s", b.Stat.Name())
+	drawText(stat, x+w-20-rl.MeasureText(stat, 16), y+19, 16, hudSub)
+
+	// the two sides
+	half := w / 2
+	side := func(sx int32, name string, line string, hp, maxHP int) {
+		drawText(name, sx, y+64, 20, hudText)
+		drawText(line, sx, y+88, 16, hudSub)
+		drawHPBar(sx, y+112, half-60, 14, hp, maxHP)
+		drawText(fmt.Sprintf("%d/%d", hp, maxHP), sx+half-52, y+110, 16, hudText)
+	}
+	you := fmt.Sprintf("%s %d", b.Stat, hero.Stat(b.Stat))
+	if g.FightBonus > 0 {
+		you += fmt.Sprintf(" + spells %d", g.FightBonus)
+	}
+	if f := hero.FollowerBonus(); f > 0 {
+		you += fmt.Sprintf(" + followers %d", f)
+	}
+	you += " + d6"
+	side(x+20, hero.Name, you, hero.Health, max(engine.StartHealth, hero.Health))
+	foe := "Foe"
+	if len(b.Card.Abilities) > 0 {
+		foe += " - " + b.Card.AbilityList()
+	}
+	side(x+half+20, foe, fmt.Sprintf("%s %d + d6", b.Stat, b.Card.Power()), b.MonsterHP, b.MonsterMax)
+	drawHairlineY(x+half, y+60, y+136)
+	drawHairlineX(x+12, x+w-12, y+146)
+
+	// transcript, newest last
+	first := max(0, len(b.Rounds)-battleRoundsShown)
+	if len(b.Rounds) == 0 {
+		drawText("The fight begins. Choose your move.", x+20, y+160, 18, hudSub)
+	}
+	for i, r := range b.Rounds[first:] {
+		col := hudText
+		switch {
+		case r.Taken > 0:
+			col = hpWarn
+		case r.Dealt > 0:
+			col = hpOK
+		case r.Warded:
+			col = hudSub
+		}
+		drawText(fmt.Sprintf("%2d  %s", first+i+1, r), x+20, y+160+int32(i)*24, 18, col)
+	}
+
+	// moves, or how it ended
+	footY := y + h - 40
+	rl.DrawRectangle(x, footY-8, w, 48, rl.NewColor(30, 25, 20, 200))
+	if g.CardResolved {
+		col := hudAccent
+		if b.Outcome == engine.OutcomeLoss {
+			col = hpWarn
+		}
+		drawText(g.CardMsg, x+20, footY, 18, col)
+		drawText("Enter = close", x+w-20-rl.MeasureText("Enter = close", 16), footY+20, 16, hudSub)
+		return
+	}
+	moves := fmt.Sprintf("A = attack   E = evade   F = flee (d6 %d+)", engine.FleeRoll)
+	if slot := hero.PotionSlot(); slot >= 0 {
+		moves += fmt.Sprintf("   I = drink %s", hero.Cards[slot].Title)
+	}
+	if slot := hero.SpellSlot(engine.SpellBattle); slot >= 0 {
+		moves += fmt.Sprintf("   C = cast %s (+%d)", hero.Spells[slot].Title, hero.Spells[slot].Spell.Power)
+	}
+	drawText(moves, x+20, footY+4, 16, hudText)
+}
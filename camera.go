@@ -1,6 +1,9 @@
 package main
 
-import rl "github.com/gen2brain/raylib-go/raylib"
+import (
+	"github.com/Meduza3/talisman/engine"
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
 
 var cam rl.Camera2D
 
@@ -14,8 +17,8 @@ func clamp(v, lo, hi float32) float32 {
 	return v
 }
 
-func playerPos(w *World, g *Game) rl.Vector2 {
-	return w.Loops[g.Player.At.Loop].Tiles[g.Player.At.Index].Pos
+func playerPos(w *engine.World, g *Game) rl.Vector2 {
+	return vec(w.Loops[g.Current().At.Loop].Tiles[g.Current().At.Index].Pos)
 }
 
 // Smooth follow (lerp). Set `followSpeed` higher if you want snappier motion.
@@ -2,8 +2,9 @@ package main
 
 import (
 	"fmt"
-	"math/rand"
+	"strings"
 
+	"github.com/Meduza3/talisman/engine"
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
@@ -14,85 +15,14 @@ func drawTextCard(text string, x, y int32, fontSize int32, color rl.Color) {
 	// rl.DrawText(text, x, y, fontSize, color)
 }
 
-type CardType string
+// displayCard draws the card modal and reports the confirm/cancel keys. For
+// a fight it also shows the stat hero and card will roll with, and for the
+// other encounters what they hold.
+func displayCard(c *engine.Card, hero *engine.Player, bonus int) (confirm, cancel bool) {
+	lines := encounterLines(c, hero)
 
-var monsterType CardType = "monster"
-var magicMonsterType CardType = "magicMonster"
-var buffType CardType = "buff"
-var shopItemType CardType = "shopItem"
-
-type Card struct {
-	Type        CardType
-	Strength    int
-	Magic       int
-	Title, Text string
-}
-
-type Deck struct {
-	Name  string
-	Cards []Card
-}
-
-var deck1 Deck = Deck{
-	Name: "deck1",
-	Cards: []Card{
-		NewMonster(2),
-		NewBuff(1),
-		NewMonster(3),
-	},
-}
-
-var deck2 Deck = Deck{
-	Name: "deck2",
-	Cards: []Card{
-		NewMonster(2),
-		NewBuff(2),
-		NewMonster(5),
-	},
-}
-
-var deck3 Deck = Deck{
-	Name: "deck3",
-	Cards: []Card{
-		NewMonster(10),
-		NewBuff(5),
-		NewMonster(15),
-	},
-}
-
-var deck4 Deck = Deck{
-	Name: "deck4",
-	Cards: []Card{
-		NewMonster(8),
-		NewBuff(3),
-		NewMonster(12),
-		NewMonster(10),
-	},
-}
-
-var deck5 Deck = Deck{
-	Name: "deck5",
-	Cards: []Card{
-		NewMonster(15),
-		NewBuff(6),
-		NewMonster(18),
-		NewMonster(20),
-	},
-}
-
-var deck6 Deck = Deck{
-	Name: "deck6",
-	Cards: []Card{
-		NewMonster(25),
-		NewBuff(8),
-		NewMonster(30),
-		NewMonster(22),
-	},
-}
-
-func (c *Card) Display() (confirm, cancel bool) {
-	// modal rect (centered above the menu bar)
-	w, h := float32(540), float32(260)
+	// modal rect (centered above the menu bar); long tables make it taller
+	w, h := float32(540), float32(260+22*max(len(lines)-3, 0))
 	cx := float32(screenWidth) / 2
 	cy := float32(screenHeight-menuHeight)/2 + 40
 	x, y := int32(cx-w/2), int32(cy-h/2)
@@ -101,11 +31,20 @@ func (c *Card) Display() (confirm, cancel bool) {
 	rl.DrawRectangle(x-4, y-4, int32(w)+8, int32(h)+8, rl.NewColor(0, 0, 0, 40))
 	rl.DrawRectangle(x, y, int32(w), int32(h), rl.NewColor(245, 245, 245, 255))
 	rl.DrawRectangleLines(x, y, int32(w), int32(h), rl.DarkGray)
+	if col, ok := rarityColor(c.Rarity); ok {
+		rl.DrawRectangleLinesEx(rl.NewRectangle(float32(x-3), float32(y-3), w+6, h+6), 4, col)
+		label := strings.ToUpper(string(c.Rarity))
+		drawTextCard(label, x+int32(w)-18-rl.MeasureText(label, 16), y+22, 16, col)
+	}
 
 	// title
 	title := c.Title
+	if c.IsBoss() {
+		// bosses wear a crest: their reward under the title
+		drawTextCard("BOSS - beat it for "+c.Reward.Title, x+18, y+46, 16, rl.NewColor(150, 60, 40, 255))
+	}
 	if title == "" {
-		if c.Type == monsterType {
+		if c.Type == engine.MonsterType {
 			title = "A Monster Appears!"
 		} else {
 			title = "You Found a Buff!"
@@ -117,22 +56,128 @@ func (c *Card) Display() (confirm, cancel bool) {
 	body := c.Text
 	if body == "" {
 		switch c.Type {
-		case monsterType:
+		case engine.MonsterType:
 			body = fmt.Sprintf("Monster Strength: %d\nPress Enter to fight.\nEsc to ignore.", c.Strength)
-		case buffType:
+		case engine.BuffType:
 			body = fmt.Sprintf("Gain +%d Strength.\nPress Enter to take it.\nEsc to ignore.", c.Strength)
 		}
 	}
 	drawMultiline(body, x+18, y+64, 22, rl.DarkGray, int(w)-36)
 
+	// fight line: which stat decides it
+	if stat := c.FightStat(); stat != "" {
+		col := rl.NewColor(180, 80, 60, 255)
+		if stat == engine.StatMagic {
+			col = rl.NewColor(120, 90, 170, 255)
+		}
+		line := fmt.Sprintf("Fought with %s: your %s %d", stat.Name(), stat, hero.Stat(stat))
+		if bonus > 0 {
+			line += fmt.Sprintf(" + spells %d", bonus)
+		}
+		if f := hero.FollowerBonus(); f > 0 {
+			line += fmt.Sprintf(" + followers %d", f)
+		}
+		line += fmt.Sprintf(" + d6 vs %d + d6, %d HP", c.Power(), c.HitPoints())
+		drawTextCard(line, x+18, y+int32(h)-66, 20, col)
+		// what it does beyond its power
+		for i, a := range c.Abilities {
+			drawTextCard(fmt.Sprintf("%s: %s", a, a.Describe()), x+18, y+int32(h)-92-int32(len(c.Abilities)-1-i)*22, 18, col)
+		}
+	}
+
+	// what an encounter holds: offers, a dice table, a check or loot
+	for i, line := range lines {
+		drawTextCard(line, x+18, y+int32(h)-66-int32(len(lines)-1-i)*22, 18, rl.NewColor(60, 100, 140, 255))
+	}
+
+	footer := "Enter = confirm   Esc = cancel"
+	switch {
+	case c.FightStat() != "":
+		footer = "Enter = fight   Esc = walk away"
+	case c.Type == engine.EventType:
+		footer = "Enter = brace yourself"
+	case c.Type == engine.StrangerType:
+		footer = fmt.Sprintf("1-%d = take an offer   Esc = walk away", len(c.Offers))
+	case c.Type == engine.PlaceType:
+		footer = "Enter = roll the die   Esc = walk on"
+	case c.Type == engine.TrapType:
+		footer = fmt.Sprintf("Enter = test your %s", c.CheckStat().Name())
+	case c.Type == engine.TreasureType:
+		footer = "Enter = take it   Esc = leave it"
+	case c.Type == engine.ScriptedType:
+		footer = "Enter = go on   Esc = walk away"
+	}
+	if slot := hero.SpellSlot(engine.SpellBattle); slot >= 0 && c.FightStat() != "" {
+		s := hero.Spells[slot]
+		footer += fmt.Sprintf("   C = cast %s (+%d)", s.Title, s.Spell.Power)
+	}
+
 	// footer
-	drawTextCard("Enter = confirm   Esc = cancel", x+18, y+int32(h)-36, 20, rl.Gray)
+	drawTextCard(footer, x+18, y+int32(h)-36, 20, rl.Gray)
 
 	// Return input states for external handling
 	confirm = rl.IsKeyPressed(rl.KeyEnter) || rl.IsKeyPressed(rl.KeyKpEnter)
 	cancel = rl.IsKeyPressed(rl.KeyEscape)
 	return
 }
+
+// encounterLines is what the card modal lists for events, strangers,
+// places, traps, treasure and scripted cards, or nothing for other cards.
+func encounterLines(c *engine.Card, hero *engine.Player) []string {
+	var lines []string
+	switch c.Type {
+	case engine.EventType:
+		lines = append(lines, "Every hero: "+c.Effect.Describe())
+	case engine.StrangerType:
+		for i, e := range c.Offers {
+			line := fmt.Sprintf("%d) %s: %s", i+1, e.Text, e.Describe())
+			if why := hero.CannotTake(e); why != "" {
+				line += " (" + why + ")"
+			}
+			lines = append(lines, line)
+		}
+	case engine.PlaceType:
+		for i, e := range c.Table {
+			to := 6
+			if i+1 < len(c.Table) {
+				to = c.Table[i+1].From - 1
+			}
+			roll := fmt.Sprintf("%d-%d", e.From, to)
+			if e.From == to {
+				roll = fmt.Sprint(to)
+			}
+			lines = append(lines, fmt.Sprintf("%s: %s (%s)", roll, e.Text, e.Describe()))
+		}
+	case engine.TrapType:
+		stat := c.CheckStat()
+		lines = append(lines, fmt.Sprintf("Your %s %d + d6 vs %d, or lose %d health", stat, hero.Stat(stat), c.Difficulty(), c.Damage))
+	case engine.ScriptedType:
+		lines = append(lines, c.Script.Statements()...)
+	case engine.TreasureType:
+		if c.Gold > 0 {
+			lines = append(lines, fmt.Sprintf("%d gold", c.Gold))
+		}
+		if c.Reward != nil {
+			lines = append(lines, "and "+c.Reward.Title)
+		}
+	}
+	return lines
+}
+
+// rarityColor is the frame round a card of rarity r. Common cards have no
+// frame of their own.
+func rarityColor(r engine.Rarity) (rl.Color, bool) {
+	switch r {
+	case engine.RarityUncommon:
+		return rl.NewColor(90, 170, 90, 255), true
+	case engine.RarityRare:
+		return rl.NewColor(70, 130, 220, 255), true
+	case engine.RarityLegendary:
+		return rl.NewColor(235, 140, 40, 255), true
+	}
+	return rl.Color{}, false
+}
+
 func drawMultiline(s string, x, y int32, fs int32, col rl.Color, maxWidth int) {
 	lines := []string{""}
 	for _, word := range splitWordsPreserveNL(s) {
@@ -182,152 +227,3 @@ func splitWordsPreserveNL(s string) []string {
 	}
 	return out
 }
-
-// ---------- Card factories ----------
-
-func NewMonster(str int) Card {
-	if rand.Intn(2) == 1 {
-		return NewMonsterStrength(str)
-	} else {
-		return NewMonsterMagic(str)
-	}
-}
-
-func NewMonsterStrength(str int) Card {
-	return Card{
-		Type:     monsterType,
-		Strength: str,
-		Title:    "A Monster Appears!",
-		Text:     fmt.Sprintf("A fearsome foe blocks the way.\nMonster Strength: %d", str),
-	}
-}
-func NewMonsterMagic(magic int) Card {
-	return Card{
-		Type:  magicMonsterType,
-		Magic: magic,
-		Title: "A Magic Monster Appears!",
-		Text:  fmt.Sprintf("A fearsome foe blocks the way.\nMonster Magic: %d", magic),
-	}
-}
-func NewBuff(str int) Card {
-	if rand.Intn(2) == 1 {
-		return NewBuffStrength(str)
-	} else {
-		return NewBuffMagic(str)
-	}
-}
-
-func NewBuffStrength(str int) Card {
-	return Card{
-		Type:     buffType,
-		Strength: str,
-		Title:    "A Blessing!",
-		Text:     fmt.Sprintf("A boon empowers you.\nGain +%d Strength.", str),
-	}
-}
-func NewBuffMagic(str int) Card {
-	return Card{
-		Type:  buffType,
-		Magic: str,
-		Title: "A Magic Blessing!",
-		Text:  fmt.Sprintf("A boon empowers you.\nGain +%d Magic.", str),
-	}
-}
-
-// Randomized helpers (tweak ranges to taste)
-func RandMonster(minStr, maxStr int) Card {
-	if maxStr < minStr {
-		maxStr = minStr
-	}
-	return NewMonster(minStr + rand.Intn(maxStr-minStr+1))
-}
-func RandBuff(minStr, maxStr int) Card {
-	if maxStr < minStr {
-		maxStr = minStr
-	}
-	return NewBuff(minStr + rand.Intn(maxStr-minStr+1))
-}
-func RandCard() Card {
-	if rand.Float32() < 0.55 {
-		return RandMonster(2, 8)
-	}
-	return RandBuff(1, 3)
-}
-
-// Shop-specific decks for each shopkeeper type
-var mysticShopDeck Deck = Deck{
-	Name: "mysticShopDeck",
-	Cards: []Card{
-		{Type: shopItemType, Magic: 2, Title: "Wizard's Amulet", Text: "An ancient amulet crackling with arcane power.\nGain +2 Magic."},
-		{Type: shopItemType, Magic: 3, Title: "Staff of Elements", Text: "A powerful staff that channels elemental forces.\nGain +3 Magic."},
-		{Type: shopItemType, Magic: 1, Title: "Mana Potion", Text: "A shimmering blue liquid that restores magical essence.\nGain +1 Magic."},
-		{Type: shopItemType, Magic: 2, Title: "Enchanted Robes", Text: "Mystical robes woven with silver thread.\nGain +2 Magic."},
-		{Type: shopItemType, Magic: 3, Title: "Archmage's Crown", Text: "A crown that once belonged to the greatest wizard.\nGain +3 Magic."},
-		{Type: shopItemType, Magic: 3, Title: "Void Crystal", Text: "A dark crystal pulsing with otherworldly energy.\nGain +3 Magic."},
-	},
-}
-
-var ogreShopDeck Deck = Deck{
-	Name: "ogreShopDeck",
-	Cards: []Card{
-		{Type: shopItemType, Strength: 3, Title: "Ogre's Club", Text: "A massive wooden club used by ogre warriors.\nGain +3 Strength."},
-		{Type: shopItemType, Strength: 2, Title: "Beast Hide Armor", Text: "Thick armor made from giant beast hide.\nGain +2 Strength."},
-		{Type: shopItemType, Strength: 3, Title: "Titan's Hammer", Text: "A massive war hammer forged by titans.\nGain +3 Strength."},
-		{Type: shopItemType, Strength: 2, Title: "Giant's Belt", Text: "A leather belt once worn by a mountain giant.\nGain +2 Strength."},
-		{Type: shopItemType, Strength: 1, Title: "Stone Knuckles", Text: "Heavy stone gauntlets that crush enemies.\nGain +1 Strength."},
-		{Type: shopItemType, Strength: 3, Title: "Berserker's Charm", Text: "A wild charm that unleashes inner rage.\nGain +3 Strength."},
-	},
-}
-
-var monkeyShopDeck Deck = Deck{
-	Name: "monkeyShopDeck",
-	Cards: []Card{
-		{Type: shopItemType, Strength: 1, Magic: 1, Title: "Banana of Wisdom", Text: "A magical fruit that enhances mind and body.\nGain +1 Strength and +1 Magic."},
-		{Type: shopItemType, Magic: 2, Title: "Jungle Vine Staff", Text: "A staff made from enchanted jungle vines.\nGain +2 Magic."},
-		{Type: shopItemType, Strength: 2, Title: "Monkey Paw Gloves", Text: "Nimble gloves that increase dexterity.\nGain +2 Strength."},
-		{Type: shopItemType, Strength: 1, Magic: 2, Title: "Scholar's Banana", Text: "A fruit inscribed with ancient knowledge.\nGain +1 Strength and +2 Magic."},
-		{Type: shopItemType, Magic: 1, Title: "Chattering Scroll", Text: "A scroll that whispers jungle secrets.\nGain +1 Magic."},
-		{Type: shopItemType, Strength: 1, Title: "Swinging Rope", Text: "A rope that increases agility and strength.\nGain +1 Strength."},
-	},
-}
-
-var pigShopDeck Deck = Deck{
-	Name: "pigShopDeck",
-	Cards: []Card{
-		{Type: shopItemType, Strength: 1, Title: "Truffle Hunter's Nose", Text: "Enhances your ability to find hidden treasures.\nGain +1 Strength."},
-		{Type: shopItemType, Magic: 1, Title: "Mud Bath Essence", Text: "A relaxing potion that clears the mind.\nGain +1 Magic."},
-		{Type: shopItemType, Strength: 2, Title: "Bacon Shield", Text: "A surprisingly sturdy shield made of cured meat.\nGain +2 Strength."},
-		{Type: shopItemType, Magic: 2, Title: "Snorting Powder", Text: "A magical dust that enhances mental abilities.\nGain +2 Magic."},
-		{Type: shopItemType, Strength: 1, Magic: 1, Title: "Farm Fresh Meal", Text: "A hearty meal that nourishes body and soul.\nGain +1 Strength and +1 Magic."},
-		{Type: shopItemType, Strength: 2, Title: "Pig Iron Gauntlets", Text: "Heavy iron gloves forged in pig-shaped molds.\nGain +2 Strength."},
-	},
-}
-
-var dolphinShopDeck Deck = Deck{
-	Name: "dolphinShopDeck",
-	Cards: []Card{
-		{Type: shopItemType, Magic: 2, Title: "Pearl of the Deep", Text: "A lustrous pearl containing ocean magic.\nGain +2 Magic."},
-		{Type: shopItemType, Strength: 2, Title: "Whale Bone Sword", Text: "A sword carved from ancient whale bone.\nGain +2 Strength."},
-		{Type: shopItemType, Magic: 3, Title: "Tidal Wave Orb", Text: "An orb that commands the power of the tides.\nGain +3 Magic."},
-		{Type: shopItemType, Strength: 1, Magic: 2, Title: "Dolphin's Wisdom", Text: "Ancient knowledge from the ocean depths.\nGain +1 Strength and +2 Magic."},
-		{Type: shopItemType, Magic: 1, Title: "Sea Foam Potion", Text: "A bubbly potion made from ocean waves.\nGain +1 Magic."},
-		{Type: shopItemType, Strength: 1, Title: "Coral Armor", Text: "Living coral that protects and strengthens.\nGain +1 Strength."},
-	},
-}
-
-func RandShopCard(keeperType int) Card {
-	switch keeperType {
-	case 0:
-		return mysticShopDeck.Cards[rand.Intn(len(mysticShopDeck.Cards))]
-	case 1:
-		return ogreShopDeck.Cards[rand.Intn(len(ogreShopDeck.Cards))]
-	case 2:
-		return monkeyShopDeck.Cards[rand.Intn(len(monkeyShopDeck.Cards))]
-	case 3:
-		return pigShopDeck.Cards[rand.Intn(len(pigShopDeck.Cards))]
-	case 4:
-		return dolphinShopDeck.Cards[rand.Intn(len(dolphinShopDeck.Cards))]
-	default:
-		return mysticShopDeck.Cards[rand.Intn(len(mysticShopDeck.Cards))]
-	}
-}
@@ -36,4 +36,50 @@ func lighten(c rl.Color, factor float32) rl.Color {
 // Talisman-inspired colors
 var shopGold = rl.NewColor(220, 180, 60, 255)      // Rich gold for shops
 var bridgeStone = rl.NewColor(140, 130, 120, 255)  // Ancient stone bridges
-var playerColor = rl.NewColor(220, 50, 50, 255)    // Bright red for player
+var crownGold = rl.NewColor(230, 190, 60, 255)     // The Crown and the Talisman that opens it
+var potionRed = rl.NewColor(200, 70, 100, 255)     // Healing potions
+var followerTeal = rl.NewColor(80, 170, 160, 255)  // Followers
+var bossBone = rl.NewColor(235, 225, 200, 255)     // Region bosses
+var monsterRed = rl.NewColor(180, 80, 60, 255)     // Monsters left on the map
+var monsterViolet = rl.NewColor(120, 90, 170, 255) // ...and magic monsters
+
+// drawCrownGlyph marks the middle of The Crown: a three-pointed crown of
+// the given width centred on (cx, cy).
+func drawCrownGlyph(cx, cy, w float32) {
+	h := w * 0.6
+	l := cx - w/2
+	base := cy + h/2
+	band := base - h*0.35
+	rl.DrawRectangleV(rl.NewVector2(l, band), rl.NewVector2(w, base-band), crownGold)
+	for i := float32(0); i < 3; i++ {
+		x0 := l + i*w/3
+		rl.DrawTriangle(rl.NewVector2(x0+w/6, cy-h/2), rl.NewVector2(x0, band), rl.NewVector2(x0+w/3, band), crownGold)
+		rl.DrawCircleV(rl.NewVector2(x0+w/6, cy-h/2), w/20, hudAccent)
+	}
+	rl.DrawRectangleLinesEx(rl.NewRectangle(l, band, w, base-band), 2, darken(crownGold, 0.5))
+}
+
+// drawMonsterGlyph marks a tile a monster still waits on: a horned head r
+// wide centred on (cx, cy), in col, with the monster's power on it.
+func drawMonsterGlyph(cx, cy, r float32, col rl.Color, power string) {
+	horn := darken(col, 0.3)
+	rl.DrawTriangle(rl.NewVector2(cx-r*0.45, cy-r*0.55), rl.NewVector2(cx-r*0.4, cy-r*0.1), rl.NewVector2(cx-r*0.15, cy-r*0.3), horn)
+	rl.DrawTriangle(rl.NewVector2(cx+r*0.45, cy-r*0.55), rl.NewVector2(cx+r*0.15, cy-r*0.3), rl.NewVector2(cx+r*0.4, cy-r*0.1), horn)
+	rl.DrawCircleV(rl.NewVector2(cx, cy), r*0.36, col)
+	rl.DrawCircleLines(int32(cx), int32(cy), r*0.36, horn)
+	fs := int32(r * 0.4)
+	drawText(power, int32(cx)-rl.MeasureText(power, fs)/2, int32(cy)-fs/2, fs, rl.RayWhite)
+}
+
+// drawBossGlyph marks a tile a region boss guards: a skull r wide centred
+// on (cx, cy).
+func drawBossGlyph(cx, cy, r float32) {
+	rl.DrawCircleV(rl.NewVector2(cx, cy-r*0.1), r*0.5, bossBone)
+	rl.DrawRectangleV(rl.NewVector2(cx-r*0.28, cy+r*0.2), rl.NewVector2(r*0.56, r*0.3), bossBone)
+	eye := darken(bossBone, 0.2)
+	rl.DrawCircleV(rl.NewVector2(cx-r*0.2, cy-r*0.12), r*0.12, eye)
+	rl.DrawCircleV(rl.NewVector2(cx+r*0.2, cy-r*0.12), r*0.12, eye)
+	for i := float32(-1); i <= 1; i++ {
+		rl.DrawLineV(rl.NewVector2(cx+i*r*0.14, cy+r*0.24), rl.NewVector2(cx+i*r*0.14, cy+r*0.5), eye)
+	}
+}
@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Ability is something a monster does beyond its power.
+type Ability string
+
+const (
+	AbilityAmbush       Ability = "ambush"       // strikes a free blow as it is revealed; the hero cannot walk away
+	AbilityRegenerating Ability = "regenerating" // wins attack rounds the two sides tie
+	AbilityThief        Ability = "thief"        // takes ThiefGold gold whenever it wounds the hero
+	AbilityVenomous     Ability = "venomous"     // its wounds poison the hero
+	AbilityMagicOnly    Ability = "magicOnly"    // the hero's blows only land once a battle spell is cast
+)
+
+var Abilities = []Ability{AbilityAmbush, AbilityRegenerating, AbilityThief, AbilityVenomous, AbilityMagicOnly}
+
+// ThiefGold is what a thief takes with each wound it deals.
+const ThiefGold = 2
+
+// Venom is the poison a venomous monster's wound leaves.
+var Venom = Status{Kind: StatusPoison, Power: 1, Turns: 3}
+
+// Describe is what the ability does, for the card modal.
+func (a Ability) Describe() string {
+	switch a {
+	case AbilityAmbush:
+		return "strikes first; you cannot walk away"
+	case AbilityRegenerating:
+		return "wins tied rounds"
+	case AbilityThief:
+		return fmt.Sprintf("steals %d gold with each wound", ThiefGold)
+	case AbilityVenomous:
+		return fmt.Sprintf("its wounds poison you for %d turns", Venom.Turns)
+	case AbilityMagicOnly:
+		return "only hurt once a battle spell is cast"
+	}
+	return ""
+}
+
+// Has reports whether the card's monster has ability a.
+func (c Card) Has(a Ability) bool { return slices.Contains(c.Abilities, a) }
+
+// AbilityList is the card's abilities in short, like "ambush, thief", or ""
+// when it has none.
+func (c Card) AbilityList() string {
+	names := make([]string, len(c.Abilities))
+	for i, a := range c.Abilities {
+		names[i] = string(a)
+	}
+	return strings.Join(names, ", ")
+}
+
+// ambush opens the fight with the monster's free blow. The hero rolls only
+// to ward it off and deals no wound.
+func (g *Game) ambush() {
+	g.startBattle()
+	r := g.rollRound(MoveAmbush)
+	r.Taken = b2i(g.monsterWins(r))
+	g.wounded(&r)
+	g.endRound(r)
+}
+
+// monsterWins reports whether the monster's total beats the hero's in an
+// opposed round; a regenerating monster wins ties too.
+func (g *Game) monsterWins(r BattleRound) bool {
+	return r.MonTot > r.YourTot || (r.MonTot == r.YourTot && g.Card.Has(AbilityRegenerating))
+}
+
+// wounded applies what a wound in round r costs the hero beyond its health:
+// broken gear, a fallen follower, stolen gold and venom.
+func (g *Game) wounded(r *BattleRound) {
+	p := g.Current()
+	if r.Taken == 0 {
+		return
+	}
+	r.Broke = p.roundBreaks(*r)
+	if r.MonDie == LethalDie {
+		r.Lost = p.loseFollower()
+	}
+	if g.Card.Has(AbilityThief) {
+		r.Stole = min(p.Gold, ThiefGold)
+		p.Gold -= r.Stole
+	}
+	if g.Card.Has(AbilityVenomous) {
+		r.Poisoned = true
+		p.afflict(Venom)
+	}
+}
@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAmbush(t *testing.T) {
+	g := NewGame(1, 1)
+	monster := NewMonsterStrength(3)
+	monster.Abilities = []Ability{AbilityAmbush}
+	meet(g, monster)
+	if !g.InBattle() || len(g.Battle.Rounds) != 1 || g.Battle.Rounds[0].Move != MoveAmbush || g.Battle.Rounds[0].Dealt != 0 {
+		t.Fatalf("battle %+v, want it opened by the ambush", g.Battle)
+	}
+	if err := g.Apply(Action{Kind: ActSkipCard}); !errors.Is(err, ErrIllegalAction) {
+		t.Fatalf("walking away from an ambush: got %v", err)
+	}
+}
+
+func TestMonsterWins(t *testing.T) {
+	tests := []struct {
+		name       string
+		abilities  []Ability
+		yours, its int
+		want       bool
+	}{
+		{name: "higher", yours: 5, its: 6, want: true},
+		{name: "lower", yours: 6, its: 5},
+		{name: "tie", yours: 6, its: 6},
+		{name: "regenerating tie", abilities: []Ability{AbilityRegenerating}, yours: 6, its: 6, want: true},
+		{name: "regenerating lower", abilities: []Ability{AbilityRegenerating}, yours: 7, its: 6},
+	}
+	for _, tt := range tests {
+		g := NewGame(1, 1)
+		g.Card = NewMonsterStrength(4)
+		g.Card.Abilities = tt.abilities
+		if got := g.monsterWins(BattleRound{YourTot: tt.yours, MonTot: tt.its}); got != tt.want {
+			t.Errorf("%s: monster wins %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWoundAbilities(t *testing.T) {
+	tests := []struct {
+		name     string
+		ability  Ability
+		gold     int
+		stole    int
+		poisoned bool
+	}{
+		{name: "plain", gold: 5},
+		{name: "thief", ability: AbilityThief, gold: 5, stole: ThiefGold},
+		{name: "thief of the poor", ability: AbilityThief, gold: 1, stole: 1},
+		{name: "venomous", ability: AbilityVenomous, gold: 5, poisoned: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGame(1, 1)
+			p := g.Current()
+			p.Gold = tt.gold
+			g.Card = NewMonsterStrength(4)
+			if tt.ability != "" {
+				g.Card.Abilities = []Ability{tt.ability}
+			}
+			r := BattleRound{Move: MoveAttack, YourDie: 3, MonDie: 3, Taken: 1}
+			g.wounded(&r)
+			if r.Stole != tt.stole || p.Gold != tt.gold-tt.stole {
+				t.Errorf("stole %d leaving %d gold, want %d stolen", r.Stole, p.Gold, tt.stole)
+			}
+			if r.Poisoned != tt.poisoned || p.HasStatus(StatusPoison) != tt.poisoned {
+				t.Errorf("poisoned %v, want %v", p.HasStatus(StatusPoison), tt.poisoned)
+			}
+		})
+	}
+}
+
+func TestMagicOnly(t *testing.T) {
+	for _, spelled := range []bool{false, true} {
+		g := NewGame(1, 1)
+		p := g.Current()
+		p.Strength = 50
+		p.Spells = []Card{NewSpell(SpellBattle, 1)}
+		monster := NewMonsterStrength(1)
+		monster.Abilities = []Ability{AbilityMagicOnly}
+		fight(t, g, monster)
+		if spelled {
+			if err := g.Apply(Action{Kind: ActCastSpell}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := g.Apply(Action{Kind: ActAttack}); err != nil {
+			t.Fatal(err)
+		}
+		r := g.Battle.Rounds[len(g.Battle.Rounds)-1]
+		if r.Warded == spelled || (r.Dealt == 1) != spelled {
+			t.Errorf("battle spell cast %v: warded %v, dealt %d", spelled, r.Warded, r.Dealt)
+		}
+	}
+}
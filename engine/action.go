@@ -0,0 +1,288 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrIllegalAction is wrapped by every error Apply returns for an action the
+// rules do not allow right now.
+var ErrIllegalAction = errors.New("illegal action")
+
+type ActionKind string
+
+// Every decision a player can make. Apply is the only way they change the
+// game, which is what makes a seed plus an action stream a full recording.
+const (
+	ActRoll             ActionKind = "roll"
+	ActChooseDest       ActionKind = "chooseDest" // Tile = one of Game.Dests
+	ActCancelDest       ActionKind = "cancelDest" // only when the roll left no Dests; ends the turn
+	ActConfirmCard      ActionKind = "confirmCard"
+	ActSkipCard         ActionKind = "skipCard"
+	ActCloseCard        ActionKind = "closeCard"
+	ActOpenShop         ActionKind = "openShop"
+	ActCloseShop        ActionKind = "closeShop"
+	ActBuy              ActionKind = "buy" // Slot = 0..2
+	ActExchangeStrength ActionKind = "exchangeStrength"
+	ActExchangeMagic    ActionKind = "exchangeMagic"
+	ActEndTurn          ActionKind = "endTurn"
+	ActRespawn          ActionKind = "respawn"       // a fallen hero rises again (DeathRule Respawn)
+	ActCastSpell        ActionKind = "castSpell"     // Slot = index into Player.Spells; Tile = teleport target
+	ActExchangeSpell    ActionKind = "exchangeSpell" // trade trophies for a spell
+	ActAttack           ActionKind = "attack"        // a round of the fight on the open card
+	ActEvade            ActionKind = "evade"
+	ActFlee             ActionKind = "flee"
+	ActUseItem          ActionKind = "useItem" // Slot = index into Player.Cards; takes a round in a fight
+	ActEquip            ActionKind = "equip"   // Slot = index into Player.Cards
+	ActUnequip          ActionKind = "unequip" // Slot = index into EquipSlots
+	ActDrop             ActionKind = "drop"    // Slot = index into Player.Cards; leave a pack item behind
+	ActDismiss          ActionKind = "dismiss" // Slot = index into Player.Followers
+	ActReroll           ActionKind = "reroll"  // a guide rerolls the movement die
+	ActChoose           ActionKind = "choose"  // Slot = index into the open stranger's Offers
+
+	// Debug moves kept from the sandbox days, legal only with Game.Debug.
+	ActNewWorld   ActionKind = "newWorld"
+	ActStep       ActionKind = "step" // Dir = +1 next, -1 prev
+	ActFollowLink ActionKind = "followLink"
+)
+
+type Action struct {
+	Kind ActionKind
+	Tile TileID `json:",omitzero"`
+	Slot int    `json:",omitempty"`
+	Dir  int    `json:",omitempty"`
+}
+
+func (a Action) String() string {
+	switch a.Kind {
+	case ActChooseDest:
+		return fmt.Sprintf("%s %d/%d", a.Kind, a.Tile.Loop, a.Tile.Index)
+	case ActBuy:
+		return fmt.Sprintf("%s %d", a.Kind, a.Slot)
+	case ActCastSpell:
+		return fmt.Sprintf("%s %d %d/%d", a.Kind, a.Slot, a.Tile.Loop, a.Tile.Index)
+	case ActUseItem, ActEquip, ActUnequip, ActDrop, ActDismiss, ActChoose:
+		return fmt.Sprintf("%s %d", a.Kind, a.Slot)
+	case ActStep:
+		return fmt.Sprintf("%s %+d", a.Kind, a.Dir)
+	}
+	return string(a.Kind)
+}
+
+func illegal(a Action, why string) error {
+	return fmt.Errorf("%s: %s: %w", a, why, ErrIllegalAction)
+}
+
+// Ready reports whether the game is waiting for a decision rather than
+// walking the hero along a path.
+func (g *Game) Ready() bool { return g.Phase != PhaseAnimating }
+
+// Apply performs one player decision and appends it to g.Actions. Actions
+// that are not legal in the current state are rejected and leave the game
+// untouched.
+func (g *Game) Apply(a Action) error {
+	if err := g.apply(a); err != nil {
+		return err
+	}
+	g.Actions = append(g.Actions, a)
+	return nil
+}
+
+func (g *Game) apply(a Action) error {
+	if !g.Ready() {
+		return illegal(a, "the hero is still moving")
+	}
+	busy := g.CardActive || g.ShopActive
+	if g.Over() && a.Kind != ActNewWorld && a.Kind != ActCloseCard {
+		return illegal(a, "the run is over")
+	}
+	if g.Current().Dead {
+		switch a.Kind {
+		case ActCloseCard, ActRespawn, ActEndTurn, ActNewWorld:
+		default:
+			return illegal(a, "the hero has fallen")
+		}
+	}
+
+	switch a.Kind {
+	case ActRoll:
+		if busy || !g.CanRoll() {
+			return illegal(a, "cannot roll now")
+		}
+		g.roll()
+
+	case ActChooseDest:
+		if g.Phase != PhaseTargetSelect {
+			return illegal(a, "no roll to move with")
+		}
+		return g.chooseDest(a.Tile)
+
+	case ActCancelDest:
+		if g.Phase != PhaseTargetSelect {
+			return illegal(a, "no roll to cancel")
+		}
+		if len(g.Dests) > 0 {
+			return illegal(a, "pick one of the landing spots")
+		}
+		g.cancelTarget()
+
+	case ActConfirmCard, ActSkipCard:
+		if !g.CardActive || g.CardResolved {
+			return illegal(a, "no card to resolve")
+		}
+		if g.Battle != nil {
+			return illegal(a, "the fight is on; attack, evade or flee")
+		}
+		if a.Kind == ActConfirmCard && g.Card.Type == StrangerType {
+			return illegal(a, "take one of the offers or walk away")
+		}
+		if a.Kind == ActSkipCard && g.Card.Unavoidable() {
+			return illegal(a, "there is no walking away from this")
+		}
+		if a.Kind == ActConfirmCard {
+			g.resolveCard()
+		} else {
+			g.skipCard()
+		}
+
+	case ActChoose:
+		if !g.CardActive || g.CardResolved || g.Card.Type != StrangerType {
+			return illegal(a, "nobody is offering anything")
+		}
+		return g.takeOffer(a.Slot)
+
+	case ActCloseCard:
+		if !g.CardActive || !g.CardResolved {
+			return illegal(a, "no resolved card to close")
+		}
+		g.closeCard()
+
+	case ActOpenShop:
+		if busy || g.Phase != PhaseIdle {
+			return illegal(a, "cannot shop now")
+		}
+		if !g.openShop() {
+			return illegal(a, "no shop next to the hero")
+		}
+
+	case ActCloseShop:
+		if !g.ShopActive {
+			return illegal(a, "not in a shop")
+		}
+		g.closeShop()
+
+	case ActBuy:
+		if !g.ShopActive {
+			return illegal(a, "not in a shop")
+		}
+		if a.Slot < 0 || a.Slot >= len(g.ShopCards) {
+			return illegal(a, "no such shop slot")
+		}
+		return g.buyShopItem(a.Slot)
+
+	case ActExchangeStrength:
+		if !g.exchangeMonsterStrength() {
+			return illegal(a, "not enough monster strength trophies")
+		}
+
+	case ActExchangeMagic:
+		if !g.exchangeMagicMonster() {
+			return illegal(a, "not enough magic monster trophies")
+		}
+
+	case ActCastSpell:
+		return g.castSpell(a.Slot, a.Tile)
+
+	case ActExchangeSpell:
+		return g.exchangeForSpell()
+
+	case ActAttack, ActEvade, ActFlee:
+		if !g.InBattle() {
+			return illegal(a, "no fight in progress")
+		}
+		switch a.Kind {
+		case ActAttack:
+			g.strike(MoveAttack)
+		case ActEvade:
+			g.strike(MoveEvade)
+		default:
+			g.flee()
+		}
+
+	case ActUseItem:
+		if g.CardActive && !g.InBattle() {
+			return illegal(a, "deal with the card first")
+		}
+		return g.useItem(a.Slot)
+
+	case ActEquip, ActUnequip:
+		if g.CardActive {
+			return illegal(a, "no changing gear with a card on the table")
+		}
+		if a.Kind == ActEquip {
+			return g.equip(a.Slot)
+		}
+		return g.unequip(a.Slot)
+
+	case ActDrop:
+		if g.InBattle() {
+			return illegal(a, "not in the middle of a fight")
+		}
+		return g.drop(a.Slot)
+
+	case ActDismiss:
+		if g.InBattle() {
+			return illegal(a, "not in the middle of a fight")
+		}
+		return g.dismiss(a.Slot)
+
+	case ActReroll:
+		if !g.CanReroll() {
+			return illegal(a, "no guide reroll left")
+		}
+		g.reroll()
+
+	case ActEndTurn:
+		if busy || g.Phase != PhaseIdle || !g.Moved {
+			return illegal(a, "roll and move before ending the turn")
+		}
+		if g.Current().Dead && g.DeathRule == Respawn {
+			return illegal(a, "a fallen hero must respawn")
+		}
+		g.endTurn()
+
+	case ActRespawn:
+		if busy || !g.Current().Dead || g.DeathRule != Respawn {
+			return illegal(a, "nobody to respawn")
+		}
+		g.respawn()
+
+	case ActNewWorld, ActStep, ActFollowLink:
+		if !g.Debug {
+			return illegal(a, "debug moves are off")
+		}
+		if a.Kind == ActNewWorld {
+			g.newWorld()
+			break
+		}
+		if busy || g.Phase != PhaseIdle {
+			return illegal(a, "cannot move now")
+		}
+		to := stepAlongDir(g.World, g.Current().At, a.Dir)
+		if a.Kind == ActFollowLink {
+			cur := g.World.Loops[g.Current().At.Loop].Tiles[g.Current().At.Index]
+			if len(cur.Links) == 0 {
+				return illegal(a, "no link here")
+			}
+			to = cur.Links[0]
+		}
+		if g.World.Loops[to.Loop].Type.Crown && !g.crownOpen() {
+			return illegal(a, "The Crown is sealed")
+		}
+		g.Current().At = to
+
+	default:
+		return illegal(a, "unknown action")
+	}
+	return nil
+}
@@ -0,0 +1,576 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+)
+
+// Personality decides how a computer-controlled hero plays. The empty
+// personality is a human.
+type Personality string
+
+const (
+	Cautious Personality = "cautious" // only fights it expects to win, keeps its health up
+	Greedy   Personality = "greedy"   // goes where the gold is, hoards some for big buys
+	Reckless Personality = "reckless" // fights everything, heads for the nastiest decks
+)
+
+var Personalities = []Personality{Cautious, Greedy, Reckless}
+
+// ParsePersonality accepts the names used on the command line.
+func ParsePersonality(s string) (Personality, error) {
+	for _, p := range Personalities {
+		if string(p) == s {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("unknown bot personality %q (want cautious, greedy or reckless)", s)
+}
+
+// botTraits are the knobs that set the personalities apart.
+type botTraits struct {
+	minWin  float64 // lowest chance of winning it will fight at
+	hurt    float64 // how much it minds losing a point of health
+	reward  float64 // how much it values a won fight
+	reserve int     // gold it never spends in shops
+	hoard   bool    // waits for a tidy trophy exchange instead of taking any
+	flee    float64 // tries to run once its chance of winning the fight drops below this
+}
+
+func (p Personality) traits() botTraits {
+	switch p {
+	case Cautious:
+		return botTraits{minWin: 0.6, hurt: 3, reward: 1, flee: 0.5}
+	case Greedy:
+		return botTraits{minWin: 0.45, hurt: 1.5, reward: 2.5, reserve: 6, hoard: true, flee: 0.35}
+	default: // Reckless
+		return botTraits{minWin: 0, hurt: 0.5, reward: 1.5, flee: 0.1}
+	}
+}
+
+// WinChance is the chance p beats c in a fight to the finish: c at full hit
+// points, p at its current health and never fleeing.
+func WinChance(p *Player, c Card) float64 { return winChance(p, c, 0) }
+
+// winChance is WinChance with bonus added to the hero's rolls.
+func winChance(p *Player, c Card, bonus int) float64 {
+	if c.FightStat() == "" {
+		return 1
+	}
+	return battleOdds(p, c, bonus, c.HitPoints(), p.Health)
+}
+
+// fightOdds is the hero's chance of winning the fight on the open card from
+// where it stands, with bonus added to its rolls.
+func fightOdds(g *Game, bonus int) float64 {
+	hp := g.Card.HitPoints()
+	if g.Battle != nil {
+		hp = g.Battle.MonsterHP
+	}
+	return battleOdds(g.Current(), g.Card, bonus, hp, g.Current().Health)
+}
+
+// battleOdds is the chance of dealing hp wounds before taking health of
+// them, attacking every round. Rounds that lock blades change nothing, so
+// only the rounds in which someone is hit count.
+func battleOdds(p *Player, c Card, bonus, hp, health int) float64 {
+	if hp <= 0 {
+		return 1
+	}
+	if health <= 0 || (c.Has(AbilityMagicOnly) && bonus == 0) {
+		return 0
+	}
+	mine, theirs := p.Stat(c.FightStat())+p.FollowerBonus()+bonus, c.Power()
+	hits, wounds := 0, 0
+	for a := 1; a <= 6; a++ {
+		for b := 1; b <= 6; b++ {
+			switch {
+			case mine+a > theirs+b:
+				hits++
+			case theirs+b > mine+a, theirs+b == mine+a && c.Has(AbilityRegenerating):
+				wounds++
+			}
+		}
+	}
+	if hits == 0 {
+		return 0
+	}
+	q := float64(hits) / float64(hits+wounds)
+	// the last hit lands after k of the monster's, for every k < health
+	total, ways := 0.0, 1.0
+	for k := range health {
+		if k > 0 {
+			ways = ways * float64(hp-1+k) / float64(k)
+		}
+		total += ways * math.Pow(q, float64(hp)) * math.Pow(1-q, float64(k))
+	}
+	return total
+}
+
+// NextAction picks the bot's next decision for the current hero. It never
+// draws from the game's RNG, so a bot game replays from its actions alone.
+func (p Personality) NextAction(g *Game) Action {
+	t := p.traits()
+	hero := g.Current()
+
+	switch {
+	case g.ShopActive:
+		if slot := p.pickPurchase(g); slot >= 0 {
+			return Action{Kind: ActBuy, Slot: slot}
+		}
+		return Action{Kind: ActCloseShop}
+
+	case g.CardActive && g.CardResolved:
+		return Action{Kind: ActCloseCard}
+
+	case hero.Dead && !g.CardActive:
+		if g.DeathRule == Respawn {
+			return Action{Kind: ActRespawn}
+		}
+		return Action{Kind: ActEndTurn}
+
+	case g.InBattle():
+		return p.battleMove(g)
+
+	case g.CardActive:
+		if slot := p.battleSpell(g); slot >= 0 {
+			return Action{Kind: ActCastSpell, Slot: slot}
+		}
+		if g.Card.Type == StrangerType {
+			if i := p.bestOffer(hero, g.Card); i >= 0 {
+				return Action{Kind: ActChoose, Slot: i}
+			}
+			return Action{Kind: ActSkipCard}
+		}
+		if p.wouldFight(hero, g.Card, g.FightBonus) {
+			return Action{Kind: ActConfirmCard}
+		}
+		return Action{Kind: ActSkipCard}
+
+	case g.Phase == PhaseTargetSelect:
+		if len(g.Dests) == 0 && g.CanReroll() {
+			return Action{Kind: ActReroll}
+		}
+		if len(g.Dests) == 0 {
+			return Action{Kind: ActCancelDest}
+		}
+		// a hero carrying a Talisman is drawn toward The Crown
+		var toCrown map[TileID]int
+		if hero.HasTalisman() {
+			toCrown = crownDistances(g.World)
+		}
+		score := func(d TileID) float64 {
+			s := p.scoreLoop(g, d)
+			if dist, ok := toCrown[d]; ok {
+				s -= float64(dist) * crownPull
+			}
+			return s
+		}
+		best, bestScore := g.Dests[0], score(g.Dests[0])
+		for _, d := range g.Dests[1:] {
+			if s := score(d); s > bestScore {
+				best, bestScore = d, s
+			}
+		}
+		if bestScore < 0 && g.CanReroll() {
+			return Action{Kind: ActReroll} // nowhere good to go; the guide may find better
+		}
+		return Action{Kind: ActChooseDest, Tile: best}
+	}
+
+	// idle: patch up, tidy up trophies, shop if there is something worth buying, then move
+	if slot := hero.PotionSlot(); slot >= 0 && hero.Health <= 2 {
+		return Action{Kind: ActUseItem, Slot: slot}
+	}
+	for i, c := range hero.Cards {
+		if c.Type == PotionType && c.Status.Kind == StatusBlessing && !hero.HasStatus(StatusBlessing) && g.CanRoll() {
+			return Action{Kind: ActUseItem, Slot: i} // a sure six before the roll
+		}
+	}
+	for i, c := range hero.Cards {
+		if c.Type == ShopItemType && gearGain(hero, c) > 0 {
+			return Action{Kind: ActEquip, Slot: i}
+		}
+	}
+	if hero.PackRoom() <= 0 {
+		for i, c := range hero.Cards {
+			if c.Type == ShopItemType {
+				return Action{Kind: ActDrop, Slot: i} // never better than what it wears
+			}
+		}
+	}
+	if p.wantsExchange(hero.Cards, MonsterType, t) {
+		return Action{Kind: ActExchangeStrength}
+	}
+	if p.wantsExchange(hero.Cards, MagicMonsterType, t) {
+		return Action{Kind: ActExchangeMagic}
+	}
+	if shop := g.AdjacentShop(); shop != nil && p.bestBuy(hero, shop.Cards[:], shop.Prices[:]) >= 0 {
+		return Action{Kind: ActOpenShop}
+	}
+	if g.CanRoll() {
+		return Action{Kind: ActRoll}
+	}
+	return Action{Kind: ActEndTurn}
+}
+
+func (p Personality) wouldFight(hero *Player, c Card, bonus int) bool {
+	switch c.Type {
+	case MonsterType, MagicMonsterType, CrownType:
+	case PlaceType:
+		return p.placeValue(hero, c) >= 0
+	case ScriptedType:
+		return p.scriptValue(hero, c.Script) >= 0
+	default:
+		return true // blessings, talismans and spells are free; events and traps cannot be refused
+	}
+	win := winChance(hero, c, bonus)
+	if p == Cautious && hero.Health <= 2 {
+		return win >= 0.9
+	}
+	return win >= p.traits().minWin
+}
+
+// battleMove picks the bot's move in the fight under way: a potion when it
+// is about to go down, a battle spell when the fight is slipping, flight when
+// it is lost, and otherwise another attack. Bots never evade.
+func (p Personality) battleMove(g *Game) Action {
+	hero := g.Current()
+	if slot := hero.PotionSlot(); slot >= 0 && hero.Health == 1 {
+		return Action{Kind: ActUseItem, Slot: slot}
+	}
+	if slot := p.battleSpell(g); slot >= 0 {
+		return Action{Kind: ActCastSpell, Slot: slot}
+	}
+	if fightOdds(g, g.FightBonus) < p.traits().flee {
+		return Action{Kind: ActFlee}
+	}
+	return Action{Kind: ActAttack}
+}
+
+// cardValue is what the bot expects to get out of landing on c.
+func (p Personality) cardValue(hero *Player, c Card) float64 {
+	t := p.traits()
+	switch c.Type {
+	case MonsterType, MagicMonsterType:
+		if !p.wouldFight(hero, c, 0) {
+			return 0
+		}
+		win := WinChance(hero, c)
+		// a lost fight hurts more the closer the hero is to dying
+		hurt := t.hurt * (1 + 3/float64(max(hero.Health, 1)))
+		v := win*t.reward - (1-win)*hurt
+		if p == Reckless {
+			v += float64(c.Strength+c.Magic) / 10 // likes a big trophy
+		}
+		return v
+	case CrownType:
+		if !p.wouldFight(hero, c, 0) {
+			return 0
+		}
+		return WinChance(hero, c)*crownValue - (1-WinChance(hero, c))*t.hurt
+	case TalismanType:
+		if hero.HasTalisman() {
+			return 0
+		}
+		return crownValue / 2
+	case SpellType:
+		if len(hero.Spells) >= hero.SpellSlots() {
+			return 0
+		}
+		return float64(max(c.Spell.Power, 2))
+	case PotionType:
+		if hero.Fits(c) != "" {
+			return 0
+		}
+		return float64(c.Heal)/2 + float64(b2i(c.Status.Kind == StatusBlessing))
+	case FollowerType:
+		if hero.Fits(c) != "" {
+			return 0
+		}
+		return followerValue(c.Follower)
+	case BuffType:
+		return float64(c.Strength + c.Magic)
+	case EventType:
+		return p.effectValue(hero, c.Effect)
+	case StrangerType:
+		if i := p.bestOffer(hero, c); i >= 0 {
+			return p.effectValue(hero, c.Offers[i])
+		}
+		return 0
+	case PlaceType:
+		return max(p.placeValue(hero, c), 0)
+	case ScriptedType:
+		return max(p.scriptValue(hero, c.Script), 0)
+	case TrapType:
+		return -(1 - trapOdds(hero, c)) * float64(c.Damage) * t.hurt
+	case TreasureType:
+		v := float64(c.Gold) * goldValue
+		if c.Reward != nil {
+			v += p.rewardValue(hero, *c.Reward)
+		}
+		return v
+	}
+	return 0
+}
+
+// goldValue is what a gold piece is worth next to a point of Strength.
+const goldValue = 0.25
+
+// effectValue is what the bot makes of e happening to the hero.
+func (p Personality) effectValue(hero *Player, e Effect) float64 {
+	t := p.traits()
+	health := e.Health
+	if health > 0 {
+		health = min(health, max(StartHealth-hero.Health, 0))
+	}
+	v := float64(e.Gold)*goldValue + float64(health)*t.hurt + float64(e.Strength+e.Magic)
+	switch e.Status.Kind {
+	case StatusPoison:
+		v -= float64(e.Status.Power*e.Status.Turns) * t.hurt
+	case StatusCurse:
+		v -= float64(e.Status.Power) / 2
+	case StatusBlessing:
+		v += float64(e.Status.Turns) / 2
+	}
+	return v
+}
+
+// bestOffer is the stranger's offer the bot would take, or -1 when none is
+// worth having or can be paid for.
+func (p Personality) bestOffer(hero *Player, c Card) int {
+	best, bestValue := -1, 0.0
+	for i, e := range c.Offers {
+		if hero.CannotTake(e) != "" {
+			continue
+		}
+		if v := p.effectValue(hero, e); v > bestValue {
+			best, bestValue = i, v
+		}
+	}
+	return best
+}
+
+// placeValue is what the bot expects from a roll on the place's table.
+func (p Personality) placeValue(hero *Player, c Card) float64 {
+	total := 0.0
+	for die := 1; die <= 6; die++ {
+		total += p.effectValue(hero, c.RowFor(die).Effect)
+	}
+	return total / 6
+}
+
+// scriptValue is what the bot expects from a scripted card: each statement
+// at its average amount, times the chance its condition holds.
+func (p Personality) scriptValue(hero *Player, s Script) float64 {
+	stmts, err := s.compile()
+	if err != nil {
+		return 0
+	}
+	total := 0.0
+	for _, st := range stmts {
+		faces := 6
+		if st.If != nil {
+			faces = 0
+			for die := 1; die <= 6; die++ {
+				faces += b2i(st.If.holds(hero, die))
+			}
+		}
+		total += float64(faces) / 6 * p.effectValue(hero, st.effect(int(math.Round(st.Amount.mean()))))
+	}
+	return total
+}
+
+// trapOdds is the chance the hero gets past trap c.
+func trapOdds(hero *Player, c Card) float64 {
+	need := c.Difficulty() - hero.Stat(c.CheckStat())
+	return float64(min(max(7-need, 0), 6)) / 6
+}
+
+// battleSpell picks a battle spell to cast on the open fight, or -1. Spells
+// go on fights the bot would otherwise walk away from or might well lose.
+func (p Personality) battleSpell(g *Game) int {
+	hero := g.Current()
+	if g.CardResolved || g.Card.FightStat() == "" {
+		return -1
+	}
+	engaged := g.Battle != nil
+	if (engaged || p.wouldFight(hero, g.Card, g.FightBonus)) && fightOdds(g, g.FightBonus) >= 0.75 {
+		return -1
+	}
+	all, first := g.FightBonus, -1
+	for i, c := range hero.Spells {
+		if c.Spell.Kind == SpellBattle {
+			all += c.Spell.Power
+			if first < 0 {
+				first = i
+			}
+		}
+	}
+	if first < 0 || (!engaged && !p.wouldFight(hero, g.Card, all)) {
+		return -1
+	}
+	return first
+}
+
+// crownValue is what winning the run is worth next to a single fight.
+const crownValue = 20
+
+// scoreLoop rates landing on id by the average value of its deck.
+func (p Personality) scoreLoop(g *Game, id TileID) float64 {
+	if g.World.BossAt(id) {
+		return p.bossValue(g.Current(), g.World.Loops[id.Loop].Type.Boss)
+	}
+	if c := g.World.WaitingAt(id); c != nil {
+		return p.cardValue(g.Current(), *c)
+	}
+	// bots count cards: only what is left in the draw pile can come up
+	cards := g.World.Upcoming(g.World.Loops[id.Loop].Type.Deck)
+	if len(cards) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, c := range cards {
+		total += p.cardValue(g.Current(), c)
+	}
+	return total / float64(len(cards))
+}
+
+// bossValue is what the bot expects from landing on a boss: the fight, and
+// the reward if it would take it on.
+func (p Personality) bossValue(hero *Player, boss Card) float64 {
+	v := p.cardValue(hero, boss)
+	if !p.wouldFight(hero, boss, 0) {
+		return v
+	}
+	return v + WinChance(hero, boss)*p.rewardValue(hero, *boss.Reward)
+}
+
+// rewardValue is what the bot makes of being handed c.
+func (p Personality) rewardValue(hero *Player, c Card) float64 {
+	if c.Type == ShopItemType {
+		return float64(max(gearGain(hero, c), 0))
+	}
+	return p.cardValue(hero, c)
+}
+
+// crownPull is how much a step closer to The Crown is worth.
+const crownPull = 0.5
+
+// crownDistances maps every tile to the number of steps to the nearest tile
+// of The Crown, walking either way round the loops and across bridges.
+func crownDistances(w *World) map[TileID]int {
+	dist := map[TileID]int{}
+	var queue []TileID
+	if c := w.CrownLoop(); c >= 0 {
+		for i := range w.Loops[c].Tiles {
+			id := TileID{c, i}
+			dist[id] = 0
+			queue = append(queue, id)
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		t := w.Loops[id.Loop].Tiles[id.Index]
+		for _, nb := range append([]TileID{t.Next, t.Prev}, t.Links...) {
+			if _, seen := dist[nb]; seen || isShopTile(w, nb) {
+				continue
+			}
+			dist[nb] = dist[id] + 1
+			queue = append(queue, nb)
+		}
+	}
+	return dist
+}
+
+// wantsExchange reports whether to trade in trophies of type kind now.
+func (p Personality) wantsExchange(cards []Card, kind CardType, t botTraits) bool {
+	total, spent := 0, 0
+	for _, c := range cards {
+		if c.Type != kind {
+			continue
+		}
+		v := c.Strength + c.Magic
+		total += v
+		if spent < 7 {
+			spent += v // the exchange eats trophies in order until it has 7
+		}
+	}
+	if total < 7 {
+		return false
+	}
+	// a hoarder waits rather than throw away much more than 7
+	return !t.hoard || spent-7 <= 2 || total >= 14
+}
+
+// bestBuy returns the affordable card worth the most stats per gold, or -1.
+// A Talisman beats everything until the hero has one.
+func (p Personality) bestBuy(hero *Player, cards []Card, prices []int) int {
+	budget := hero.Gold - p.traits().reserve
+	best, bestValue := -1, 0.0
+	for i, c := range cards {
+		if prices[i] > budget || prices[i] <= 0 || hero.Fits(c) != "" {
+			continue
+		}
+		if c.Type == TalismanType {
+			if !hero.HasTalisman() {
+				return i
+			}
+			continue
+		}
+		v := float64(c.Strength+c.Magic) / float64(prices[i])
+		if c.Type == ShopItemType {
+			v = float64(gearGain(hero, c)) / float64(prices[i])
+		}
+		if c.Type == PotionType && hero.PotionSlot() < 0 {
+			v = float64(c.Heal) / float64(prices[i]) // one in the pack is plenty
+		}
+		if c.Type == FollowerType {
+			v = followerValue(c.Follower) / float64(prices[i])
+		}
+		if v > bestValue {
+			best, bestValue = i, v
+		}
+	}
+	return best
+}
+
+// followerValue is what a follower is worth next to a point of Strength.
+func followerValue(f Follower) float64 {
+	switch f.Kind {
+	case FollowerFighter:
+		return float64(f.Power)
+	case FollowerGuide:
+		return float64(f.Power) / 2
+	}
+	return 0.2 * float64(f.Power) // bots carry little
+}
+
+// gearGain is how many stat points wearing c would add over what the hero
+// wears in its slot now.
+func gearGain(hero *Player, c Card) int {
+	old, _ := hero.Worn(c.Slot)
+	return c.Strength + c.Magic - old.Strength - old.Magic
+}
+
+func (p Personality) pickPurchase(g *Game) int {
+	return p.bestBuy(g.Current(), g.ShopCards[:], g.ShopPrices[:])
+}
+
+// RunBots lets computer heroes take their turns until it is a human's turn
+// or limit actions have been applied. Headless: walks finish instantly.
+func (g *Game) RunBots(limit int) error {
+	for range limit {
+		bot := g.Current().Bot
+		if bot == "" || (g.Over() && !g.CardActive) {
+			return nil
+		}
+		g.FinishMove()
+		if err := g.Apply(bot.NextAction(g)); err != nil {
+			return fmt.Errorf("%s bot: %w", bot, err)
+		}
+	}
+	g.FinishMove()
+	return nil
+}
@@ -0,0 +1,119 @@
+package engine
+
+import "testing"
+
+func TestParsePersonality(t *testing.T) {
+	for _, p := range Personalities {
+		if got, err := ParsePersonality(string(p)); got != p || err != nil {
+			t.Errorf("%q: got %q, %v", p, got, err)
+		}
+	}
+	if _, err := ParsePersonality("sneaky"); err == nil {
+		t.Error("sneaky is not a personality")
+	}
+}
+
+func TestWinChance(t *testing.T) {
+	hero := NewPlayer(TileID{}, 3, 3, StartHealth)
+	weak, even, hopeless := WinChance(hero, NewMonsterStrength(1)), WinChance(hero, NewMonsterStrength(3)), WinChance(hero, NewMonsterStrength(20))
+	if !(weak > 0.9 && weak > even && even > hopeless && hopeless == 0) {
+		t.Fatalf("win chances %.2f, %.2f, %.2f; want them falling to 0 as the monster grows", weak, even, hopeless)
+	}
+	if WinChance(hero, NewBuffStrength(1)) != 1 {
+		t.Error("a buff can be lost to")
+	}
+}
+
+func TestWouldFight(t *testing.T) {
+	tests := []struct {
+		name   string
+		p      Personality
+		health int
+		card   Card
+		want   bool
+	}{
+		{name: "cautious, sure win", p: Cautious, health: StartHealth, card: NewMonsterStrength(1), want: true},
+		{name: "cautious, long odds", p: Cautious, health: StartHealth, card: NewMonsterStrength(7)},
+		{name: "cautious and hurt", p: Cautious, health: 2, card: NewMonsterStrength(3)},
+		{name: "reckless, long odds", p: Reckless, health: StartHealth, card: NewMonsterStrength(7), want: true},
+		{name: "greedy, even fight", p: Greedy, health: StartHealth, card: NewMonsterStrength(3), want: true},
+		{name: "anyone takes a buff", p: Cautious, health: 1, card: NewBuffMagic(1), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hero := NewPlayer(TileID{}, 3, 3, tt.health)
+			if got := tt.p.wouldFight(hero, tt.card, 0); got != tt.want {
+				t.Errorf("fights: %v, want %v (win chance %.2f)", got, tt.want, WinChance(hero, tt.card))
+			}
+		})
+	}
+}
+
+func TestNextAction(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(g *Game)
+		want  ActionKind
+	}{
+		{name: "roll", setup: func(g *Game) { g.Current().Gold = 0 }, want: ActRoll},
+		{name: "shop first", want: ActOpenShop},
+		{name: "close a resolved card", setup: func(g *Game) {
+			g.CardActive, g.CardResolved = true, true
+		}, want: ActCloseCard},
+		{name: "respawn", setup: func(g *Game) { g.Current().Dead = true }, want: ActRespawn},
+		{name: "pass when out for good", setup: func(g *Game) {
+			g.DeathRule = Permadeath
+			g.Moved = true
+			g.Current().Dead = true
+		}, want: ActEndTurn},
+		{name: "nowhere to land", setup: func(g *Game) {
+			g.roll()
+			g.Dests = nil
+		}, want: ActCancelDest},
+		{name: "reroll with a guide", setup: func(g *Game) {
+			g.Current().Followers = []Card{NewFollower(FollowerGuide, 1)}
+			g.roll()
+			g.Dests = nil
+		}, want: ActReroll},
+		{name: "drink when low", setup: func(g *Game) {
+			g.Current().Health = 1
+			g.Current().Cards = []Card{NewPotion(2)}
+		}, want: ActUseItem},
+		{name: "put on better gear", setup: func(g *Game) {
+			g.Current().Cards = []Card{{Type: ShopItemType, Title: "Sword", Strength: 2, Slot: Weapon}}
+		}, want: ActEquip},
+		{name: "walk away from a hopeless fight", setup: func(g *Game) {
+			g.Card = NewMonsterStrength(20)
+			g.CardActive = true
+		}, want: ActSkipCard},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGame(1, 2)
+			if tt.setup != nil {
+				tt.setup(g)
+			}
+			a := Cautious.NextAction(g)
+			if a.Kind != tt.want {
+				t.Fatalf("got %s, want %s", a, tt.want)
+			}
+			if err := g.Apply(a); err != nil {
+				t.Fatalf("%s is not legal: %v", a, err)
+			}
+		})
+	}
+}
+
+func TestBotsTakeTurns(t *testing.T) {
+	for seed := int64(1); seed <= 5; seed++ {
+		g := botGame(seed, Cautious, Greedy, Reckless)
+		if err := g.RunBots(3000); err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		for _, p := range g.Players {
+			if p.Stats.Turns == 0 && !g.Over() {
+				t.Errorf("seed %d: %s never rolled", seed, p.Name)
+			}
+		}
+	}
+}
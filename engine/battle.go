@@ -0,0 +1,281 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+)
+
+// BattleMove is what the hero does with one round of a fight.
+type BattleMove string
+
+const (
+	MoveAttack BattleMove = "attack" // opposed roll; whoever rolls lower takes a wound
+	MoveEvade  BattleMove = "evade"  // opposed roll; only a blow that beats you by EvadeMargin lands, and you deal none
+	MoveItem   BattleMove = "item"   // drink a potion; no blows are struck
+	MoveSpell  BattleMove = "spell"  // cast a battle spell; no blows are struck
+	MoveFlee   BattleMove = "flee"   // a d6 of FleeRoll or more escapes, anything less earns a free blow
+	MoveAmbush BattleMove = "ambush" // the monster's free blow as it is revealed; the hero only defends
+)
+
+const (
+	EvadeMargin = 3 // how far the monster must out-roll an evading hero to wound it
+	FleeRoll    = 4 // lowest d6 that gets the hero away
+)
+
+// BattleRound is one line of a fight's transcript.
+type BattleRound struct {
+	Move    BattleMove
+	Item    string `json:",omitempty"` // title of the potion drunk or spell cast
+	YourDie int    `json:",omitempty"`
+	MonDie  int    `json:",omitempty"`
+	YourTot int    `json:",omitempty"` // stat + spells + followers + YourDie
+	MonTot  int    `json:",omitempty"` // power + MonDie
+	Dealt   int    `json:",omitempty"` // wounds dealt to the monster
+	Taken   int    `json:",omitempty"` // wounds the hero took
+	Healed  int    `json:",omitempty"`
+	Broke   string `json:",omitempty"` // title of the hero's item the round broke
+	Lost    string `json:",omitempty"` // title of the follower the round killed
+
+	Warded   bool `json:",omitempty"` // the hero out-rolled a magicOnly monster but could not hurt it
+	Stole    int  `json:",omitempty"` // gold a thief took
+	Poisoned bool `json:",omitempty"` // a venomous monster's wound
+}
+
+func (r BattleRound) String() string {
+	s := r.describe()
+	if r.Broke != "" {
+		s += fmt.Sprintf(" - your %s breaks!", r.Broke)
+	}
+	if r.Lost != "" {
+		s += fmt.Sprintf(" - %s falls!", r.Lost)
+	}
+	if r.Stole > 0 {
+		s += fmt.Sprintf(" - it steals %d gold", r.Stole)
+	}
+	if r.Poisoned {
+		s += " - you are poisoned"
+	}
+	return s
+}
+
+func (r BattleRound) describe() string {
+	rolls := fmt.Sprintf("you %d (d6 %d) vs %d (d6 %d)", r.YourTot, r.YourDie, r.MonTot, r.MonDie)
+	switch r.Move {
+	case MoveAttack:
+		switch {
+		case r.Dealt > 0:
+			return "Attack: " + rolls + ", you hit"
+		case r.Warded:
+			return "Attack: " + rolls + ", your blow passes through it"
+		case r.Taken > 0:
+			return "Attack: " + rolls + ", you are hit"
+		}
+		return "Attack: " + rolls + ", blades lock"
+	case MoveAmbush:
+		if r.Taken > 0 {
+			return "Ambush! " + rolls + ", it strikes you"
+		}
+		return "Ambush! " + rolls + ", you ward it off"
+	case MoveEvade:
+		if r.Taken > 0 {
+			return "Evade: " + rolls + ", the blow lands"
+		}
+		return "Evade: " + rolls + ", you slip aside"
+	case MoveItem:
+		if r.Healed == 0 {
+			return fmt.Sprintf("You drink %s", r.Item)
+		}
+		return fmt.Sprintf("You drink %s (+%d health)", r.Item, r.Healed)
+	case MoveSpell:
+		return fmt.Sprintf("You cast %s", r.Item)
+	case MoveFlee:
+		if r.Taken > 0 {
+			return fmt.Sprintf("Flee: d6 %d, you are caught and hit", r.YourDie)
+		}
+		return fmt.Sprintf("Flee: d6 %d, you get away", r.YourDie)
+	}
+	return string(r.Move)
+}
+
+// startBattle engages the monster on the open card. The fight lasts until
+// the monster's hit points or the hero's health run out, or the hero flees.
+func (g *Game) startBattle() {
+	p := g.Current()
+	hp := g.Card.HitPoints()
+	g.Battle = &InteractResult{
+		Card:        g.Card,
+		Stat:        g.Card.FightStat(),
+		MonsterHP:   hp,
+		MonsterMax:  hp,
+		HPBefore:    p.Health,
+		HPAfter:     p.Health,
+		StrBefore:   p.Strength,
+		StrAfter:    p.Strength,
+		MagicBefore: p.Magic,
+		MagicAfter:  p.Magic,
+		GoldBefore:  p.Gold,
+		GoldAfter:   p.Gold,
+	}
+	if len(g.Card.Abilities) > 0 {
+		g.Logf("Battle! Foe: %s %d, %d HP (%s)", g.Battle.Stat, g.Card.Power(), hp, g.Card.AbilityList())
+		return
+	}
+	g.Logf("Battle! Foe: %s %d, %d HP", g.Battle.Stat, g.Card.Power(), hp)
+}
+
+// InBattle reports whether a fight is under way and waiting for a move.
+func (g *Game) InBattle() bool { return g.Battle != nil && g.Battle.Outcome == OutcomeNone }
+
+// rollRound throws both sides' dice for an opposed round.
+func (g *Game) rollRound(move BattleMove) BattleRound {
+	r := BattleRound{Move: move, YourDie: g.Rng.Intn(6) + 1, MonDie: g.Rng.Intn(6) + 1}
+	r.YourTot = g.Current().Stat(g.Battle.Stat) + g.FightBonus + g.Current().FollowerBonus() + r.YourDie
+	r.MonTot = g.Card.Power() + r.MonDie
+	return r
+}
+
+// strike plays an attack or evade round.
+func (g *Game) strike(move BattleMove) {
+	r := g.rollRound(move)
+	switch {
+	case move == MoveEvade:
+		r.Taken = b2i(r.MonTot >= r.YourTot+EvadeMargin)
+	case g.monsterWins(r):
+		r.Taken = 1
+	case r.YourTot > r.MonTot && g.Card.Has(AbilityMagicOnly) && g.FightBonus == 0:
+		r.Warded = true
+	case r.YourTot > r.MonTot:
+		r.Dealt = 1
+	}
+	g.wounded(&r)
+	g.endRound(r)
+}
+
+// flee tries to get away from the fight.
+func (g *Game) flee() {
+	r := BattleRound{Move: MoveFlee, YourDie: g.Rng.Intn(6) + 1}
+	r.Taken = b2i(r.YourDie < FleeRoll)
+	g.wounded(&r)
+	g.endRound(r)
+}
+
+// useItem drinks the potion in the hero's inventory slot. In a fight it
+// takes up the round.
+func (g *Game) useItem(slot int) error {
+	p := g.Current()
+	if slot < 0 || slot >= len(p.Cards) || p.Cards[slot].Type != PotionType {
+		return fmt.Errorf("no potion in slot %d: %w", slot, ErrIllegalAction)
+	}
+	c := p.Cards[slot]
+	if c.Status.Kind == "" && p.Health >= StartHealth {
+		return fmt.Errorf("already at full health: %w", ErrIllegalAction)
+	}
+	healed := min(c.Heal, max(StartHealth-p.Health, 0))
+	p.Health += healed
+	p.Cards = slices.Delete(p.Cards, slot, slot+1)
+	if c.Status.Kind != "" {
+		p.afflict(c.Status)
+	}
+	if g.InBattle() {
+		g.endRound(BattleRound{Move: MoveItem, Item: c.Title, Healed: healed})
+	} else if c.Status.Kind != "" {
+		g.Logf("You drink %s: %s", c.Title, c.Status.Effect())
+	} else {
+		g.Logf("You drink %s (+%d health)", c.Title, healed)
+	}
+	return nil
+}
+
+// endRound applies a round's wounds, adds it to the transcript and ends the
+// fight if someone is down or the hero got away.
+func (g *Game) endRound(r BattleRound) {
+	b, p := g.Battle, g.Current()
+	b.MonsterHP = max(b.MonsterHP-r.Dealt, 0)
+	p.Health = max(p.Health-r.Taken, 0)
+	b.Rounds = append(b.Rounds, r)
+	g.Logf("Round %d: %s", len(b.Rounds), r)
+	switch {
+	case p.Health == 0:
+		g.finishBattle(OutcomeLoss)
+	case b.MonsterHP == 0:
+		g.finishBattle(OutcomeWin)
+	case r.Move == MoveFlee && r.Taken == 0:
+		g.finishBattle(OutcomeFled)
+	}
+}
+
+// finishBattle settles a fight's outcome, fills in the rest of the
+// transcript and resolves the card.
+func (g *Game) finishBattle(o Outcome) {
+	b, p := g.Battle, g.Current()
+	b.Outcome = o
+	b.Bonus = g.FightBonus
+	if bonus := p.FollowerBonus(); bonus > 0 {
+		b.FollowerNotes = append(b.FollowerNotes, fmt.Sprintf("your followers added +%d to every roll", bonus))
+	}
+	for _, r := range b.Rounds {
+		if r.Lost != "" {
+			b.FollowerNotes = append(b.FollowerNotes, r.Lost+" fell in the fight")
+		}
+	}
+	switch o {
+	case OutcomeWin:
+		trophy := g.Card
+		trophy.Reward = nil
+		p.Cards = append(p.Cards, trophy)
+		p.Stats.FightsWon++
+		if g.Card.Type == CrownType {
+			p.Won = true
+			b.Message = "The guardian kneels. The Crown is yours!"
+			g.Logf("You claim The Crown!")
+			break
+		}
+		if g.Card.IsBoss() {
+			b.Message = fmt.Sprintf("You have beaten %s! %s", g.Card.Title, g.beatBoss())
+			break
+		}
+		gold := g.Rng.Intn(3) + 1
+		p.Gold += gold
+		p.Stats.GoldEarned += gold
+		b.Message = fmt.Sprintf("You defeated the monster by %s! (Card collected, +%d gold)", b.Stat.Name(), gold)
+		g.Logf("Result: You win and take the card. (+%d gold)", gold)
+	case OutcomeLoss:
+		g.setAside()
+		p.Dead = true
+		p.Stats.FightsLost++
+		b.Died = true
+		b.Message = "The monster strikes you down! You have fallen."
+		if g.Card.Type == CrownType {
+			b.Message = "The guardian strikes you down! You have fallen."
+		}
+		g.died(g.Card)
+	case OutcomeFled:
+		g.setAside()
+		b.Message = fmt.Sprintf("You get away! (HP %d → %d)", b.HPBefore, p.Health)
+		g.Logf("You flee. HP %d → %d", b.HPBefore, p.Health)
+	}
+	b.HPAfter = p.Health
+	b.GoldAfter = p.Gold
+	g.CardMsg = b.Message
+	g.CardResolved = true
+}
+
+// HitPoints is how many wounds a monster or guardian takes to beat: the
+// card's HP, or one plus one for every six points of power.
+func (c Card) HitPoints() int {
+	if c.HP > 0 {
+		return c.HP
+	}
+	return 1 + c.Power()/6
+}
+
+// PotionSlot is the inventory slot of the hero's first healing potion, or
+// -1.
+func (p *Player) PotionSlot() int {
+	for i, c := range p.Cards {
+		if c.Type == PotionType && c.Heal > 0 {
+			return i
+		}
+	}
+	return -1
+}
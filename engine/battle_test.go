@@ -0,0 +1,167 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFightStat(t *testing.T) {
+	tests := []struct {
+		name  string
+		card  Card
+		stat  Stat
+		yours int // the hero's side of the roll before the die
+		its   int // the monster's
+	}{
+		{name: "monster", card: NewMonsterStrength(4), stat: StatStrength, yours: 10, its: 4},
+		{name: "magic monster", card: NewMonsterMagic(3), stat: StatMagic, yours: 20, its: 3},
+		{name: "guardian", card: NewCrownGuardian(12), stat: StatBoth, yours: 30, its: 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGame(1, 1)
+			p := g.Current()
+			p.Strength, p.Magic = 10, 20
+			meet(g, tt.card)
+			if err := g.Apply(Action{Kind: ActConfirmCard}); err != nil {
+				t.Fatal(err)
+			}
+			if g.Battle == nil || g.Battle.Stat != tt.stat {
+				t.Fatalf("battle %+v, want one fought with %s", g.Battle, tt.stat.Name())
+			}
+			if err := g.Apply(Action{Kind: ActAttack}); err != nil {
+				t.Fatal(err)
+			}
+			r := g.Battle.Rounds[0]
+			if r.YourTot-r.YourDie != tt.yours || r.MonTot-r.MonDie != tt.its {
+				t.Errorf("rolled %d+%d against %d+%d, want %d against %d", r.YourTot-r.YourDie, r.YourDie, r.MonTot-r.MonDie, r.MonDie, tt.yours, tt.its)
+			}
+		})
+	}
+}
+
+// fight has the hero land on c and take it on.
+func fight(t *testing.T, g *Game, c Card) {
+	t.Helper()
+	meet(g, c)
+	if err := g.Apply(Action{Kind: ActConfirmCard}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHitPoints(t *testing.T) {
+	tests := []struct {
+		card Card
+		want int
+	}{
+		{NewMonsterStrength(5), 1},
+		{NewMonsterStrength(6), 2},
+		{NewMonsterMagic(13), 3},
+		{Card{Type: MonsterType, Strength: 2, HP: 4}, 4},
+	}
+	for _, tt := range tests {
+		if got := tt.card.HitPoints(); got != tt.want {
+			t.Errorf("%s %d with HP %d: %d hit points, want %d", tt.card.Type, tt.card.Power(), tt.card.HP, got, tt.want)
+		}
+	}
+}
+
+func TestBattleRounds(t *testing.T) {
+	g := NewGame(1, 1)
+	p := g.Current()
+	p.Strength = 50
+	if err := g.Apply(Action{Kind: ActAttack}); !errors.Is(err, ErrIllegalAction) {
+		t.Fatalf("attacking with no fight: got %v", err)
+	}
+	fight(t, g, Card{Type: MonsterType, Strength: 1, HP: 3})
+	for round := 1; round <= 3; round++ {
+		if err := g.Apply(Action{Kind: ActConfirmCard}); !errors.Is(err, ErrIllegalAction) {
+			t.Fatalf("round %d: settling the card mid-fight: got %v", round, err)
+		}
+		if err := g.Apply(Action{Kind: ActAttack}); err != nil {
+			t.Fatal(err)
+		}
+		if want := 3 - round; g.Battle.MonsterHP != want || len(g.Battle.Rounds) != round {
+			t.Fatalf("round %d: monster on %d HP after %d rounds, want %d", round, g.Battle.MonsterHP, len(g.Battle.Rounds), want)
+		}
+		if round < 3 && !g.InBattle() {
+			t.Fatalf("round %d: the fight ended early", round)
+		}
+	}
+	if g.InBattle() || g.Battle.Outcome != OutcomeWin || !g.CardResolved {
+		t.Fatalf("outcome %v, want a won fight", g.Battle.Outcome)
+	}
+	if len(p.Cards) != 1 || p.Cards[0].HP != 3 || p.Gold <= 10 || p.Health != StartHealth {
+		t.Errorf("cards %v, gold %d, health %d; want the trophy, more gold and no wounds", p.Cards, p.Gold, p.Health)
+	}
+}
+
+func TestEvade(t *testing.T) {
+	tests := []struct {
+		name     string
+		strength int // the monster's, against the hero's 3
+		taken    bool
+	}{
+		{name: "out of reach", strength: 0},
+		{name: "overwhelmed", strength: 20, taken: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGame(1, 1)
+			fight(t, g, Card{Type: MonsterType, Strength: tt.strength, HP: 2})
+			if err := g.Apply(Action{Kind: ActEvade}); err != nil {
+				t.Fatal(err)
+			}
+			r := g.Battle.Rounds[0]
+			if r.Dealt != 0 || (r.Taken > 0) != tt.taken || g.Battle.MonsterHP != 2 {
+				t.Errorf("dealt %d, took %d; want no blow dealt and a wound %v", r.Dealt, r.Taken, tt.taken)
+			}
+		})
+	}
+}
+
+func TestFlee(t *testing.T) {
+	seen := map[bool]bool{} // got away
+	for seed := int64(1); len(seen) < 2 && seed <= 100; seed++ {
+		g := NewGame(seed, 1)
+		p := g.Current()
+		monster := NewMonsterStrength(2)
+		fight(t, g, monster)
+		if err := g.Apply(Action{Kind: ActFlee}); err != nil {
+			t.Fatal(err)
+		}
+		r := g.Battle.Rounds[0]
+		away := r.YourDie >= FleeRoll
+		seen[away] = true
+		if !away {
+			if r.Taken != 1 || p.Health != StartHealth-1 || !g.InBattle() {
+				t.Errorf("seed %d: d6 %d, took %d, in battle %v; want caught and hit", seed, r.YourDie, r.Taken, g.InBattle())
+			}
+			continue
+		}
+		if r.Taken != 0 || g.Battle.Outcome != OutcomeFled || len(p.Cards) != 0 {
+			t.Errorf("seed %d: d6 %d, took %d, outcome %v; want away unhurt and empty-handed", seed, r.YourDie, r.Taken, g.Battle.Outcome)
+		}
+		if left := g.World.Loops[0].Tiles[0].Card; left == nil || left.Strength != 2 {
+			t.Errorf("seed %d: left %v on the tile, want the monster", seed, left)
+		}
+	}
+	if len(seen) < 2 {
+		t.Fatal("never saw both a clean escape and a caught hero")
+	}
+}
+
+func TestPotionTakesARound(t *testing.T) {
+	g := NewGame(1, 1)
+	p := g.Current()
+	p.Health = 2
+	p.Cards = []Card{NewPotion(2)}
+	fight(t, g, NewMonsterStrength(4))
+	if err := g.Apply(Action{Kind: ActUseItem, Slot: 0}); err != nil {
+		t.Fatal(err)
+	}
+	r := g.Battle.Rounds[0]
+	if r.Move != MoveItem || r.Healed != 2 || r.YourDie != 0 || p.Health != 4 || len(p.Cards) != 0 || !g.InBattle() {
+		t.Errorf("round %+v, health %d; want a potion round healing 2 with the fight still on", r, p.Health)
+	}
+}
@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Every region but The Crown may have a boss: a monster far tougher than
+// anything in its deck that guards one tile of the region, however many
+// loops the region is dealt. Landing there brings on the boss instead of a card from the deck, and the
+// first hero to beat it takes its reward. A beaten boss is gone for good.
+
+// IsBoss reports whether c is a region boss.
+func (c Card) IsBoss() bool { return c.Reward != nil && c.FightStat() != "" }
+
+// BossAt reports whether an unbeaten boss guards tile id.
+func (w *World) BossAt(id TileID) bool {
+	return w.Loops[id.Loop].Tiles[id.Index].Boss
+}
+
+// placeBosses puts the boss of each region among the first n loops on one
+// tile of the region's loops. Only tiles a hero can walk to from the start
+// without a Talisman are picked, so every boss can be reached; junctions
+// and the start tile are left alone while there is anything else.
+func placeBosses(r *rand.Rand, w *World, n int) {
+	reach := reachableFromStart(w)
+	var regions []string // in the order they are first met, for a stable draw
+	quiet := map[string][]TileID{}
+	any := map[string][]TileID{}
+	for li := range n {
+		l := &w.Loops[li]
+		if !l.Type.Boss.IsBoss() {
+			continue
+		}
+		name := l.Type.Name
+		if _, ok := any[name]; !ok {
+			regions = append(regions, name)
+			any[name] = nil
+		}
+		for ti, t := range l.Tiles {
+			id := TileID{li, ti}
+			if !reach[id] || t.Bridge {
+				continue
+			}
+			any[name] = append(any[name], id)
+			if len(t.Links) == 0 && id != (TileID{0, 0}) {
+				quiet[name] = append(quiet[name], id)
+			}
+		}
+	}
+	for _, name := range regions {
+		ids := any[name]
+		if len(quiet[name]) > 0 {
+			ids = quiet[name]
+		}
+		if len(ids) > 0 {
+			id := ids[r.Intn(len(ids))]
+			w.Loops[id.Loop].Tiles[id.Index].Boss = true
+		}
+	}
+}
+
+// reachableFromStart is every tile a hero can walk to from the first tile
+// without entering The Crown or a shop.
+func reachableFromStart(w *World) map[TileID]bool {
+	start := TileID{0, 0}
+	seen := map[TileID]bool{start: true}
+	queue := []TileID{start}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		t := w.Loops[id.Loop].Tiles[id.Index]
+		for _, nb := range append([]TileID{t.Next, t.Prev}, t.Links...) {
+			if seen[nb] || isShopTile(w, nb) || w.Loops[nb.Loop].Type.Crown {
+				continue
+			}
+			seen[nb] = true
+			queue = append(queue, nb)
+		}
+	}
+	return seen
+}
+
+// beatBoss clears the boss from the hero's tile and hands over its reward.
+// It returns what the hero got, for the battle's message.
+func (g *Game) beatBoss() string {
+	p := g.Current()
+	g.World.Loops[p.At.Loop].Tiles[p.At.Index].Boss = false
+	p.Stats.BossesBeaten++
+	c := *g.Card.Reward
+	g.Logf("%s is beaten! Its reward: %s", g.Card.Title, c.Title)
+	return p.claim(c)
+}
+
+// claim hands the hero a reward card. Gear is worn straight away if its
+// slot is free; anything else is met as if drawn. It returns what the hero
+// got.
+func (p *Player) claim(c Card) string {
+	if c.Type == ShopItemType {
+		if _, worn := p.Worn(c.Slot); !worn {
+			p.Equipped[c.Slot.Index()] = c
+			return fmt.Sprintf("You take %s and put it on.", c.Title)
+		}
+		if why := p.Fits(c); why != "" {
+			return fmt.Sprintf("You must leave %s behind: %s.", c.Title, why)
+		}
+		p.Cards = append(p.Cards, c)
+		return fmt.Sprintf("You take %s.", c.Title)
+	}
+	return p.Interact(&c).Message
+}
@@ -0,0 +1,117 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlaceBosses(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		w, counts := buildWorld(seed)
+		reach := reachableFromStart(&w)
+		bosses := map[string]int{} // boss tiles per region
+		for li, l := range w.Loops {
+			if li < len(counts) && l.Type.Boss.IsBoss() {
+				bosses[l.Type.Name] += 0
+			}
+			for ti, tile := range l.Tiles {
+				if !tile.Boss {
+					continue
+				}
+				id := TileID{li, ti}
+				if li >= len(counts) || !l.Type.Boss.IsBoss() {
+					t.Errorf("seed %d: boss on %v in %s, which has none", seed, id, l.Type.Name)
+				}
+				if !reach[id] || tile.Bridge || id == (TileID{0, 0}) {
+					t.Errorf("seed %d: boss on %v is out of reach, on a bridge or on the start", seed, id)
+				}
+				bosses[l.Type.Name]++
+			}
+		}
+		for name, n := range bosses {
+			if n != 1 {
+				t.Errorf("seed %d: %s has %d boss tiles, want 1", seed, name, n)
+			}
+		}
+	}
+}
+
+func TestRegionBoss(t *testing.T) {
+	deck := Deck{Name: "woods", Cards: []Card{NewMonsterStrength(4), NewBuffStrength(1)}}
+	boss := &cardJSON{Type: MonsterType, Strength: 7, Title: "Chief"}
+	sword := &cardJSON{Type: ShopItemType, Strength: 2, Slot: Weapon, Title: "Cutlass"}
+	tests := []struct {
+		name   string
+		rj     regionJSON
+		err    string // part of the error, "" if it is fine
+		isBoss bool
+	}{
+		{name: "none", rj: regionJSON{}},
+		{name: "boss and reward", rj: regionJSON{Boss: boss, Reward: sword}, isBoss: true},
+		{name: "potion reward", rj: regionJSON{Boss: boss, Reward: &cardJSON{Type: PotionType, Heal: 2}}, isBoss: true},
+		{name: "no reward", rj: regionJSON{Boss: boss}, err: "go together"},
+		{name: "no boss", rj: regionJSON{Reward: sword}, err: "go together"},
+		{name: "crown", rj: regionJSON{Crown: true, Boss: boss, Reward: sword}, err: "guardian"},
+		{name: "not a monster", rj: regionJSON{Boss: &cardJSON{Type: BuffType, Strength: 7}, Reward: sword}, err: "monster or magicMonster"},
+		{name: "too weak", rj: regionJSON{Boss: &cardJSON{Type: MonsterType, Strength: 4}, Reward: sword}, err: "stronger than everything"},
+		{name: "fought reward", rj: regionJSON{Boss: boss, Reward: &cardJSON{Type: MonsterType, Strength: 2}}, err: "cannot be fought"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rg := Region{Deck: deck.Name, Crown: tt.rj.Crown}
+			err := tt.rj.boss(&rg, deck)
+			if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Fatalf("got %v, want an error about %q", err, tt.err)
+			}
+			if rg.Boss.IsBoss() != tt.isBoss {
+				t.Fatalf("boss %+v, want a boss: %v", rg.Boss, tt.isBoss)
+			}
+		})
+	}
+}
+
+func TestBeatBoss(t *testing.T) {
+	sword := Card{Type: ShopItemType, Title: "Cutlass", Strength: 2, Slot: Weapon}
+	club := Card{Type: ShopItemType, Title: "Club", Strength: 1, Slot: Weapon}
+	tests := []struct {
+		name   string
+		setup  func(p *Player)
+		worn   string // the weapon afterwards
+		packed bool   // the cutlass went into the pack
+	}{
+		{name: "free slot", worn: "Cutlass"},
+		{name: "slot taken", setup: func(p *Player) { p.Equipped[Weapon.Index()] = club }, worn: "Club", packed: true},
+		{name: "slot taken and pack full", setup: func(p *Player) {
+			p.Equipped[Weapon.Index()] = club
+			for p.PackRoom() > 0 {
+				p.Cards = append(p.Cards, NewPotion(1))
+			}
+		}, worn: "Club"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGame(1, 1)
+			p := g.Current()
+			if tt.setup != nil {
+				tt.setup(p)
+			}
+			g.World.Loops[0].Tiles[0].Boss = true
+			g.Card = NewMonsterStrength(9)
+			g.Card.Reward = &sword
+			g.beatBoss()
+			if g.World.BossAt(p.At) || p.Stats.BossesBeaten != 1 {
+				t.Fatal("the boss is still there")
+			}
+			if c, _ := p.Worn(Weapon); c.Title != tt.worn {
+				t.Errorf("wielding %q, want %q", c.Title, tt.worn)
+			}
+			packed := false
+			for _, c := range p.Cards {
+				packed = packed || c.Title == "Cutlass"
+			}
+			if packed != tt.packed {
+				t.Errorf("cutlass in the pack: %v, want %v", packed, tt.packed)
+			}
+		})
+	}
+}
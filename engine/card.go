@@ -0,0 +1,362 @@
+package engine
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+)
+
+type CardType string
+
+const (
+	MonsterType      CardType = "monster"
+	MagicMonsterType CardType = "magicMonster"
+	BuffType         CardType = "buff"
+	ShopItemType     CardType = "shopItem"
+	TalismanType     CardType = "talisman" // opens the way into The Crown
+	CrownType        CardType = "crown"    // the final encounter; beating it wins the run
+	SpellType        CardType = "spell"    // learned into a spell slot, cast later
+	PotionType       CardType = "potion"   // carried in the inventory, drunk for health or a status
+	FollowerType     CardType = "follower" // joins the hero and helps while it stays
+)
+
+type Card struct {
+	Type        CardType
+	Strength    int
+	Magic       int
+	Title, Text string
+	Weight      int          `json:",omitempty"` // relative draw chance; 0 means 1
+	Rarity      Rarity       `json:",omitempty"` // how seldom it turns up; "" is common
+	Spell       Spell        `json:",omitzero"`  // set on spell cards
+	HP          int          `json:",omitempty"` // wounds a monster takes to beat; 0 means HitPoints' default
+	Heal        int          `json:",omitempty"` // health a potion restores
+	Slot        EquipSlot    `json:",omitempty"` // where a shop item is worn
+	Follower    Follower     `json:",omitzero"`  // set on follower cards
+	Status      Status       `json:",omitzero"`  // what a potion puts on the hero who drinks it
+	Abilities   []Ability    `json:",omitempty"` // what a monster does beyond its power
+	Reward      *Card        `json:",omitempty"` // what beating a region boss earns, or the item in a treasure
+	Effect      Effect       `json:",omitzero"`  // what an event does to every hero
+	Offers      []Effect     `json:",omitempty"` // a stranger's deals
+	Table       []TableEntry `json:",omitempty"` // a place's dice table, by ascending From
+	Damage      int          `json:",omitempty"` // health a trap takes from a hero who fails its check
+	Gold        int          `json:",omitempty"` // gold in a treasure
+	Script      Script       `json:",omitempty"` // what a scripted card does
+}
+
+// Stat is the hero attribute a fight is decided by.
+type Stat string
+
+const (
+	StatStrength Stat = "STR"
+	StatMagic    Stat = "MAG"
+	StatBoth     Stat = "STR+MAG" // body and mind together
+)
+
+// Name is the stat spelled out for messages.
+func (s Stat) Name() string {
+	switch s {
+	case StatStrength:
+		return "Strength"
+	case StatMagic:
+		return "Magic"
+	case StatBoth:
+		return "Strength and Magic"
+	}
+	return string(s)
+}
+
+// FightStat is the stat both sides fight c with, or "" if c is not fought.
+// Magic monsters are psychic battles: the hero's Magic against theirs.
+func (c Card) FightStat() Stat {
+	switch c.Type {
+	case MonsterType:
+		return StatStrength
+	case MagicMonsterType:
+		return StatMagic
+	case CrownType:
+		return StatBoth
+	}
+	return ""
+}
+
+// Power is what the card brings to a fight.
+func (c Card) Power() int {
+	if c.Type == MagicMonsterType {
+		return c.Magic
+	}
+	return c.Strength
+}
+
+// Rarity is how seldom a card turns up. Each step rarer halves a card's
+// lots in weighted draws, and legendary stock only reaches the shops late
+// in a run.
+type Rarity string
+
+const (
+	RarityCommon    Rarity = "common"
+	RarityUncommon  Rarity = "uncommon"
+	RarityRare      Rarity = "rare"
+	RarityLegendary Rarity = "legendary"
+)
+
+var Rarities = []Rarity{RarityCommon, RarityUncommon, RarityRare, RarityLegendary}
+
+// LegendaryShopRound is the first round a shop restocks with legendary
+// cards. Rounds rather than turns, so a full table waits as long as a lone
+// hero.
+const LegendaryShopRound = 20
+
+// Lots is the rarity's share of a weighted draw, before the card's weight.
+func (r Rarity) Lots() int {
+	switch r {
+	case RarityUncommon:
+		return 4
+	case RarityRare:
+		return 2
+	case RarityLegendary:
+		return 1
+	}
+	return 8
+}
+
+// DrawWeight is how many lots the card gets in a weighted draw: its weight
+// times its rarity's lots.
+func (c Card) DrawWeight() int { return max(c.Weight, 1) * c.Rarity.Lots() }
+
+type Deck struct {
+	Name  string
+	Cards []Card
+}
+
+// ---------- Card factories ----------
+
+func NewMonster(r *rand.Rand, str int) Card {
+	if r.Intn(2) == 1 {
+		return NewMonsterStrength(str)
+	} else {
+		return NewMonsterMagic(str)
+	}
+}
+
+func NewMonsterStrength(str int) Card {
+	return Card{
+		Type:     MonsterType,
+		Strength: str,
+		Title:    "A Monster Appears!",
+		Text:     fmt.Sprintf("A fearsome foe blocks the way.\nMonster Strength: %d", str),
+	}
+}
+func NewMonsterMagic(magic int) Card {
+	return Card{
+		Type:  MagicMonsterType,
+		Magic: magic,
+		Title: "A Magic Monster Appears!",
+		Text:  fmt.Sprintf("A fearsome foe blocks the way.\nMonster Magic: %d", magic),
+	}
+}
+func NewBuff(r *rand.Rand, str int) Card {
+	if r.Intn(2) == 1 {
+		return NewBuffStrength(str)
+	} else {
+		return NewBuffMagic(str)
+	}
+}
+
+func NewBuffStrength(str int) Card {
+	return Card{
+		Type:     BuffType,
+		Strength: str,
+		Title:    "A Blessing!",
+		Text:     fmt.Sprintf("A boon empowers you.\nGain +%d Strength.", str),
+	}
+}
+func NewBuffMagic(str int) Card {
+	return Card{
+		Type:  BuffType,
+		Magic: str,
+		Title: "A Magic Blessing!",
+		Text:  fmt.Sprintf("A boon empowers you.\nGain +%d Magic.", str),
+	}
+}
+
+func NewTalisman() Card {
+	return Card{
+		Type:  TalismanType,
+		Title: "The Talisman",
+		Text:  "An ancient charm that lets its bearer pass\ninto The Crown.",
+	}
+}
+
+func NewCrownGuardian(str int) Card {
+	return Card{
+		Type:     CrownType,
+		Strength: str,
+		Title:    "The Crown of Command",
+		Text:     fmt.Sprintf("Its guardian tests body and mind alike.\nGuardian Strength: %d", str),
+	}
+}
+
+func NewSpell(kind SpellKind, power int) Card {
+	s := Spell{Kind: kind, Power: power}
+	titles := map[SpellKind]string{
+		SpellBattle:   "Battle Spell",
+		SpellStride:   "Stride Spell",
+		SpellTeleport: "Teleport",
+	}
+	return Card{
+		Type:  SpellType,
+		Spell: s,
+		Title: titles[kind],
+		Text:  fmt.Sprintf("Cast %s:\n%s.", s.When(), s.Effect()),
+	}
+}
+
+func NewPotion(heal int) Card {
+	return Card{
+		Type:  PotionType,
+		Heal:  heal,
+		Title: "Healing Potion",
+		Text:  fmt.Sprintf("Drink it in or out of a fight.\nRestores %d Health.", heal),
+	}
+}
+
+// NewStatusPotion is a potion that puts s on the hero, healing heal too.
+func NewStatusPotion(heal int, s Status) Card {
+	c := NewPotion(heal)
+	c.Status = s
+	c.Title = "Elixir"
+	c.Text = fmt.Sprintf("Drink it in or out of a fight.\n%s.", c.PotionEffect())
+	return c
+}
+
+// PotionEffect describes what drinking the potion does.
+func (c Card) PotionEffect() string {
+	var parts []string
+	if c.Heal > 0 {
+		parts = append(parts, fmt.Sprintf("Restores %d Health", c.Heal))
+	}
+	if c.Status.Kind != "" {
+		parts = append(parts, fmt.Sprintf("%s for %d turns: %s", c.Status.Kind, c.Status.Turns, c.Status.Effect()))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func NewFollower(kind FollowerKind, power int) Card {
+	f := Follower{Kind: kind, Power: power}
+	titles := map[FollowerKind]string{
+		FollowerFighter: "Mercenary",
+		FollowerPorter:  "Porter",
+		FollowerGuide:   "Guide",
+	}
+	return Card{
+		Type:     FollowerType,
+		Follower: f,
+		Title:    titles[kind],
+		Text:     fmt.Sprintf("A companion for the road:\n%s.", f.Effect()),
+	}
+}
+
+// Randomized helpers (tweak ranges to taste)
+func RandMonster(r *rand.Rand, minStr, maxStr int) Card {
+	if maxStr < minStr {
+		maxStr = minStr
+	}
+	return NewMonster(r, minStr+r.Intn(maxStr-minStr+1))
+}
+func RandBuff(r *rand.Rand, minStr, maxStr int) Card {
+	if maxStr < minStr {
+		maxStr = minStr
+	}
+	return NewBuff(r, minStr+r.Intn(maxStr-minStr+1))
+}
+
+// RandCard makes up an encounter for a loop with no deck, picked from
+// randCards by DrawWeight like a deck draw.
+func RandCard(r *rand.Rand) Card { return drawWeighted(r, randCards) }
+
+// randCards are monsters of strength or magic 2-8 and buffs of 1-3, the
+// stronger the rarer. Monsters come up on a little over half the draws.
+var randCards = func() []Card {
+	var cs []Card
+	add := func(weight int, rarity Rarity, cards ...Card) {
+		for _, c := range cards {
+			c.Weight, c.Rarity = weight, rarity
+			cs = append(cs, c)
+		}
+	}
+	for str, rarity := range []Rarity{2: RarityCommon, RarityCommon, RarityCommon, RarityUncommon, RarityUncommon, RarityRare, RarityRare} {
+		if rarity != "" {
+			add(10, rarity, NewMonsterStrength(str), NewMonsterMagic(str))
+		}
+	}
+	for str, rarity := range []Rarity{1: RarityCommon, RarityUncommon, RarityRare} {
+		if rarity != "" {
+			add(21, rarity, NewBuffStrength(str), NewBuffMagic(str))
+		}
+	}
+	return cs
+}()
+
+// RandShopCard stocks a shelf from the keeper's deck. Legendary cards are
+// held back until LegendaryShopRound.
+func RandShopCard(r *rand.Rand, keeperType, round int) Card {
+	if keeperType < 0 || keeperType >= len(content.Shops) {
+		keeperType = 0
+	}
+	cards := content.Shops[keeperType].Cards
+	if round < LegendaryShopRound {
+		held := slices.DeleteFunc(slices.Clone(cards), func(c Card) bool { return c.Rarity == RarityLegendary })
+		if len(held) > 0 {
+			cards = held
+		}
+	}
+	return drawWeighted(r, cards)
+}
+
+// drawWeighted picks one card, each with a chance proportional to its
+// weight. It uses a single draw from r whatever the weights.
+func drawWeighted(r *rand.Rand, cs []Card) Card {
+	total := 0
+	for _, c := range cs {
+		total += c.DrawWeight()
+	}
+	n := r.Intn(total)
+	for _, c := range cs {
+		if n -= c.DrawWeight(); n < 0 {
+			return c
+		}
+	}
+	return cs[len(cs)-1]
+}
+
+// TalismanPrice is the base cost of a Talisman in the shops.
+const TalismanPrice = 40
+
+// spellPrice is the base cost of a spell in the shops.
+func spellPrice(s Spell) int {
+	if s.Kind == SpellTeleport {
+		return 12
+	}
+	return s.Power * 4
+}
+
+// ShopPrice is what a shopkeeper asks for a card: a base cost from its stats
+// plus 1-5 gold of haggling noise.
+func ShopPrice(r *rand.Rand, c Card) int {
+	baseCost := 0
+	if c.Type == TalismanType {
+		baseCost = TalismanPrice
+	} else if c.Type == SpellType {
+		baseCost = spellPrice(c.Spell)
+	} else if c.Type == PotionType {
+		baseCost = c.Heal*4 + statusPrice(c.Status)
+	} else if c.Type == FollowerType {
+		baseCost = followerPrice(c.Follower)
+	} else if c.Strength > 0 && c.Magic > 0 {
+		baseCost = (c.Strength + c.Magic) * 4 // Mixed items cost more
+	} else {
+		baseCost = max(c.Strength, c.Magic) * 3
+	}
+	return baseCost + r.Intn(5) + 1 // Add 1-5 gold randomness
+}
@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestDrawWeight(t *testing.T) {
+	tests := []struct {
+		card Card
+		want int
+	}{
+		{Card{}, 8},
+		{Card{Weight: 3}, 24},
+		{Card{Rarity: RarityUncommon}, 4},
+		{Card{Rarity: RarityRare, Weight: 2}, 4},
+		{Card{Rarity: RarityLegendary}, 1},
+	}
+	for _, tt := range tests {
+		if got := tt.card.DrawWeight(); got != tt.want {
+			t.Errorf("%s weight %d: %d lots, want %d", tt.card.Rarity, tt.card.Weight, got, tt.want)
+		}
+	}
+}
+
+func TestRandCard(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	monsters := 0
+	byRarity := map[Rarity]int{}
+	const n = 10000
+	for range n {
+		c := RandCard(r)
+		if c.FightStat() != "" {
+			monsters++
+		}
+		byRarity[c.Rarity]++
+	}
+	if monsters < n*50/100 || monsters > n*60/100 {
+		t.Errorf("%d monsters in %d cards, want about 55%%", monsters, n)
+	}
+	if !(byRarity[RarityCommon] > byRarity[RarityUncommon] && byRarity[RarityUncommon] > byRarity[RarityRare] && byRarity[RarityRare] > 0) {
+		t.Errorf("drawn by rarity %v, want fewer of each rarer kind", byRarity)
+	}
+}
+
+func TestRandShopCardHoldsBackLegendaries(t *testing.T) {
+	legendary := -1
+	for i, shop := range content.Shops {
+		for _, c := range shop.Cards {
+			if c.Rarity == RarityLegendary {
+				legendary = i
+			}
+		}
+	}
+	if legendary < 0 {
+		t.Skip("no shop sells a legendary card")
+	}
+	r := rand.New(rand.NewSource(1))
+	count := func(round int) int {
+		n := 0
+		for range 2000 {
+			if RandShopCard(r, legendary, round).Rarity == RarityLegendary {
+				n++
+			}
+		}
+		return n
+	}
+	if n := count(LegendaryShopRound - 1); n != 0 {
+		t.Errorf("%d legendary cards before round %d", n, LegendaryShopRound)
+	}
+	if n := count(LegendaryShopRound); n == 0 {
+		t.Errorf("no legendary cards from round %d", LegendaryShopRound)
+	}
+}
+
+func TestRounds(t *testing.T) {
+	g := NewGame(1, 4)
+	for turn := 1; turn <= 8; turn++ {
+		g.endTurn()
+		if want := turn / 4; g.Round != want {
+			t.Fatalf("after %d turns: round %d, want %d", turn, g.Round, want)
+		}
+	}
+	g.DeathRule = Permadeath
+	g.Players[1].Dead = true
+	g.Players[2].Dead = true
+	g.endTurn() // to the last hero
+	g.endTurn() // and round again
+	if g.Active != 0 || g.Round != 3 {
+		t.Fatalf("hero %d in round %d, want hero 0 in round 3", g.Active, g.Round)
+	}
+}
+
+func TestShopRestocks(t *testing.T) {
+	g := NewGame(1, 1)
+	shop := g.AdjacentShop()
+	if shop == nil {
+		t.Fatal("no shop beside the start")
+	}
+	stock := shop.Cards
+	g.Round = ShopRestockRounds - 1
+	if !g.openShop() || shop.Stocked != 0 || !reflect.DeepEqual(shop.Cards, stock) {
+		t.Fatalf("shelves stocked in round %d changed in round %d", shop.Stocked, g.Round)
+	}
+	g.closeShop()
+	g.Round = ShopRestockRounds
+	if !g.openShop() || shop.Stocked != ShopRestockRounds {
+		t.Fatalf("shelves stocked in round %d, want %d", shop.Stocked, ShopRestockRounds)
+	}
+	if !reflect.DeepEqual(g.ShopCards, shop.Cards) {
+		t.Error("the shop shows the old stock")
+	}
+}
@@ -0,0 +1,72 @@
+package engine
+
+// The Crown is the goal of a run: one region of every world is sealed off
+// until a hero carries a Talisman, and whoever beats the encounter waiting
+// inside wins.
+
+// Winner is the hero who claimed The Crown, or nil while the run goes on.
+func (g *Game) Winner() *Player {
+	for i := range g.Players {
+		if g.Players[i].Won {
+			return &g.Players[i]
+		}
+	}
+	return nil
+}
+
+// crownOpen reports whether the current hero may step into The Crown.
+func (g *Game) crownOpen() bool { return g.Current().HasTalisman() }
+
+// CrownLoop is the index of the world's Crown loop, or -1 if it has none.
+func (w *World) CrownLoop() int {
+	for i, l := range w.Loops {
+		if l.Type.Crown {
+			return i
+		}
+	}
+	return -1
+}
+
+// crownRegion is the region The Crown is built from, if the data has one.
+func crownRegion() (Region, bool) {
+	for _, rg := range content.Regions {
+		if rg.Crown {
+			return rg, true
+		}
+	}
+	return Region{}, false
+}
+
+// centralLeaf picks the loop for The Crown: of the loops nothing else was
+// anchored to, the one nearest the middle of the world. Only a leaf can be
+// sealed without cutting other loops off from the start. It returns -1 when
+// there is no loop besides the first.
+func centralLeaf(specs []rectSpec, parent []int) int {
+	anchor := make([]bool, len(specs))
+	for _, p := range parent {
+		if p >= 0 {
+			anchor[p] = true
+		}
+	}
+	var cx, cy float64
+	for _, s := range specs {
+		cx += float64(s.gx) + float64(s.cols)/2
+		cy += float64(s.gy) + float64(s.rows)/2
+	}
+	cx /= float64(len(specs))
+	cy /= float64(len(specs))
+
+	best, bestDist := -1, 0.0
+	for i := 1; i < len(specs); i++ {
+		if anchor[i] {
+			continue
+		}
+		s := specs[i]
+		dx := float64(s.gx) + float64(s.cols)/2 - cx
+		dy := float64(s.gy) + float64(s.rows)/2 - cy
+		if d := dx*dx + dy*dy; best < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
@@ -0,0 +1,696 @@
+package engine
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+)
+
+// The deck and region data the game ships with. Designers edit the files in
+// engine/data and try them out with -data engine/data; rebuilding bakes them
+// in as the defaults.
+//
+//go:embed data/*.json
+var defaultData embed.FS
+
+// DecksFile lists the encounter and shop decks:
+//
+//	{
+//	  "decks": [{"name": "deck1", "cards": [CARD, ...]}, ...],
+//	  "shops": [{"name": "mystic", "cards": [CARD, ...]}, ...]
+//	}
+//
+// where CARD is
+//
+//	{"type": "monster", "strength": 2, "magic": 0, "title": "...", "text": "...", "count": 1, "weight": 1}
+//
+// Only type and the stat that matters for it are required. count is how many
+// copies the deck holds and weight how likely each copy is to be drawn; both
+// default to 1. The cards every deck has always had get the usual title and
+// text when none is given. Shops sell shopItem, talisman, spell, potion and
+// follower cards; a "crown" card is the final encounter and belongs in the
+// crown region's deck. Spell and follower cards name their kind and power,
+// and a potion the health it restores, the status it puts on the drinker,
+// or both:
+//
+//	{"type": "spell", "spell": "battle", "power": 2, "title": "Fireball"}
+//	{"type": "follower", "follower": "fighter", "power": 1, "title": "Squire"}
+//	{"type": "potion", "heal": 2}
+//	{"type": "potion", "status": {"kind": "blessing", "turns": 1}}
+//
+// Statuses last turns rolls: poison (lose power health a turn), curse
+// (-power Strength) or blessing (the roll is a six).
+//
+// Followers are fighters (+power to fight rolls), porters (+power pack room)
+// or guides (power movement rerolls a turn).
+//
+// Any card may set "rarity": common (the default), uncommon, rare or
+// legendary. Each step rarer halves its draw chance, and legendary shop
+// stock is only put out from round LegendaryShopRound:
+//
+//	{"type": "monster", "strength": 9, "rarity": "rare"}
+//
+// Monsters and crown guardians may set "hp", the wounds they take to beat;
+// by default it grows with their strength or magic. They may also list
+// "abilities": ambush (a free blow as it appears; no walking away),
+// regenerating (wins tied rounds), thief (steals gold with each wound),
+// venomous (its wounds poison) and magicOnly (only hurt once a battle spell
+// is cast; not for magic monsters):
+//
+//	{"type": "monster", "strength": 5, "abilities": ["ambush", "thief"]}
+//
+// Events, strangers, places, traps and treasure need a title and bring their
+// own text. An event has an "effect" that befalls every hero; a stranger has
+// up to four "offers" to take one of; a place has a dice "table" whose rows
+// start at a roll, the first from 1. An effect has text and any of gold,
+// health, strength, magic and a status, and an event's may move every hero
+// along its loop; gold and health may be negative. A trap tests strength or
+// magic against its value and takes damage health from a hero who falls
+// short. Treasure holds gold, an item (a shopItem, spell, potion or
+// follower), or both:
+//
+//	{"type": "event", "title": "Gale", "effect": {"text": "A gale sweeps the land", "move": 2}}
+//	{"type": "stranger", "title": "Pedlar", "offers": [{"text": "A charm", "gold": -4, "magic": 1}]}
+//	{"type": "place", "title": "Old Well", "table": [{"from": 1, "text": "Bad water", "health": -1}, {"from": 4, "text": "Coins", "gold": 3}]}
+//	{"type": "trap", "title": "Pit", "strength": 7, "damage": 1}
+//	{"type": "treasure", "title": "Chest", "gold": 6, "item": CARD}
+//
+// A scripted card needs a title and does what its "script" says; see Script
+// for the language. Unknown ops and bad amounts are reported here:
+//
+//	{"type": "scripted", "title": "Mossy Statue", "script": "lose gold 1; if roll>=5 then gain magic 1"}
+//
+// A shopItem is equipment and names the slot it is worn in: weapon, armour,
+// helm or trinket.
+//
+// The deck named "spells" is what heroes draw from when they trade in
+// trophies for a spell.
+const DecksFile = "decks.json"
+
+// ShopKeepers names the shop decks in KeeperType order.
+var ShopKeepers = []string{"mystic", "ogre", "monkey", "pig", "dolphin"}
+
+// gameData is everything the rules deal cards and build worlds from.
+type gameData struct {
+	Decks   map[string]Deck // encounter decks by name
+	Shops   []Deck          // indexed by ShopType.KeeperType
+	Regions []Region
+	Sum     string `json:"-"` // tells data sets apart; see DataSum
+}
+
+// content is the data in use. It is set once at startup, before any game is
+// created, and only read after that.
+var content = mustLoadDefault()
+
+func mustLoadDefault() gameData {
+	sub, _ := fs.Sub(defaultData, "data")
+	gd, err := loadData(sub, "engine/data")
+	if err != nil {
+		panic(err)
+	}
+	return gd
+}
+
+// LoadData replaces the built-in decks and regions with the files in dir.
+func LoadData(dir string) error {
+	gd, err := loadData(os.DirFS(dir), dir)
+	if err != nil {
+		return err
+	}
+	content = gd
+	return nil
+}
+
+func loadData(fsys fs.FS, dir string) (gameData, error) {
+	gd, err := loadCardData(fsys, dir)
+	if err != nil {
+		return gameData{}, err
+	}
+	if gd.Regions, err = loadRegions(fsys, dir, gd.Decks); err != nil {
+		return gameData{}, err
+	}
+	data, err := json.Marshal(gd)
+	if err != nil {
+		return gameData{}, err
+	}
+	gd.Sum = fmt.Sprintf("%x", sha256.Sum256(data))[:16]
+	return gd, nil
+}
+
+// DataSum identifies the decks and regions in use. Data that deals the same
+// cards and builds the same worlds has the same sum, however it is laid out
+// in the files.
+func DataSum() string { return content.Sum }
+
+func loadCardData(fsys fs.FS, dir string) (gameData, error) {
+	data, err := fs.ReadFile(fsys, DecksFile)
+	if err != nil {
+		return gameData{}, err
+	}
+	f := &dataFile{name: path.Join(dir, DecksFile), data: data}
+	var decks, shops []Deck
+	err = f.object(f.dec(), func(dec *json.Decoder, key string, off int64) error {
+		var err error
+		switch key {
+		case "decks":
+			decks, err = f.decks(dec, false)
+		case "shops":
+			shops, err = f.decks(dec, true)
+		default:
+			return f.errorf(off, "unknown section %q (want decks or shops)", key)
+		}
+		return err
+	})
+	if err != nil {
+		return gameData{}, err
+	}
+
+	cd := gameData{Decks: map[string]Deck{}}
+	for _, d := range decks {
+		cd.Decks[d.Name] = d
+	}
+	if d, ok := cd.Decks[SpellDeck]; ok && slices.ContainsFunc(d.Cards, func(c Card) bool { return c.Type != SpellType }) {
+		return gameData{}, fmt.Errorf("%s: the %q deck may only hold spell cards", f.name, SpellDeck)
+	}
+	for _, keeper := range ShopKeepers {
+		i := slices.IndexFunc(shops, func(d Deck) bool { return d.Name == keeper })
+		if i < 0 {
+			return gameData{}, fmt.Errorf("%s: no shop deck for the %s shopkeeper", f.name, keeper)
+		}
+		cd.Shops = append(cd.Shops, shops[i])
+	}
+	return cd, nil
+}
+
+// deckNamed returns a copy of an encounter deck, or an empty deck (which
+// deals random cards) if there is none by that name.
+func deckNamed(name string) Deck {
+	d := content.Decks[name]
+	d.Cards = slices.Clone(d.Cards)
+	return d
+}
+
+// dataFile decodes one data file and words errors as file:line.
+type dataFile struct {
+	name string
+	data []byte
+	seen map[string]int64 // deck names already defined, by offset
+}
+
+func (f *dataFile) dec() *json.Decoder {
+	dec := json.NewDecoder(bytes.NewReader(f.data))
+	dec.DisallowUnknownFields()
+	return dec
+}
+
+// skip moves off past separators to the start of the next value.
+func (f *dataFile) skip(off int64) int64 {
+	off = min(off, int64(len(f.data)))
+	for off < int64(len(f.data)) && bytes.IndexByte([]byte(" \t\r\n,:"), f.data[off]) >= 0 {
+		off++
+	}
+	return off
+}
+
+// line is the line of the first value at or after off.
+func (f *dataFile) line(off int64) int {
+	return 1 + bytes.Count(f.data[:f.skip(off)], []byte("\n"))
+}
+
+func (f *dataFile) errorf(off int64, format string, args ...any) error {
+	return fmt.Errorf("%s:%d: %s", f.name, f.line(off), fmt.Sprintf(format, args...))
+}
+
+// wrap places a decoding error at the offset the decoder reports, or at
+// off, where the value being decoded starts.
+func (f *dataFile) wrap(off int64, err error) error {
+	var syn *json.SyntaxError
+	var typ *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syn):
+		off = syn.Offset - 1 // counted from the start of the file
+	case errors.As(err, &typ):
+		off = f.skip(off) + typ.Offset - 1 // counted from the start of the value
+	}
+	return f.errorf(off, "%v", err)
+}
+
+func (f *dataFile) delim(dec *json.Decoder, want json.Delim) error {
+	off := dec.InputOffset()
+	tok, err := dec.Token()
+	if err != nil {
+		return f.wrap(off, err)
+	}
+	if tok != want {
+		return f.errorf(off, "expected %v, found %v", want, tok)
+	}
+	return nil
+}
+
+// object walks a JSON object, calling field with the decoder positioned on
+// each value.
+func (f *dataFile) object(dec *json.Decoder, field func(dec *json.Decoder, key string, off int64) error) error {
+	if err := f.delim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		off := dec.InputOffset()
+		tok, err := dec.Token()
+		if err != nil {
+			return f.wrap(off, err)
+		}
+		if err := field(dec, tok.(string), off); err != nil {
+			return err
+		}
+	}
+	return f.delim(dec, '}')
+}
+
+// array walks a JSON array, calling elem with the offset of each element.
+func (f *dataFile) array(dec *json.Decoder, elem func(off int64) error) error {
+	if err := f.delim(dec, '['); err != nil {
+		return err
+	}
+	for dec.More() {
+		if err := elem(dec.InputOffset()); err != nil {
+			return err
+		}
+	}
+	return f.delim(dec, ']')
+}
+
+func (f *dataFile) decks(dec *json.Decoder, shop bool) ([]Deck, error) {
+	var out []Deck
+	err := f.array(dec, func(off int64) error {
+		var d Deck
+		err := f.object(dec, func(dec *json.Decoder, key string, koff int64) error {
+			switch key {
+			case "name":
+				if err := dec.Decode(&d.Name); err != nil {
+					return f.wrap(koff, err)
+				}
+			case "cards":
+				return f.array(dec, func(coff int64) error {
+					var cj cardJSON
+					if err := dec.Decode(&cj); err != nil {
+						return f.wrap(coff, err)
+					}
+					c, err := cj.card(shop)
+					if err != nil {
+						return f.errorf(coff, "%v", err)
+					}
+					for range cj.Count {
+						d.Cards = append(d.Cards, c)
+					}
+					return nil
+				})
+			default:
+				return f.errorf(koff, "unknown deck field %q (want name or cards)", key)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if d.Name == "" {
+			return f.errorf(off, "deck has no name")
+		}
+		if len(d.Cards) == 0 {
+			return f.errorf(off, "deck %q has no cards", d.Name)
+		}
+		if f.seen == nil {
+			f.seen = map[string]int64{}
+		}
+		if prev, dup := f.seen[d.Name]; dup {
+			return f.errorf(off, "deck %q is already defined on line %d", d.Name, f.line(prev))
+		}
+		f.seen[d.Name] = off
+		out = append(out, d)
+		return nil
+	})
+	return out, err
+}
+
+type cardJSON struct {
+	Type     CardType `json:"type"`
+	Strength int      `json:"strength"`
+	Magic    int      `json:"magic"`
+	Title    string   `json:"title"`
+	Text     string   `json:"text"`
+	Count    int      `json:"count"`
+	Weight   int      `json:"weight"`
+	Rarity   Rarity   `json:"rarity"`
+
+	Spell SpellKind `json:"spell"`
+	Power int       `json:"power"`
+	HP    int       `json:"hp"`
+	Heal  int       `json:"heal"`
+	Slot  EquipSlot `json:"slot"`
+
+	Follower FollowerKind `json:"follower"`
+	Status   *statusJSON  `json:"status"`
+
+	Abilities []Ability `json:"abilities"`
+
+	Effect *effectJSON  `json:"effect"`
+	Offers []effectJSON `json:"offers"`
+	Table  []rowJSON    `json:"table"`
+	Damage int          `json:"damage"`
+	Gold   int          `json:"gold"`
+	Item   *cardJSON    `json:"item"`
+	Script Script       `json:"script"`
+}
+
+type effectJSON struct {
+	Text     string      `json:"text"`
+	Gold     int         `json:"gold"`
+	Health   int         `json:"health"`
+	Strength int         `json:"strength"`
+	Magic    int         `json:"magic"`
+	Status   *statusJSON `json:"status"`
+	Move     int         `json:"move"`
+}
+
+type rowJSON struct {
+	From int `json:"from"`
+	effectJSON
+}
+
+// MaxOffers is the most deals a stranger can have; they are picked with the
+// number keys.
+const MaxOffers = 4
+
+// effect validates an effect entry. Only events blow heroes along.
+func (ej *effectJSON) effect(event bool) (Effect, error) {
+	e := Effect{Text: ej.Text, Gold: ej.Gold, Health: ej.Health, Strength: ej.Strength, Magic: ej.Magic, Move: ej.Move}
+	switch {
+	case ej.Text == "":
+		return Effect{}, errors.New("an effect needs text")
+	case ej.Strength < 0 || ej.Magic < 0 || ej.Move < 0:
+		return Effect{}, errors.New("an effect's strength, magic and move cannot be negative")
+	case ej.Move != 0 && !event:
+		return Effect{}, errors.New("only events move heroes")
+	}
+	if ej.Status != nil {
+		s, err := ej.Status.status()
+		if err != nil {
+			return Effect{}, err
+		}
+		e.Status = s
+	}
+	return e, nil
+}
+
+// encounter makes c from an event, stranger, place, trap or treasure entry.
+func (cj *cardJSON) encounter(c *Card) error {
+	if cj.Title == "" {
+		return fmt.Errorf("a %s needs a title", cj.Type)
+	}
+	if cj.Type != TrapType && (cj.Strength != 0 || cj.Magic != 0) {
+		return fmt.Errorf("a %s has no strength or magic", cj.Type)
+	}
+	switch cj.Type {
+	case EventType:
+		if cj.Effect == nil {
+			return errors.New("an event needs an effect")
+		}
+		e, err := cj.Effect.effect(true)
+		if err != nil {
+			return err
+		}
+		if e.Describe() == "nothing" {
+			return errors.New("an event must do something")
+		}
+		*c = NewEvent(cj.Title, e)
+	case StrangerType:
+		if len(cj.Offers) == 0 || len(cj.Offers) > MaxOffers {
+			return fmt.Errorf("a stranger needs 1 to %d offers", MaxOffers)
+		}
+		var offers []Effect
+		for i := range cj.Offers {
+			e, err := cj.Offers[i].effect(false)
+			if err != nil {
+				return fmt.Errorf("offer %d: %v", i+1, err)
+			}
+			offers = append(offers, e)
+		}
+		*c = NewStranger(cj.Title, offers)
+	case PlaceType:
+		if len(cj.Table) == 0 || cj.Table[0].From != 1 {
+			return errors.New("a place needs a table whose first row is from 1")
+		}
+		var table []TableEntry
+		for i := range cj.Table {
+			rj := &cj.Table[i]
+			if i > 0 && (rj.From <= cj.Table[i-1].From || rj.From > 6) {
+				return fmt.Errorf("row %d: from must rise, up to 6", i+1)
+			}
+			e, err := rj.effect(false)
+			if err != nil {
+				return fmt.Errorf("row %d: %v", i+1, err)
+			}
+			table = append(table, TableEntry{From: rj.From, Effect: e})
+		}
+		*c = NewPlace(cj.Title, table)
+	case TrapType:
+		if (cj.Strength == 0) == (cj.Magic == 0) || cj.Damage == 0 {
+			return errors.New("a trap needs strength or magic to beat, and damage")
+		}
+		stat := StatStrength
+		if cj.Magic > 0 {
+			stat = StatMagic
+		}
+		*c = NewTrap(cj.Title, stat, cj.Strength+cj.Magic, cj.Damage)
+	case TreasureType:
+		if cj.Gold == 0 && cj.Item == nil {
+			return errors.New("a treasure needs gold or an item")
+		}
+		var item *Card
+		if cj.Item != nil {
+			it, err := cj.Item.card(cj.Item.Type == ShopItemType)
+			if err != nil {
+				return fmt.Errorf("item: %v", err)
+			}
+			switch it.Type {
+			case ShopItemType, SpellType, PotionType, FollowerType:
+			default:
+				return errors.New("item: a treasure holds a shopItem, spell, potion or follower")
+			}
+			item = &it
+		}
+		*c = NewTreasure(cj.Title, cj.Gold, item)
+	}
+	return nil
+}
+
+type statusJSON struct {
+	Kind  StatusKind `json:"kind"`
+	Power int        `json:"power"`
+	Turns int        `json:"turns"`
+}
+
+// status validates a status entry.
+func (sj *statusJSON) status() (Status, error) {
+	if !slices.Contains(StatusKinds, sj.Kind) {
+		return Status{}, fmt.Errorf("unknown status %q (want one of %v)", sj.Kind, StatusKinds)
+	}
+	if sj.Turns <= 0 {
+		return Status{}, fmt.Errorf("a %s status needs turns", sj.Kind)
+	}
+	switch {
+	case sj.Kind == StatusBlessing && sj.Power != 0:
+		return Status{}, errors.New("a blessing has no power")
+	case sj.Kind != StatusBlessing && sj.Power <= 0:
+		return Status{}, fmt.Errorf("a %s status needs power", sj.Kind)
+	}
+	return Status{Kind: sj.Kind, Power: sj.Power, Turns: sj.Turns}, nil
+}
+
+// card validates a card entry and fills in its defaults.
+func (cj *cardJSON) card(shop bool) (Card, error) {
+	if cj.Strength < 0 || cj.Magic < 0 {
+		return Card{}, errors.New("strength and magic cannot be negative")
+	}
+	if cj.Count < 0 || cj.Weight < 0 || cj.Power < 0 || cj.HP < 0 || cj.Heal < 0 {
+		return Card{}, errors.New("count, weight, power, hp and heal cannot be negative")
+	}
+	if cj.Type != SpellType && cj.Spell != "" {
+		return Card{}, errors.New("only spell cards have spell")
+	}
+	if cj.Type != FollowerType && cj.Follower != "" {
+		return Card{}, errors.New("only follower cards have follower")
+	}
+	if cj.Type != SpellType && cj.Type != FollowerType && cj.Power != 0 {
+		return Card{}, errors.New("only spell and follower cards have power")
+	}
+	if cj.HP != 0 && cj.Type != MonsterType && cj.Type != MagicMonsterType && cj.Type != CrownType {
+		return Card{}, errors.New("only monsters and crown guardians have hp")
+	}
+	if cj.Type != PotionType && cj.Heal != 0 {
+		return Card{}, errors.New("only potions have heal")
+	}
+	if cj.Type != PotionType && cj.Status != nil {
+		return Card{}, errors.New("only potions have a status")
+	}
+	for i, a := range cj.Abilities {
+		switch {
+		case !slices.Contains(Abilities, a):
+			return Card{}, fmt.Errorf("unknown ability %q (want one of %v)", a, Abilities)
+		case slices.Contains(cj.Abilities[:i], a):
+			return Card{}, fmt.Errorf("ability %q is listed twice", a)
+		case cj.Type != MonsterType && cj.Type != MagicMonsterType && cj.Type != CrownType:
+			return Card{}, errors.New("only monsters and crown guardians have abilities")
+		case a == AbilityMagicOnly && cj.Type == MagicMonsterType:
+			return Card{}, errors.New("a magic monster is already fought with magic; it cannot be magicOnly")
+		}
+	}
+	if cj.Rarity != "" && !slices.Contains(Rarities, cj.Rarity) {
+		return Card{}, fmt.Errorf("unknown rarity %q (want one of %v)", cj.Rarity, Rarities)
+	}
+	if cj.Type != ShopItemType && cj.Slot != "" {
+		return Card{}, errors.New("only shop items have a slot")
+	}
+	switch {
+	case cj.Damage < 0 || cj.Gold < 0:
+		return Card{}, errors.New("damage and gold cannot be negative")
+	case cj.Type != EventType && cj.Effect != nil:
+		return Card{}, errors.New("only events have an effect")
+	case cj.Type != StrangerType && cj.Offers != nil:
+		return Card{}, errors.New("only strangers have offers")
+	case cj.Type != PlaceType && cj.Table != nil:
+		return Card{}, errors.New("only places have a table")
+	case cj.Type != TrapType && cj.Damage != 0:
+		return Card{}, errors.New("only traps have damage")
+	case cj.Type != TreasureType && (cj.Gold != 0 || cj.Item != nil):
+		return Card{}, errors.New("only treasure has gold and an item")
+	case cj.Type != ScriptedType && cj.Script != "":
+		return Card{}, errors.New("only scripted cards have a script")
+	}
+	cj.Count = max(cj.Count, 1)
+
+	var c Card
+	switch cj.Type {
+	case EventType, StrangerType, PlaceType, TrapType, TreasureType:
+		if err := cj.encounter(&c); err != nil {
+			return Card{}, err
+		}
+	case MonsterType:
+		if cj.Strength == 0 || cj.Magic != 0 {
+			return Card{}, errors.New("a monster needs strength and no magic")
+		}
+		c = NewMonsterStrength(cj.Strength)
+	case MagicMonsterType:
+		if cj.Magic == 0 || cj.Strength != 0 {
+			return Card{}, errors.New("a magic monster needs magic and no strength")
+		}
+		c = NewMonsterMagic(cj.Magic)
+	case BuffType:
+		switch {
+		case cj.Strength > 0 && cj.Magic == 0:
+			c = NewBuffStrength(cj.Strength)
+		case cj.Magic > 0 && cj.Strength == 0:
+			c = NewBuffMagic(cj.Magic)
+		default:
+			return Card{}, errors.New("a buff gives either strength or magic")
+		}
+	case ShopItemType:
+		if cj.Title == "" {
+			return Card{}, errors.New("a shop item needs a title")
+		}
+		if cj.Slot.Index() < 0 {
+			return Card{}, fmt.Errorf("a shop item needs a slot (want one of %v)", EquipSlots)
+		}
+		c = Card{Type: ShopItemType, Strength: cj.Strength, Magic: cj.Magic, Slot: cj.Slot}
+	case TalismanType:
+		if cj.Strength != 0 || cj.Magic != 0 {
+			return Card{}, errors.New("a talisman has no strength or magic")
+		}
+		c = NewTalisman()
+	case CrownType:
+		if cj.Strength == 0 || cj.Magic != 0 {
+			return Card{}, errors.New("a crown guardian needs strength and no magic")
+		}
+		c = NewCrownGuardian(cj.Strength)
+	case SpellType:
+		if cj.Strength != 0 || cj.Magic != 0 {
+			return Card{}, errors.New("a spell has power, not strength or magic")
+		}
+		switch cj.Spell {
+		case SpellBattle, SpellStride:
+			if cj.Power == 0 {
+				return Card{}, fmt.Errorf("a %s spell needs power", cj.Spell)
+			}
+		case SpellTeleport:
+			if cj.Power != 0 {
+				return Card{}, errors.New("a teleport spell has no power")
+			}
+		default:
+			return Card{}, fmt.Errorf("unknown spell %q (want one of %v)", cj.Spell, SpellKinds)
+		}
+		c = NewSpell(cj.Spell, cj.Power)
+	case PotionType:
+		if (cj.Heal == 0 && cj.Status == nil) || cj.Strength != 0 || cj.Magic != 0 {
+			return Card{}, errors.New("a potion needs heal or a status, and no strength or magic")
+		}
+		c = NewPotion(cj.Heal)
+		if cj.Status != nil {
+			s, err := cj.Status.status()
+			if err != nil {
+				return Card{}, err
+			}
+			c = NewStatusPotion(cj.Heal, s)
+		}
+	case FollowerType:
+		if cj.Strength != 0 || cj.Magic != 0 {
+			return Card{}, errors.New("a follower has power, not strength or magic")
+		}
+		if !slices.Contains(FollowerKinds, cj.Follower) {
+			return Card{}, fmt.Errorf("unknown follower %q (want one of %v)", cj.Follower, FollowerKinds)
+		}
+		if cj.Power == 0 {
+			return Card{}, fmt.Errorf("a %s follower needs power", cj.Follower)
+		}
+		c = NewFollower(cj.Follower, cj.Power)
+	case ScriptedType:
+		if cj.Title == "" || cj.Strength != 0 || cj.Magic != 0 {
+			return Card{}, errors.New("a scripted card needs a title, and no strength or magic")
+		}
+		if _, err := cj.Script.compile(); err != nil {
+			return Card{}, fmt.Errorf("script: %v", err)
+		}
+		c = Card{Type: ScriptedType, Script: cj.Script}
+	case "":
+		return Card{}, errors.New("card has no type")
+	default:
+		return Card{}, fmt.Errorf("unknown card type %q (want monster, magicMonster, buff, shopItem, talisman, crown, spell, potion, follower, event, stranger, place, trap, treasure or scripted)", cj.Type)
+	}
+	switch {
+	case shop && cj.Type != ShopItemType && cj.Type != TalismanType && cj.Type != SpellType && cj.Type != PotionType && cj.Type != FollowerType:
+		return Card{}, fmt.Errorf("shops only sell shopItem, talisman, spell, potion and follower cards, not %s", cj.Type)
+	case !shop && cj.Type == ShopItemType:
+		return Card{}, errors.New("shopItem cards belong in the shops section")
+	}
+
+	c.Abilities = cj.Abilities
+	if cj.Title != "" {
+		c.Title = cj.Title
+	}
+	if cj.Text != "" {
+		c.Text = cj.Text
+	}
+	if cj.Weight != 1 {
+		c.Weight = cj.Weight // 0 and 1 both mean the default
+	}
+	if cj.Rarity != RarityCommon {
+		c.Rarity = cj.Rarity // "" and common both mean the default
+	}
+	c.HP = cj.HP
+	return c, nil
+}
@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// testShops is a shops section with one potion for every keeper.
+func testShops() string {
+	var shops []string
+	for _, k := range ShopKeepers {
+		shops = append(shops, `{"name": "`+k+`", "cards": [{"type": "potion", "heal": 1}]}`)
+	}
+	return `"shops": [` + strings.Join(shops, ", ") + `]`
+}
+
+func TestLoadCardData(t *testing.T) {
+	tests := []struct {
+		name  string
+		decks string
+		err   string // part of the error, "" if it loads
+		check func(t *testing.T, gd gameData)
+	}{
+		{name: "decks and shops", decks: `{
+  "decks": [{"name": "woods", "cards": [
+    {"type": "monster", "strength": 3, "count": 2},
+    {"type": "buff", "magic": 1, "title": "Mushroom", "weight": 3}
+  ]}],
+  ` + testShops() + `
+}`, check: func(t *testing.T, gd gameData) {
+			d := gd.Decks["woods"]
+			if len(d.Cards) != 3 || d.Cards[2].Title != "Mushroom" || d.Cards[2].Weight != 3 {
+				t.Errorf("woods holds %+v", d.Cards)
+			}
+			if len(gd.Shops) != len(ShopKeepers) || gd.Shops[1].Name != "ogre" {
+				t.Errorf("shops %+v, want one per keeper in order", gd.Shops)
+			}
+		}},
+		{name: "unknown section", decks: `{"cards": []}`, err: `test/decks.json:1: unknown section "cards"`},
+		{name: "syntax", decks: "{\n  \"decks\": [\n    {\"name\": \"woods\",,}\n  ]\n}", err: "test/decks.json:3: invalid character"},
+		{name: "no name", decks: "{\"decks\": [\n  {\"cards\": [{\"type\": \"buff\", \"strength\": 1}]}\n]}", err: "test/decks.json:2: deck has no name"},
+		{name: "no cards", decks: `{"decks": [{"name": "woods", "cards": []}]}`, err: `deck "woods" has no cards`},
+		{name: "twice", decks: "{\"decks\": [\n  {\"name\": \"a\", \"cards\": [{\"type\": \"buff\", \"strength\": 1}]},\n  {\"name\": \"a\", \"cards\": [{\"type\": \"buff\", \"strength\": 1}]}\n]}",
+			err: `test/decks.json:3: deck "a" is already defined on line 2`},
+		{name: "bad card", decks: "{\"decks\": [{\"name\": \"woods\", \"cards\": [\n  {\"type\": \"monster\", \"magic\": 3}\n]}]}",
+			err: "test/decks.json:2: a monster needs strength and no magic"},
+		{name: "unknown field", decks: `{"decks": [{"name": "woods", "cards": [{"type": "buff", "strength": 1, "luck": 2}]}]}`,
+			err: `unknown field "luck"`},
+		{name: "gear outside a shop", decks: `{"decks": [{"name": "woods", "cards": [{"type": "shopItem", "strength": 1, "title": "Club", "slot": "weapon"}]}]}`,
+			err: "shopItem cards belong in the shops section"},
+		{name: "monster in a shop", decks: `{"shops": [{"name": "mystic", "cards": [{"type": "monster", "strength": 1}]}]}`,
+			err: "shops only sell"},
+		{name: "missing keeper", decks: `{"decks": [{"name": "woods", "cards": [{"type": "buff", "strength": 1}]}]}`,
+			err: "no shop deck for the mystic shopkeeper"},
+		{name: "spell deck with a monster", decks: `{"decks": [{"name": "spells", "cards": [{"type": "monster", "strength": 1}]}], ` + testShops() + `}`,
+			err: `the "spells" deck may only hold spell cards`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gd, err := loadCardData(fstest.MapFS{DecksFile: {Data: []byte(tt.decks)}}, "test")
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got %v, want an error about %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, gd)
+		})
+	}
+}
+
+func TestBuiltinData(t *testing.T) {
+	if len(content.Shops) != len(ShopKeepers) || len(content.Regions) == 0 {
+		t.Fatalf("%d shops and %d regions built in", len(content.Shops), len(content.Regions))
+	}
+	for _, rg := range content.Regions {
+		if _, ok := content.Decks[rg.Deck]; !ok {
+			t.Errorf("region %s deals from missing deck %q", rg.Name, rg.Deck)
+		}
+	}
+}
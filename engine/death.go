@@ -0,0 +1,96 @@
+package engine
+
+import "fmt"
+
+// DeathRule is what happens to a hero whose health runs out.
+type DeathRule string
+
+const (
+	// Respawn sends the hero back to the first tile with full health, half
+	// its gold, half its trophies and none of the items it wore or the
+	// followers it led.
+	Respawn DeathRule = "respawn"
+	// Permadeath takes the hero out of the game. The run is over when
+	// nobody is left.
+	Permadeath DeathRule = "permadeath"
+)
+
+// ParseDeathRule accepts the names used on the command line.
+func ParseDeathRule(s string) (DeathRule, error) {
+	switch DeathRule(s) {
+	case Respawn, Permadeath:
+		return DeathRule(s), nil
+	}
+	return "", fmt.Errorf("unknown death rule %q (want respawn or permadeath)", s)
+}
+
+// StartHealth is what heroes start, and respawn, with.
+const StartHealth = 5
+
+// Over reports whether the run has ended: a hero has claimed The Crown, or
+// every hero has fallen for good.
+func (g *Game) Over() bool {
+	if g.Winner() != nil {
+		return true
+	}
+	if g.DeathRule != Permadeath {
+		return false
+	}
+	for _, p := range g.Players {
+		if !p.Dead {
+			return false
+		}
+	}
+	return true
+}
+
+// died records a hero's death on its run stats.
+func (g *Game) died(card Card) {
+	p := g.Current()
+	p.Stats.Deaths++
+	p.Stats.KilledBy = card.Title
+	p.Stats.KilledIn = g.World.Loops[p.At.Loop].Type.Name
+	g.Logf("You have fallen in %s!", p.Stats.KilledIn)
+}
+
+// RespawnCost is the gold and the number of trophies a respawn takes.
+func (p *Player) RespawnCost() (gold, trophies int) {
+	for _, c := range p.Cards {
+		if isTrophy(c) {
+			trophies++
+		}
+	}
+	return p.Gold / 2, trophies / 2
+}
+
+func isTrophy(c Card) bool { return c.Type == MonsterType || c.Type == MagicMonsterType }
+
+// respawn brings the fallen hero back on the first tile and passes the dice.
+func (g *Game) respawn() {
+	p := g.Current()
+	gold, trophies := p.RespawnCost()
+	p.Gold -= gold
+	// the most recent trophies are the ones lost
+	drop := map[int]bool{}
+	for i := len(p.Cards) - 1; i >= 0 && len(drop) < trophies; i-- {
+		if isTrophy(p.Cards[i]) {
+			drop[i] = true
+		}
+	}
+	kept := make([]Card, 0, len(p.Cards)-len(drop))
+	for i, c := range p.Cards {
+		if !drop[i] {
+			kept = append(kept, c)
+		}
+	}
+	p.Cards = kept
+	gear := p.dropGear() // left where the hero fell
+	followers := len(p.Followers)
+	p.Followers = nil // scattered
+	p.Statuses = nil
+	p.Health = StartHealth
+	p.Dead = false
+	p.At = TileID{0, 0}
+	g.Logf("You rise again at the start, losing %d gold, %d trophies, %d worn items and %d followers", gold, len(drop), gear, followers)
+	g.endTurn()
+}
@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRespawn(t *testing.T) {
+	g := NewGame(1, 2)
+	p := g.Current()
+	p.Gold = 11
+	p.Cards = []Card{NewMonsterStrength(2), NewPotion(1), NewMonsterMagic(3), NewMonsterStrength(4)}
+	p.Equipped[Weapon.Index()] = Card{Type: ShopItemType, Title: "Sword", Strength: 2, Slot: Weapon}
+	p.Followers = []Card{NewFollower(FollowerFighter, 1)}
+	p.Statuses = []Status{{StatusPoison, 1, 2}}
+	p.At = TileID{0, 3}
+	p.Health = 0
+	p.Dead = true
+	p.Stats.Deaths = 1
+
+	if err := g.Apply(Action{Kind: ActRespawn}); err != nil {
+		t.Fatal(err)
+	}
+	if p.Dead || p.Health != StartHealth || p.At != (TileID{0, 0}) || p.Gold != 6 {
+		t.Errorf("dead %v, health %d, at %v, gold %d; want alive on the start with 5 health and 6 gold", p.Dead, p.Health, p.At, p.Gold)
+	}
+	// the newest trophy is the one lost
+	if len(p.Cards) != 3 || p.Cards[2].Magic != 3 {
+		t.Errorf("kept %v", p.Cards)
+	}
+	if _, worn := p.Worn(Weapon); worn || p.Followers != nil || p.Statuses != nil {
+		t.Error("the hero kept its gear, followers or statuses")
+	}
+	if g.Active != 1 {
+		t.Errorf("hero %d has the dice, want 1", g.Active)
+	}
+	want := "Hero 1: You rise again at the start, losing 5 gold, 1 trophies, 1 worn items and 1 followers"
+	if !strings.Contains(strings.Join(g.Log, "\n"), want) {
+		t.Errorf("log %q, want %q", g.Log, want)
+	}
+}
+
+func TestDeathRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   DeathRule
+		a      Action
+		ok     bool
+		active int // who has the dice afterwards
+	}{
+		{name: "respawn", rule: Respawn, a: Action{Kind: ActRespawn}, ok: true, active: 1},
+		{name: "no passing before respawning", rule: Respawn, a: Action{Kind: ActEndTurn}},
+		{name: "no rolling while fallen", rule: Respawn, a: Action{Kind: ActRoll}},
+		{name: "pass the dice", rule: Permadeath, a: Action{Kind: ActEndTurn}, ok: true, active: 1},
+		{name: "no respawn in permadeath", rule: Permadeath, a: Action{Kind: ActRespawn}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGame(1, 3)
+			g.DeathRule = tt.rule
+			g.Moved = true
+			g.Current().Dead = true
+			err := g.Apply(tt.a)
+			if !tt.ok {
+				if !errors.Is(err, ErrIllegalAction) || g.Active != 0 {
+					t.Fatalf("got %v with hero %d active, want an illegal action", err, g.Active)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if g.Active != tt.active {
+				t.Errorf("hero %d has the dice, want %d", g.Active, tt.active)
+			}
+		})
+	}
+}
+
+func TestPermadeathOver(t *testing.T) {
+	g := NewGame(1, 3)
+	g.DeathRule = Permadeath
+	g.Players[1].Dead = true
+	g.endTurn()
+	if g.Active != 2 {
+		t.Fatalf("hero %d has the dice, want the fallen hero skipped", g.Active)
+	}
+	if g.Over() {
+		t.Fatal("the run ended with heroes standing")
+	}
+	g.Players[0].Dead = true
+	g.Players[2].Dead = true
+	if !g.Over() {
+		t.Fatal("the run goes on with every hero fallen")
+	}
+	g.DeathRule = Respawn
+	if g.Over() {
+		t.Fatal("the run ended with heroes able to respawn")
+	}
+}
@@ -0,0 +1,255 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Beside monsters and gifts the decks deal encounters that play out in a
+// modal of their own: events that befall every hero at once, strangers who
+// offer a choice of deals, places where a die picks what happens, traps the
+// hero must test a stat against, and treasure.
+const (
+	EventType    CardType = "event"    // Effect happens to every hero; cannot be walked away from
+	StrangerType CardType = "stranger" // the hero may take one of the Offers
+	PlaceType    CardType = "place"    // a d6 picks a row of the Table
+	TrapType     CardType = "trap"     // Strength or Magic + d6 must reach the card's; else lose Damage health
+	TreasureType CardType = "treasure" // Gold and perhaps a Reward item
+)
+
+// Effect is what an event, a stranger's offer or a place's outcome does to
+// a hero. Gold and Health may be negative; Strength and Magic are gifts.
+type Effect struct {
+	Text     string // what happens, in a few words
+	Gold     int    `json:",omitempty"`
+	Health   int    `json:",omitempty"`
+	Strength int    `json:",omitempty"`
+	Magic    int    `json:",omitempty"`
+	Status   Status `json:",omitzero"`
+	Move     int    `json:",omitempty"` // tiles the hero is blown along its loop
+}
+
+// TableEntry is one row of a place's dice table: it comes up on rolls from
+// From to the next row's From.
+type TableEntry struct {
+	From int
+	Effect
+}
+
+// Describe is the effect in short, like "+3 gold, -1 health", for the card
+// modal and messages.
+func (e Effect) Describe() string {
+	var parts []string
+	if e.Gold != 0 {
+		parts = append(parts, fmt.Sprintf("%+d gold", e.Gold))
+	}
+	if e.Health != 0 {
+		parts = append(parts, fmt.Sprintf("%+d health", e.Health))
+	}
+	if e.Strength != 0 {
+		parts = append(parts, fmt.Sprintf("%+d Strength", e.Strength))
+	}
+	if e.Magic != 0 {
+		parts = append(parts, fmt.Sprintf("%+d Magic", e.Magic))
+	}
+	if e.Status.Kind != "" {
+		parts = append(parts, fmt.Sprintf("%s for %d turns", e.Status.Kind, e.Status.Turns))
+	}
+	if e.Move != 0 {
+		parts = append(parts, fmt.Sprintf("blown %d tiles on", e.Move))
+	}
+	if len(parts) == 0 {
+		return "nothing"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Unavoidable reports whether the hero must face c rather than walk away.
+func (c Card) Unavoidable() bool { return c.Type == EventType || c.Type == TrapType }
+
+// CheckStat is the stat a trap tests.
+func (c Card) CheckStat() Stat {
+	if c.Magic > 0 {
+		return StatMagic
+	}
+	return StatStrength
+}
+
+// Difficulty is the total the hero's stat and d6 must reach to get past a
+// trap.
+func (c Card) Difficulty() int { return c.Strength + c.Magic }
+
+// RowFor is the row of the place's table a roll of die picks.
+func (c Card) RowFor(die int) TableEntry {
+	row := c.Table[0]
+	for _, e := range c.Table[1:] {
+		if e.From <= die {
+			row = e
+		}
+	}
+	return row
+}
+
+// CannotTake is why the hero cannot accept an offer, or "" if it can. A
+// stranger takes neither gold the hero lacks nor its last point of health.
+func (p *Player) CannotTake(e Effect) string {
+	switch {
+	case p.Gold+e.Gold < 0:
+		return fmt.Sprintf("you need %d gold", -e.Gold)
+	case e.Health < 0 && p.Health+e.Health <= 0:
+		return "it would be the death of you"
+	}
+	return ""
+}
+
+// applyEffect makes e happen to p and returns what it did. Only the hero
+// whose turn it is can die of it, to source; anyone else is left on 1
+// health.
+func (g *Game) applyEffect(p *Player, e Effect, source Card) string {
+	p.Gold = max(p.Gold+e.Gold, 0)
+	if e.Gold > 0 {
+		p.Stats.GoldEarned += e.Gold
+	}
+	if e.Health > 0 {
+		p.Health += min(e.Health, max(StartHealth-p.Health, 0))
+	} else if e.Health < 0 {
+		p.Health = max(p.Health+e.Health, 0)
+	}
+	p.Strength = max(p.Strength+e.Strength, 0)
+	p.Magic = max(p.Magic+e.Magic, 0)
+	if e.Status.Kind != "" {
+		p.afflict(e.Status)
+	}
+	for range e.Move {
+		p.At = g.World.Loops[p.At.Loop].Tiles[p.At.Index].Next
+	}
+	if p.Health == 0 {
+		if p != g.Current() {
+			p.Health = 1
+		} else {
+			p.Dead = true
+			g.died(source)
+		}
+	}
+	return e.Describe()
+}
+
+// befall plays the open event on every hero still standing.
+func (g *Game) befall() string {
+	g.setAside()
+	e := g.Card.Effect
+	g.Logf("%s: %s for everyone", g.Card.Title, e.Describe())
+	for i := range g.Players {
+		if p := &g.Players[i]; !p.Dead {
+			g.applyEffect(p, e, g.Card)
+		}
+	}
+	msg := fmt.Sprintf("%s. Every hero: %s.", e.Text, e.Describe())
+	if g.Current().Dead {
+		msg += " You have fallen."
+	}
+	return msg
+}
+
+// takeOffer accepts the stranger's offer i.
+func (g *Game) takeOffer(i int) error {
+	p := g.Current()
+	if i < 0 || i >= len(g.Card.Offers) {
+		return fmt.Errorf("no offer %d: %w", i, ErrIllegalAction)
+	}
+	e := g.Card.Offers[i]
+	if why := p.CannotTake(e); why != "" {
+		return fmt.Errorf("%s: %w", why, ErrIllegalAction)
+	}
+	g.setAside()
+	got := g.applyEffect(p, e, g.Card)
+	g.Logf("You accept %s's offer: %s", g.Card.Title, got)
+	g.CardMsg = fmt.Sprintf("%s. (%s)", e.Text, got)
+	g.CardResolved = true
+	return nil
+}
+
+// visitPlace rolls on the open place's table.
+func (g *Game) visitPlace() string {
+	p := g.Current()
+	die := g.Rng.Intn(6) + 1
+	row := g.Card.RowFor(die)
+	g.setAside()
+	got := g.applyEffect(p, row.Effect, g.Card)
+	g.Logf("%s: you roll %d, %s", g.Card.Title, die, got)
+	msg := fmt.Sprintf("You roll %d: %s. (%s)", die, row.Text, got)
+	if p.Dead {
+		msg += " You have fallen."
+	}
+	return msg
+}
+
+// springTrap tests the hero's stat against the open trap.
+func (g *Game) springTrap() string {
+	p, c := g.Current(), g.Card
+	die := g.Rng.Intn(6) + 1
+	stat := c.CheckStat()
+	total := p.Stat(stat) + die
+	g.setAside()
+	if total >= c.Difficulty() {
+		g.Logf("%s: %s %d + %d reaches %d", c.Title, stat, p.Stat(stat), die, c.Difficulty())
+		return fmt.Sprintf("You roll %d: %s %d gets you past it unharmed.", die, stat, total)
+	}
+	g.applyEffect(p, Effect{Health: -c.Damage}, c)
+	g.Logf("%s: %s %d + %d falls short of %d; you lose %d health", c.Title, stat, p.Stat(stat), die, c.Difficulty(), c.Damage)
+	msg := fmt.Sprintf("You roll %d: %s %d falls short of %d. You lose %d health.", die, stat, total, c.Difficulty(), c.Damage)
+	if p.Dead {
+		msg += " You have fallen."
+	}
+	return msg
+}
+
+// ---------- Encounter factories ----------
+
+func NewEvent(title string, e Effect) Card {
+	return Card{
+		Type:   EventType,
+		Effect: e,
+		Title:  title,
+		Text:   e.Text + ".",
+	}
+}
+
+func NewStranger(title string, offers []Effect) Card {
+	return Card{
+		Type:   StrangerType,
+		Offers: offers,
+		Title:  title,
+		Text:   "Someone by the road has a deal for you.",
+	}
+}
+
+func NewPlace(title string, table []TableEntry) Card {
+	return Card{
+		Type:  PlaceType,
+		Table: table,
+		Title: title,
+		Text:  "Roll the die and see what comes of it.",
+	}
+}
+
+func NewTrap(title string, stat Stat, difficulty, damage int) Card {
+	c := Card{Type: TrapType, Title: title, Damage: damage}
+	if stat == StatMagic {
+		c.Magic = difficulty
+	} else {
+		c.Strength = difficulty
+	}
+	c.Text = fmt.Sprintf("Test your %s: reach %d or lose %d health.", stat.Name(), difficulty, damage)
+	return c
+}
+
+func NewTreasure(title string, gold int, item *Card) Card {
+	return Card{
+		Type:   TreasureType,
+		Gold:   gold,
+		Reward: item,
+		Title:  title,
+		Text:   "Something glints by the road.",
+	}
+}
@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestEvent(t *testing.T) {
+	g := NewGame(1, 3)
+	g.Players[1].Health = 1
+	g.Players[2].Dead = true
+	meet(g, NewEvent("Storm", Effect{Text: "Hail falls", Gold: 2, Health: -1}))
+	if err := g.Apply(Action{Kind: ActSkipCard}); !errors.Is(err, ErrIllegalAction) {
+		t.Fatalf("walking away from an event: got %v", err)
+	}
+	if err := g.Apply(Action{Kind: ActConfirmCard}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		gold, health int
+	}{
+		{12, StartHealth - 1},
+		{12, 1}, // only the hero whose turn it is can die of it
+		{10, StartHealth},
+	}
+	for i, tt := range tests {
+		if p := g.Players[i]; p.Gold != tt.gold || p.Health != tt.health || p.Dead != (i == 2) {
+			t.Errorf("%s: gold %d, health %d, dead %v; want %d, %d", p.Name, p.Gold, p.Health, p.Dead, tt.gold, tt.health)
+		}
+	}
+}
+
+func TestStrangerOffers(t *testing.T) {
+	stranger := NewStranger("Pedlar", []Effect{
+		{Text: "Buy a charm", Gold: -20, Magic: 1},
+		{Text: "Give blood", Health: -2, Strength: 1},
+		{Text: "Sell a trinket", Gold: 4, Health: -1},
+	})
+	tests := []struct {
+		name   string
+		health int
+		a      Action
+		ok     bool
+	}{
+		{name: "settle without choosing", health: StartHealth, a: Action{Kind: ActConfirmCard}},
+		{name: "no such offer", health: StartHealth, a: Action{Kind: ActChoose, Slot: 3}},
+		{name: "too poor", health: StartHealth, a: Action{Kind: ActChoose, Slot: 0}},
+		{name: "too weak", health: 2, a: Action{Kind: ActChoose, Slot: 1}},
+		{name: "take it", health: 2, a: Action{Kind: ActChoose, Slot: 2}, ok: true},
+		{name: "walk away", health: StartHealth, a: Action{Kind: ActSkipCard}, ok: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGame(1, 1)
+			p := g.Current()
+			p.Health = tt.health
+			meet(g, stranger)
+			err := g.Apply(tt.a)
+			if !tt.ok {
+				if !errors.Is(err, ErrIllegalAction) || p.Gold != 10 || p.Health != tt.health || g.CardResolved {
+					t.Fatalf("got %v with gold %d and health %d, want an illegal action", err, p.Gold, p.Health)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.a.Kind == ActChoose && (p.Gold != 14 || p.Health != 1 || !g.CardResolved) {
+				t.Errorf("gold %d, health %d, resolved %v; want the trinket sold", p.Gold, p.Health, g.CardResolved)
+			}
+		})
+	}
+}
+
+func TestPlace(t *testing.T) {
+	shrine := NewPlace("Shrine", []TableEntry{
+		{From: 1, Effect: Effect{Text: "Dust", Gold: 1}},
+		{From: 3, Effect: Effect{Text: "Coins", Gold: 3}},
+		{From: 6, Effect: Effect{Text: "A hoard", Gold: 6}},
+	})
+	for die, want := range []int{0, 1, 1, 3, 3, 3, 6} {
+		if die > 0 && shrine.RowFor(die).Gold != want {
+			t.Errorf("d6 %d picks %d gold, want %d", die, shrine.RowFor(die).Gold, want)
+		}
+	}
+	for seed := int64(1); seed <= 10; seed++ {
+		g := NewGame(seed, 1)
+		meet(g, shrine)
+		if err := g.Apply(Action{Kind: ActConfirmCard}); err != nil {
+			t.Fatal(err)
+		}
+		var die int
+		if _, err := fmt.Sscanf(g.CardMsg, "You roll %d", &die); err != nil {
+			t.Fatalf("seed %d: message %q: %v", seed, g.CardMsg, err)
+		}
+		if got := g.Current().Gold - 10; got != shrine.RowFor(die).Gold {
+			t.Errorf("seed %d: rolled %d and got %d gold", seed, die, got)
+		}
+	}
+}
+
+func TestTrap(t *testing.T) {
+	tests := []struct {
+		name   string
+		trap   Card
+		health int
+		dead   bool
+	}{
+		{name: "easy", trap: NewTrap("Log", StatStrength, 4, 2), health: StartHealth},
+		{name: "hard", trap: NewTrap("Pit", StatStrength, 20, 2), health: StartHealth - 2},
+		{name: "by magic", trap: NewTrap("Glyph", StatMagic, 20, 1), health: StartHealth - 1},
+		{name: "deadly", trap: NewTrap("Blade", StatMagic, 20, StartHealth), dead: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGame(1, 1)
+			p := g.Current()
+			p.Magic = 0
+			meet(g, tt.trap)
+			if err := g.Apply(Action{Kind: ActSkipCard}); !errors.Is(err, ErrIllegalAction) {
+				t.Fatalf("walking away from a trap: got %v", err)
+			}
+			if err := g.Apply(Action{Kind: ActConfirmCard}); err != nil {
+				t.Fatal(err)
+			}
+			if p.Health != tt.health || p.Dead != tt.dead {
+				t.Errorf("health %d, dead %v; want %d, %v", p.Health, p.Dead, tt.health, tt.dead)
+			}
+			if tt.dead && p.Stats.KilledBy != tt.trap.Title {
+				t.Errorf("killed by %q, want %q", p.Stats.KilledBy, tt.trap.Title)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+)
+
+// EquipSlot is where on the hero a shop item is worn. Each slot holds one
+// item, and an item's Strength and Magic only count while it is worn.
+type EquipSlot string
+
+const (
+	Weapon  EquipSlot = "weapon"
+	Armour  EquipSlot = "armour"
+	Helm    EquipSlot = "helm"
+	Trinket EquipSlot = "trinket"
+)
+
+// EquipSlots is every slot in Player.Equipped order.
+var EquipSlots = [...]EquipSlot{Weapon, Armour, Helm, Trinket}
+
+// Index is the slot's place in Player.Equipped, or -1 for an unknown slot.
+func (s EquipSlot) Index() int {
+	for i, e := range EquipSlots {
+		if e == s {
+			return i
+		}
+	}
+	return -1
+}
+
+const (
+	CrushMargin = 5 // how far a monster must out-roll the hero to break its armour, or its helm if it wears none
+	FumbleDie   = 1 // a hero hit in an attack it rolled this on breaks its weapon
+)
+
+// GearBonus is what the hero's worn items add to s.
+func (p *Player) GearBonus(s Stat) int {
+	total := 0
+	for _, c := range p.Equipped {
+		switch s {
+		case StatStrength:
+			total += c.Strength
+		case StatMagic:
+			total += c.Magic
+		case StatBoth:
+			total += c.Strength + c.Magic
+		}
+	}
+	return total
+}
+
+// Worn is the item in slot s, if any.
+func (p *Player) Worn(s EquipSlot) (Card, bool) {
+	c := p.Equipped[s.Index()]
+	return c, c.Type != ""
+}
+
+// equip moves the item in inventory slot from the pack onto the hero. Any
+// item already in its equipment slot goes back into the pack.
+func (g *Game) equip(slot int) error {
+	p := g.Current()
+	if slot < 0 || slot >= len(p.Cards) || p.Cards[slot].Type != ShopItemType {
+		return fmt.Errorf("nothing to equip in slot %d: %w", slot, ErrIllegalAction)
+	}
+	c := p.Cards[slot]
+	p.Cards = slices.Delete(p.Cards, slot, slot+1)
+	if old, ok := p.Worn(c.Slot); ok {
+		p.Cards = append(p.Cards, old)
+		g.Logf("You swap %s for %s", old.Title, c.Title)
+	} else {
+		g.Logf("You equip %s (%s)", c.Title, c.Slot)
+	}
+	p.Equipped[c.Slot.Index()] = c
+	return nil
+}
+
+// unequip takes off the item worn in EquipSlots[i] and puts it in the pack.
+func (g *Game) unequip(i int) error {
+	p := g.Current()
+	if i < 0 || i >= len(EquipSlots) || p.Equipped[i].Type == "" {
+		return fmt.Errorf("nothing worn in slot %d: %w", i, ErrIllegalAction)
+	}
+	if p.PackRoom() <= 0 {
+		return fmt.Errorf("pack is full: %w", ErrIllegalAction)
+	}
+	c := p.Equipped[i]
+	p.Equipped[i] = Card{}
+	p.Cards = append(p.Cards, c)
+	g.Logf("You take off %s", c.Title)
+	return nil
+}
+
+// breakGear destroys the item worn in slot s and returns its title, or ""
+// when nothing was worn there.
+func (p *Player) breakGear(s EquipSlot) string {
+	c, ok := p.Worn(s)
+	if !ok {
+		return ""
+	}
+	p.Equipped[s.Index()] = Card{}
+	return c.Title
+}
+
+// roundBreaks is what a hit in round r breaks: the weapon on a fumbled
+// attack, otherwise the armour or helm on a crushing blow.
+func (p *Player) roundBreaks(r BattleRound) string {
+	if r.Taken == 0 {
+		return ""
+	}
+	if r.Move == MoveAttack && r.YourDie == FumbleDie {
+		if t := p.breakGear(Weapon); t != "" {
+			return t
+		}
+	}
+	if r.MonTot-r.YourTot >= CrushMargin {
+		if t := p.breakGear(Armour); t != "" {
+			return t
+		}
+		return p.breakGear(Helm)
+	}
+	return ""
+}
+
+// dropGear strips everything the hero wears and returns how many items were
+// lost.
+func (p *Player) dropGear() int {
+	n := 0
+	for i, c := range p.Equipped {
+		if c.Type != "" {
+			n++
+		}
+		p.Equipped[i] = Card{}
+	}
+	return n
+}
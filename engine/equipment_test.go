@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEquip(t *testing.T) {
+	club := Card{Type: ShopItemType, Title: "Club", Strength: 1, Slot: Weapon}
+	sword := Card{Type: ShopItemType, Title: "Sword", Strength: 2, Slot: Weapon}
+	g := NewGame(1, 1)
+	p := g.Current()
+	p.Cards = []Card{club, NewPotion(1), sword}
+
+	if err := g.Apply(Action{Kind: ActEquip, Slot: 1}); !errors.Is(err, ErrIllegalAction) {
+		t.Fatalf("equipping a potion: got %v", err)
+	}
+	if err := g.Apply(Action{Kind: ActEquip, Slot: 0}); err != nil {
+		t.Fatal(err)
+	}
+	if p.Stat(StatStrength) != 4 || len(p.Cards) != 2 {
+		t.Fatalf("strength %d with %d cards packed, want 4 with the club worn", p.Stat(StatStrength), len(p.Cards))
+	}
+	if err := g.Apply(Action{Kind: ActEquip, Slot: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := p.Worn(Weapon); c.Title != "Sword" || p.Stat(StatStrength) != 5 || p.Cards[1].Title != "Club" {
+		t.Fatalf("wielding %q with %v packed, want the sword with the club back in the pack", c.Title, titles(p.Cards))
+	}
+
+	g.CardActive = true
+	if err := g.Apply(Action{Kind: ActUnequip, Slot: Weapon.Index()}); !errors.Is(err, ErrIllegalAction) {
+		t.Fatalf("changing gear with a card out: got %v", err)
+	}
+	g.CardActive = false
+	for p.PackRoom() > 0 {
+		p.Cards = append(p.Cards, NewPotion(1))
+	}
+	if err := g.Apply(Action{Kind: ActUnequip, Slot: Weapon.Index()}); !errors.Is(err, ErrIllegalAction) {
+		t.Fatalf("taking off into a full pack: got %v", err)
+	}
+	p.Cards = p.Cards[:1]
+	if err := g.Apply(Action{Kind: ActUnequip, Slot: Weapon.Index()}); err != nil {
+		t.Fatal(err)
+	}
+	if _, worn := p.Worn(Weapon); worn || p.Stat(StatStrength) != 3 || len(p.Cards) != 2 {
+		t.Errorf("still armed, or the sword missed the pack: strength %d, pack %v", p.Stat(StatStrength), titles(p.Cards))
+	}
+	if err := g.Apply(Action{Kind: ActUnequip, Slot: Weapon.Index()}); !errors.Is(err, ErrIllegalAction) {
+		t.Errorf("taking off nothing: got %v", err)
+	}
+}
+
+func TestRoundBreaks(t *testing.T) {
+	tests := []struct {
+		name  string
+		r     BattleRound
+		wears []EquipSlot
+		want  string
+	}{
+		{name: "unhurt", r: BattleRound{Move: MoveAttack, YourDie: FumbleDie, MonTot: 20}, wears: EquipSlots[:]},
+		{name: "fumble", r: BattleRound{Move: MoveAttack, YourDie: FumbleDie, YourTot: 4, MonTot: 5, Taken: 1}, wears: EquipSlots[:], want: "weapon"},
+		{name: "no fumble evading", r: BattleRound{Move: MoveEvade, YourDie: FumbleDie, YourTot: 4, MonTot: 7, Taken: 1}, wears: EquipSlots[:]},
+		{name: "crushed armour", r: BattleRound{Move: MoveAttack, YourDie: 3, YourTot: 4, MonTot: 9, Taken: 1}, wears: EquipSlots[:], want: "armour"},
+		{name: "crushed helm", r: BattleRound{Move: MoveAttack, YourDie: 3, YourTot: 4, MonTot: 9, Taken: 1}, wears: []EquipSlot{Weapon, Helm}, want: "helm"},
+		{name: "fumble unarmed", r: BattleRound{Move: MoveAttack, YourDie: FumbleDie, YourTot: 4, MonTot: 9, Taken: 1}, wears: []EquipSlot{Armour}, want: "armour"},
+		{name: "glancing blow", r: BattleRound{Move: MoveAttack, YourDie: 3, YourTot: 4, MonTot: 8, Taken: 1}, wears: EquipSlots[:]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Player
+			for _, s := range tt.wears {
+				p.Equipped[s.Index()] = Card{Type: ShopItemType, Title: string(s), Slot: s}
+			}
+			if got := p.roundBreaks(tt.r); got != tt.want {
+				t.Fatalf("broke %q, want %q", got, tt.want)
+			}
+			if tt.want == "" {
+				return
+			}
+			if _, worn := p.Worn(EquipSlot(tt.want)); worn {
+				t.Errorf("the broken %s is still worn", tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+)
+
+// FollowerKind is what a follower does for the hero it travels with.
+type FollowerKind string
+
+const (
+	FollowerFighter FollowerKind = "fighter" // adds Power to the hero's fight rolls
+	FollowerPorter  FollowerKind = "porter"  // carries Power more items in the pack
+	FollowerGuide   FollowerKind = "guide"   // lets the hero reroll the movement die Power times a turn
+)
+
+var FollowerKinds = []FollowerKind{FollowerFighter, FollowerPorter, FollowerGuide}
+
+// Follower is what a follower card does while it travels with the hero.
+// Followers join from encounter decks or are hired in shops, and are lost
+// to a monster's natural six or when the hero falls.
+type Follower struct {
+	Kind  FollowerKind
+	Power int
+}
+
+// Effect describes what the follower does, for the UI and the log.
+func (f Follower) Effect() string {
+	switch f.Kind {
+	case FollowerFighter:
+		return fmt.Sprintf("+%d to your fight rolls", f.Power)
+	case FollowerPorter:
+		return fmt.Sprintf("+%d pack room", f.Power)
+	case FollowerGuide:
+		return fmt.Sprintf("%d movement reroll(s) a turn", f.Power)
+	}
+	return ""
+}
+
+const (
+	MaxFollowers = 3 // how many followers a hero can lead at once
+	PackSize     = 4 // items a hero carries unworn without porters
+	LethalDie    = 6 // a hero wounded by a monster that rolled this loses a follower
+)
+
+// followerPrice is the base cost of hiring a follower in the shops.
+func followerPrice(f Follower) int {
+	if f.Kind == FollowerPorter {
+		return f.Power * 4
+	}
+	return f.Power * 8
+}
+
+// followerPower sums the Power of the hero's followers of kind.
+func (p *Player) followerPower(kind FollowerKind) int {
+	total := 0
+	for _, c := range p.Followers {
+		if c.Follower.Kind == kind {
+			total += c.Follower.Power
+		}
+	}
+	return total
+}
+
+// FollowerBonus is what the hero's fighters add to its fight rolls.
+func (p *Player) FollowerBonus() int { return p.followerPower(FollowerFighter) }
+
+// Rerolls is how many times a turn the hero may reroll the movement die.
+func (p *Player) Rerolls() int { return p.followerPower(FollowerGuide) }
+
+// PackCapacity is how many unworn items the hero can carry.
+func (p *Player) PackCapacity() int { return PackSize + p.followerPower(FollowerPorter) }
+
+// PackItems counts the unworn equipment and potions in the hero's pack.
+// Trophies and blessings weigh nothing.
+func (p *Player) PackItems() int {
+	n := 0
+	for _, c := range p.Cards {
+		if c.Type == ShopItemType || c.Type == PotionType {
+			n++
+		}
+	}
+	return n
+}
+
+// PackRoom is how many more items fit in the pack. It can go below zero
+// when a porter is lost.
+func (p *Player) PackRoom() int { return p.PackCapacity() - p.PackItems() }
+
+// Fits reports why the hero cannot take c, or "" if it can.
+func (p *Player) Fits(c Card) string {
+	switch c.Type {
+	case ShopItemType:
+		if _, worn := p.Worn(c.Slot); worn && p.PackRoom() <= 0 {
+			return "your pack is full"
+		}
+	case PotionType:
+		if p.PackRoom() <= 0 {
+			return "your pack is full"
+		}
+	case SpellType:
+		if len(p.Spells) >= p.SpellSlots() {
+			return "every spell slot is taken"
+		}
+	case FollowerType:
+		if len(p.Followers) >= MaxFollowers {
+			return fmt.Sprintf("you already lead %d followers", MaxFollowers)
+		}
+	}
+	return ""
+}
+
+// loseFollower takes the hero's newest follower away and returns its title,
+// or "" when the hero has none.
+func (p *Player) loseFollower() string {
+	if len(p.Followers) == 0 {
+		return ""
+	}
+	c := p.Followers[len(p.Followers)-1]
+	p.Followers = p.Followers[:len(p.Followers)-1]
+	return c.Title
+}
+
+// dismiss sends away the follower in slot.
+func (g *Game) dismiss(slot int) error {
+	p := g.Current()
+	if slot < 0 || slot >= len(p.Followers) {
+		return fmt.Errorf("no follower in slot %d: %w", slot, ErrIllegalAction)
+	}
+	g.Logf("%s leaves you", p.Followers[slot].Title)
+	p.Followers = slices.Delete(p.Followers, slot, slot+1)
+	return nil
+}
+
+// drop leaves the pack item in slot behind.
+func (g *Game) drop(slot int) error {
+	p := g.Current()
+	if slot < 0 || slot >= len(p.Cards) || (p.Cards[slot].Type != ShopItemType && p.Cards[slot].Type != PotionType) {
+		return fmt.Errorf("nothing to drop in slot %d: %w", slot, ErrIllegalAction)
+	}
+	g.Logf("You leave %s behind", p.Cards[slot].Title)
+	p.Cards = slices.Delete(p.Cards, slot, slot+1)
+	return nil
+}
+
+// CanReroll reports whether the hero's guides can reroll the movement die
+// now.
+func (g *Game) CanReroll() bool {
+	return g.Phase == PhaseTargetSelect && g.RerollsUsed < g.Current().Rerolls()
+}
+
+// reroll throws the movement die again and recomputes the landing spots.
+func (g *Game) reroll() {
+	g.RerollsUsed++
+	old := g.LastRoll
+	g.LastRoll = g.Rng.Intn(6) + 1
+	g.StepsRemaining = g.LastRoll
+	g.Dests = gatherAllLandingSpots(g.Current().At, g.StepsRemaining, g.World, g.crownOpen())
+	g.Logf("Your guide finds another way: the roll goes from %d to %d", old, g.LastRoll)
+}
@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFollowerPowers(t *testing.T) {
+	var p Player
+	p.Followers = []Card{NewFollower(FollowerFighter, 2), NewFollower(FollowerPorter, 2), NewFollower(FollowerGuide, 1)}
+	if p.FollowerBonus() != 2 || p.PackCapacity() != PackSize+2 || p.Rerolls() != 1 {
+		t.Fatalf("bonus %d, pack %d, rerolls %d; want 2, %d, 1", p.FollowerBonus(), p.PackCapacity(), p.Rerolls(), PackSize+2)
+	}
+	if why := p.Fits(NewFollower(FollowerFighter, 1)); why == "" {
+		t.Error("a fourth follower joined")
+	}
+	p.Cards = make([]Card, PackSize+2)
+	for i := range p.Cards {
+		p.Cards[i] = NewPotion(1)
+	}
+	if p.PackRoom() != 0 || p.Fits(NewPotion(1)) == "" {
+		t.Errorf("%d pack room with %d potions, want none", p.PackRoom(), len(p.Cards))
+	}
+	newest := p.Followers[2].Title
+	if lost := p.loseFollower(); lost != newest || len(p.Followers) != 2 {
+		t.Fatalf("lost %q, want the newest follower", lost)
+	}
+	p.loseFollower()
+	if p.PackRoom() != -2 {
+		t.Errorf("%d pack room after the porter left, want -2", p.PackRoom())
+	}
+}
+
+func TestFighterJoinsTheRoll(t *testing.T) {
+	g := NewGame(1, 1)
+	p := g.Current()
+	p.Strength = 10
+	p.Followers = []Card{NewFollower(FollowerFighter, 2)}
+	fight(t, g, Card{Type: MonsterType, Strength: 1, HP: 2})
+	if err := g.Apply(Action{Kind: ActAttack}); err != nil {
+		t.Fatal(err)
+	}
+	if r := g.Battle.Rounds[0]; r.YourTot != 12+r.YourDie {
+		t.Errorf("rolled %d on a d6 of %d, want 12 more", r.YourTot, r.YourDie)
+	}
+}
+
+func TestLethalDie(t *testing.T) {
+	g := NewGame(1, 1)
+	p := g.Current()
+	p.Followers = []Card{NewFollower(FollowerFighter, 1), NewFollower(FollowerGuide, 1)}
+	g.Card = NewMonsterStrength(4)
+	for die, lost := range map[int]bool{LethalDie - 1: false, LethalDie: true} {
+		r := BattleRound{Move: MoveAttack, YourDie: 3, MonDie: die, Taken: 1}
+		g.wounded(&r)
+		if (r.Lost != "") != lost {
+			t.Errorf("monster's d6 %d: lost %q, want a follower lost %v", die, r.Lost, lost)
+		}
+	}
+	if len(p.Followers) != 1 {
+		t.Errorf("%d followers left, want 1", len(p.Followers))
+	}
+}
+
+func TestReroll(t *testing.T) {
+	g := NewGame(1, 1)
+	g.Current().Followers = []Card{NewFollower(FollowerGuide, 1)}
+	if err := g.Apply(Action{Kind: ActReroll}); !errors.Is(err, ErrIllegalAction) {
+		t.Fatalf("rerolling before the roll: got %v", err)
+	}
+	if err := g.Apply(Action{Kind: ActRoll}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Apply(Action{Kind: ActReroll}); err != nil {
+		t.Fatal(err)
+	}
+	if g.RerollsUsed != 1 || g.StepsRemaining != g.LastRoll || len(g.Dests) == 0 || g.Current().Stats.Turns != 1 {
+		t.Fatalf("rerolls %d, roll %d with %d steps and %d spots; want one reroll in the same turn", g.RerollsUsed, g.LastRoll, g.StepsRemaining, len(g.Dests))
+	}
+	if err := g.Apply(Action{Kind: ActReroll}); !errors.Is(err, ErrIllegalAction) {
+		t.Fatalf("a second reroll with one guide: got %v", err)
+	}
+}
+
+func TestDismissAndDrop(t *testing.T) {
+	g := NewGame(1, 1)
+	p := g.Current()
+	p.Followers = []Card{NewFollower(FollowerFighter, 1), NewFollower(FollowerPorter, 1)}
+	p.Cards = []Card{NewMonsterStrength(2), NewPotion(1)}
+	tests := []struct {
+		a  Action
+		ok bool
+	}{
+		{Action{Kind: ActDismiss, Slot: 2}, false},
+		{Action{Kind: ActDrop, Slot: 0}, false}, // trophies stay
+		{Action{Kind: ActDismiss, Slot: 0}, true},
+		{Action{Kind: ActDrop, Slot: 1}, true},
+	}
+	for _, tt := range tests {
+		if err := g.Apply(tt.a); (err == nil) != tt.ok {
+			t.Fatalf("%s slot %d: got %v, want ok %v", tt.a.Kind, tt.a.Slot, err, tt.ok)
+		}
+	}
+	if len(p.Followers) != 1 || p.Followers[0].Follower.Kind != FollowerPorter || len(p.Cards) != 1 || p.Cards[0].Type != MonsterType {
+		t.Errorf("followers %v and cards %v, want the porter and the trophy", titles(p.Followers), titles(p.Cards))
+	}
+	fight(t, g, NewMonsterStrength(4))
+	if err := g.Apply(Action{Kind: ActDismiss, Slot: 0}); !errors.Is(err, ErrIllegalAction) {
+		t.Errorf("dismissing mid-fight: got %v", err)
+	}
+}
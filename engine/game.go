@@ -0,0 +1,605 @@
+// Package engine holds the Talisman rules: the world graph, cards, the hero
+// and the turn state machine. It has no rendering or window dependencies so
+// it can be driven and tested headlessly; package main only draws it.
+package engine
+
+import (
+	"fmt"
+	"image/color"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const stepDelay = 0.18 // seconds between tile steps while resolving a roll
+type Phase int
+
+const (
+	PhaseIdle Phase = iota
+	PhaseTargetSelect
+	PhaseAnimating
+)
+
+type Game struct {
+	Seed int64      // seed the run was started from; shown in the HUD for bug reports
+	Rng  *rand.Rand // every random rules decision draws from this
+	src  *countingSource
+
+	Players        []Player
+	Active         int  // index into Players of whoever's turn it is
+	Moved          bool // the active player has rolled and moved this turn
+	DeathRule      DeathRule
+	Debug          bool // allow the sandbox moves: new world, step and follow link
+	World          *World
+	Turn           int // rolls made by all heroes together
+	Round          int // times the dice have gone round the table
+	LastRoll       int
+	RerollsUsed    int // movement rerolls the hero's guides have given this turn
+	StepsRemaining int
+	stepAccum      float32
+
+	Dir   int   // +1 = CW (Right/Next), -1 = CCW (Left/Prev)
+	Phase Phase // Idle -> ChooseDir -> Animating
+
+	// selection
+	Dests []TileID // all valid landing spots this turn
+	Path  []TileID // animation path after confirm (excluding current tile)
+
+	CardActive   bool
+	Card         Card
+	CardResolved bool
+	Log          []string // newest last
+
+	CardMsg    string          // result message after Interact
+	FightBonus int             // spell power cast on the open card's fight
+	Battle     *InteractResult // the fight on the open card, once the hero has engaged it
+
+	Actions []Action // every decision applied since NewGame, for replays
+
+	// Shop inventory
+	ShopActive bool
+	ShopCards  [3]Card
+	ShopPrices [3]int
+}
+
+const (
+	MinPlayers = 1
+	MaxPlayers = 6
+)
+
+// PlayerColors tells the heroes apart on the board, the HUD and the log.
+var PlayerColors = []color.RGBA{
+	{220, 50, 50, 255},  // red
+	{50, 110, 220, 255}, // blue
+	{240, 200, 40, 255}, // yellow
+	{60, 170, 80, 255},  // green
+	{160, 70, 200, 255}, // purple
+	{240, 130, 40, 255}, // orange
+}
+
+// NewGame starts a fresh run: a random world with numPlayers heroes on its
+// first tile, taking turns in order. The same seed always produces the same
+// world and, given the same inputs, the same dice.
+func NewGame(seed int64, numPlayers int) *Game {
+	numPlayers = max(MinPlayers, min(numPlayers, MaxPlayers))
+	g := &Game{Seed: seed, DeathRule: Respawn}
+	g.seedRng(seed, 0)
+	for i := range numPlayers {
+		p := NewPlayer(TileID{0, 0}, 3, 3, StartHealth)
+		p.Name = fmt.Sprintf("Hero %d", i+1)
+		p.Color = PlayerColors[i]
+		g.Players = append(g.Players, *p)
+	}
+	g.newWorld()
+	return g
+}
+
+// Current is the player whose turn it is.
+func (g *Game) Current() *Player { return &g.Players[g.Active] }
+
+// endTurn hands the dice to the next player, skipping heroes that have
+// fallen for good.
+func (g *Game) endTurn() {
+	g.Moved = false
+	prev := g.Active
+	for range len(g.Players) {
+		g.Active = (g.Active + 1) % len(g.Players)
+		if !g.Current().Dead || g.DeathRule != Permadeath {
+			break
+		}
+	}
+	if g.Active <= prev {
+		g.Round++
+	}
+	if len(g.Players) > 1 && !g.Over() {
+		g.Logf("--- %s's turn ---", g.Current().Name)
+	}
+}
+
+// cardDone is called once the landing card is out of the way. A lone hero
+// has nothing to wait for, so their turn ends straight away; in hot-seat
+// games the player may still shop before passing the dice. A fallen hero
+// stays put until it respawns or passes.
+func (g *Game) cardDone() {
+	if len(g.Players) == 1 && !g.Current().Dead && !g.Over() {
+		g.endTurn()
+	}
+}
+
+// seedRng (re)creates the game's RNG and fast-forwards it past the first
+// draws values.
+func (g *Game) seedRng(seed int64, draws uint64) {
+	g.src = newCountingSource(seed)
+	g.src.skip(draws)
+	g.Rng = rand.New(g.src)
+}
+
+// newWorld throws away the board and generates another one from the game's
+// RNG, putting the hero back on the starting tile.
+func (g *Game) newWorld() {
+	regions, counts := RandomRegions(g.Rng)
+	w := BuildWorldCountsRandom(g.Rng, regions, counts, g.Round)
+	g.World = &w
+	// reset players to safe starting position
+	for i := range g.Players {
+		g.Players[i].At = TileID{0, 0}
+	}
+	g.StepsRemaining = 0
+	g.Phase = PhaseIdle
+}
+
+// keep only the last N lines
+const LogMax = 8
+
+// Logf appends a line to the game log. With several heroes on the board the
+// line is attributed to whoever's turn it is.
+func (g *Game) Logf(format string, args ...any) {
+	line := fmt.Sprintf(format, args...)
+	if len(g.Players) > 1 && !strings.HasPrefix(line, "---") {
+		line = g.Current().Name + ": " + line
+	}
+	g.Log = append(g.Log, line)
+	if len(g.Log) > LogMax {
+		g.Log = g.Log[len(g.Log)-LogMax:]
+	}
+}
+func (g *Game) CanRoll() bool { return g.Phase == PhaseIdle && !g.Moved && !g.Current().Dead }
+
+func (g *Game) roll() {
+	blessed := g.Current().HasStatus(StatusBlessing)
+	if !g.spendTurn() {
+		return // the poison got there first
+	}
+	if blessed {
+		g.LastRoll = 6
+		g.Logf("Blessed: the die comes up six")
+	} else {
+		g.LastRoll = g.Rng.Intn(6) + 1
+	}
+	g.StepsRemaining = g.LastRoll
+	g.RerollsUsed = 0
+
+	// build destinations immediately (both directions; bridges allowed; no shops)
+	g.Dests = gatherAllLandingSpots(g.Current().At, g.StepsRemaining, g.World, g.crownOpen()) // see below
+	g.Path = nil
+	g.stepAccum = 0
+	g.Phase = PhaseTargetSelect
+}
+
+// spendTurn counts the hero's move for the turn, by die or by spell, and
+// ticks its statuses. It reports false when the poison killed the hero.
+func (g *Game) spendTurn() bool {
+	g.Turn++
+	g.Moved = true
+	g.Current().Stats.Turns++
+	g.tickStatuses()
+	return !g.Current().Dead
+}
+
+func (g *Game) Update(dt float32, w *World) {
+	if g.Phase != PhaseAnimating || g.StepsRemaining <= 0 {
+		return
+	}
+	g.stepAccum += dt
+	for g.StepsRemaining > 0 && g.stepAccum >= stepDelay {
+		if len(g.Path) > 0 {
+			g.Current().At = g.Path[0]
+			g.Path = g.Path[1:]
+		}
+		g.StepsRemaining--
+		g.stepAccum -= stepDelay
+	}
+	if g.StepsRemaining == 0 {
+		// Spawn a card for the tile we just landed on
+		curLoop := w.Loops[g.Current().At.Loop]
+		g.Current().Stats.DeepestTier = max(g.Current().Stats.DeepestTier, curLoop.Type.Tier)
+		g.dealCard()
+		g.FightBonus = 0
+		g.Battle = nil
+		g.CardActive = true
+		g.CardResolved = false
+		g.CardMsg = ""
+		g.Phase = PhaseIdle // keep idle for input; modal will capture keys
+		g.Dests = nil
+		g.Path = nil
+		if g.Card.FightStat() != "" && g.Card.Has(AbilityAmbush) {
+			g.ambush()
+		}
+	}
+}
+
+// chooseDest commits to one of this roll's landing spots and starts walking
+// there.
+func (g *Game) chooseDest(target TileID) error {
+	if !slices.Contains(g.Dests, target) {
+		return fmt.Errorf("tile %v is not a legal landing spot: %w", target, ErrIllegalAction)
+	}
+	g.Path = buildPathTo(g.World, g.Current().At, g.LastRoll, target, g.crownOpen())
+	g.StepsRemaining = len(g.Path) // drive animation by path length now
+	g.Phase = PhaseAnimating
+	return nil
+}
+
+// cancelTarget gives up a roll that left nowhere to land. The die has been
+// thrown, so the hero stays put and the turn passes.
+func (g *Game) cancelTarget() {
+	g.Phase = PhaseIdle
+	g.Dests = nil
+	g.Logf("Nowhere to land; you stay put")
+	g.endTurn()
+}
+
+// FinishMove completes the current walk at once instead of stepping through
+// it frame by frame. Headless drivers use it; the result is the same as
+// letting Update run.
+func (g *Game) FinishMove() {
+	if g.Phase != PhaseAnimating {
+		return
+	}
+	g.Update(float32(g.StepsRemaining+1)*stepDelay, g.World)
+}
+
+// resolveCard lets the hero interact with the active card and writes the
+// outcome to the log. Monsters and the guardian are not settled here: the
+// hero engages them and the fight goes on round by round. Events, places,
+// traps and scripts are played out by the Game, which rolls their dice.
+func (g *Game) resolveCard() {
+	if g.Card.FightStat() != "" {
+		g.startBattle()
+		return
+	}
+	switch g.Card.Type {
+	case EventType:
+		g.CardMsg = g.befall()
+		g.CardResolved = true
+		return
+	case PlaceType:
+		g.CardMsg = g.visitPlace()
+		g.CardResolved = true
+		return
+	case TrapType:
+		g.CardMsg = g.springTrap()
+		g.CardResolved = true
+		return
+	}
+	res := g.Current().Interact(&g.Card)
+	if !res.Kept {
+		g.setAside()
+	}
+	if g.Card.Type == ScriptedType {
+		res.Message = g.runScript()
+	}
+
+	switch res.Card.Type {
+	case TalismanType:
+		g.Logf("You take the Talisman")
+	case SpellType, PotionType, FollowerType, TreasureType:
+		g.Logf("%s", res.Message)
+	case BuffType:
+		// buff log - handle both strength and magic
+		strDelta := res.StrAfter - res.StrBefore
+		magicDelta := res.MagicAfter - res.MagicBefore
+
+		if strDelta > 0 && magicDelta > 0 {
+			g.Logf("Buff: +%d STR, +%d MAG (STR %d → %d, MAG %d → %d)",
+				strDelta, magicDelta, res.StrBefore, res.StrAfter, res.MagicBefore, res.MagicAfter)
+		} else if strDelta > 0 {
+			g.Logf("Buff: +%d STR (STR %d → %d)", strDelta, res.StrBefore, res.StrAfter)
+		} else if magicDelta > 0 {
+			g.Logf("Buff: +%d MAG (MAG %d → %d)", magicDelta, res.MagicBefore, res.MagicAfter)
+		}
+	}
+
+	// Show the friendly one-line toast in the modal
+	g.CardMsg = res.Message
+	g.CardResolved = true
+}
+
+// closeCard dismisses the card modal once it has been resolved.
+func (g *Game) closeCard() {
+	g.CardActive = false
+	g.Battle = nil
+	g.cardDone()
+}
+
+// skipCard walks away from the card without interacting with it.
+func (g *Game) skipCard() {
+	g.setAside()
+	g.CardActive = false
+	g.cardDone()
+}
+
+func isShopTile(w *World, id TileID) bool {
+	t := w.Loops[id.Loop].Tiles[id.Index]
+	if t.Shop { // explicit flag for 1-tile shop loops
+		return true
+	}
+	// if ever you mark perimeter tiles as Shop in future, this still works
+	return false
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// helper: is this a bridge tile?
+func isBridgeTile(w *World, id TileID) bool {
+	return w.Loops[id.Loop].Tiles[id.Index].Bridge
+}
+
+// helper: next/prev step along the loop in a fixed direction
+func stepAlongDir(w *World, id TileID, dir int) TileID {
+	if dir >= 0 {
+		return w.Loops[id.Loop].Tiles[id.Index].Next
+	}
+	return w.Loops[id.Loop].Tiles[id.Index].Prev
+}
+
+// Bridge FSM phases while searching a single roll.
+const (
+	bridgeNone   = 0 // not on a bridge, not in a forced sequence
+	bridgePhase1 = 1 // just stepped ONTO the first bridge tile -> must go to the other bridge tile next
+	bridgePhase2 = 2 // now on the second bridge tile -> must EXIT to its linked perimeter tile
+)
+
+// BFS in a fixed direction. Every hop costs 1 step. The Crown's tiles are only
+// walked when crownOpen is set.
+// Returns: set of legal endpoints (not shops, not bridge tiles) and a parent map to backtrack one path.
+func bfsFixedDir(w *World, start TileID, steps int, dir int, crownOpen bool) (endpoints map[TileID]bool, parent map[TileID]TileID) {
+	type State struct {
+		id          TileID
+		k           int  // steps left
+		usedBridge  bool // true once we've stepped onto any bridge tile this roll
+		bridgePhase int  // bridgeNone / bridgePhase1 / bridgePhase2
+	}
+
+	// queue & visited (include usedBridge and bridgePhase in the key)
+	queue := []State{{id: start, k: steps, usedBridge: false, bridgePhase: bridgeNone}}
+	seen := map[[5]int]bool{
+		{start.Loop, start.Index, steps, 0, bridgeNone}: true,
+	}
+
+	parent = map[TileID]TileID{}  // store one predecessor per tile (enough to backtrack a shortest path)
+	endpoints = map[TileID]bool{} // tiles reachable with exactly 0 steps
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		// If no steps left, we can end here (but never on shop or bridge tiles).
+		if cur.k == 0 {
+			if !isShopTile(w, cur.id) && !isBridgeTile(w, cur.id) {
+				endpoints[cur.id] = true
+			}
+			continue
+		}
+
+		// Current tile info
+		ct := w.Loops[cur.id.Loop].Tiles[cur.id.Index]
+		onBridge := ct.Bridge
+
+		// Generate candidate neighbors BEFORE gating:
+		stepNeighbor := stepAlongDir(w, cur.id, dir) // along-loop step in fixed dir
+
+		// Candidate link neighbors from here (shops will be filtered out)
+		linkNeighbors := ct.Links
+
+		// Now apply the movement rules / gating based on the bridge FSM.
+		enqueue := func(nextID TileID, nextK int, used bool, phase int) {
+			key := [5]int{nextID.Loop, nextID.Index, nextK, btoi(used), phase}
+			if seen[key] {
+				return
+			}
+			if !crownOpen && w.Loops[nextID.Loop].Type.Crown {
+				return // sealed without a Talisman
+			}
+			seen[key] = true
+			// store parent only once (shortest due to BFS)
+			if _, ok := parent[nextID]; !ok {
+				parent[nextID] = cur.id
+			}
+			queue = append(queue, State{id: nextID, k: nextK, usedBridge: used, bridgePhase: phase})
+		}
+
+		switch cur.bridgePhase {
+		case bridgeNone:
+			// Normal movement: along the loop (if not a shop), and possibly enter a bridge (if not used yet).
+			// 1) along-loop step
+			if !isShopTile(w, stepNeighbor) && !isBridgeTile(w, stepNeighbor) {
+				enqueue(stepNeighbor, cur.k-1, cur.usedBridge, bridgeNone)
+			}
+
+			// 2) follow any links that are legal
+			for _, nb := range linkNeighbors {
+				// skip shops
+				if isShopTile(w, nb) {
+					continue
+				}
+				if isBridgeTile(w, nb) {
+					// entering a bridge is allowed ONLY if we haven't used one yet
+					if cur.usedBridge {
+						continue
+					}
+					// Step ONTO the first bridge tile (cost 1), must go to the other bridge tile next.
+					enqueue(nb, cur.k-1, true /*used now*/, bridgePhase1)
+				} else {
+					// non-bridge, non-shop link (rare in your map, but keep rule clean)
+					enqueue(nb, cur.k-1, cur.usedBridge, bridgeNone)
+				}
+			}
+
+			// Edge case: if we *start* already standing on a bridge tile, force the same logic as Phase1.
+			// (This shouldn't happen in normal flow, but keeps the search robust.)
+			if onBridge {
+				// The bridge loop has next/prev both pointing to the other bridge tile,
+				// so just force the "go to other bridge tile" step.
+				other := stepNeighbor
+				if !isShopTile(w, other) && isBridgeTile(w, other) {
+					enqueue(other, cur.k-1, true, bridgePhase2)
+				}
+			}
+
+		case bridgePhase1:
+			// We are on the FIRST bridge tile: the ONLY legal move is to the other bridge tile via loop step.
+			other := stepNeighbor // in the 2-tile bridge loop, this is the other bridge tile
+			if isBridgeTile(w, other) {
+				enqueue(other, cur.k-1, cur.usedBridge, bridgePhase2)
+			}
+			// No links allowed from this tile in this phase (can't hop off mid-bridge).
+
+		case bridgePhase2:
+			// We are on the SECOND bridge tile: the ONLY legal move is to EXIT via its link to perimeter.
+			for _, nb := range linkNeighbors {
+				if isShopTile(w, nb) {
+					continue
+				}
+				// The exit must be to a NON-bridge tile.
+				if isBridgeTile(w, nb) {
+					continue
+				}
+				enqueue(nb, cur.k-1, cur.usedBridge, bridgeNone)
+			}
+			// Do NOT allow stepping along the tiny bridge loop here (would bounce back).
+		}
+	}
+
+	return
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// union of CW and CCW endpoints + remember a parent map for later path extraction
+type reachResult struct {
+	endpoints map[TileID]bool
+	parentCW  map[TileID]TileID
+	parentCCW map[TileID]TileID
+}
+
+// run both directions and merge
+func reachBothDirs(w *World, start TileID, steps int, crownOpen bool) reachResult {
+	endsCW, pCW := bfsFixedDir(w, start, steps, +1, crownOpen)
+	endsCC, pCC := bfsFixedDir(w, start, steps, -1, crownOpen)
+	union := map[TileID]bool{}
+	for id := range endsCW {
+		union[id] = true
+	}
+	for id := range endsCC {
+		union[id] = true
+	}
+	// never include the start as a “destination” if steps>0
+	if steps > 0 {
+		delete(union, start)
+	}
+	return reachResult{endpoints: union, parentCW: pCW, parentCCW: pCC}
+}
+
+// gather + stable order for UI
+func gatherAllLandingSpots(start TileID, steps int, w *World, crownOpen bool) []TileID {
+	r := reachBothDirs(w, start, steps, crownOpen)
+	out := make([]TileID, 0, len(r.endpoints))
+	for id := range r.endpoints {
+		out = append(out, id)
+	}
+	// stable-ish ordering: loop asc, index asc
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Loop != out[j].Loop {
+			return out[i].Loop < out[j].Loop
+		}
+		return out[i].Index < out[j].Index
+	})
+	return out
+}
+
+func buildPathTo(w *World, start TileID, steps int, goal TileID, crownOpen bool) []TileID {
+	endsCW, pCW := bfsFixedDir(w, start, steps, +1, crownOpen)
+	if endsCW[goal] {
+		return backtrackPath(pCW, start, goal)
+	}
+	endsCC, pCC := bfsFixedDir(w, start, steps, -1, crownOpen)
+	if endsCC[goal] {
+		return backtrackPath(pCC, start, goal)
+	}
+	return nil
+}
+
+func backtrackPath(parent map[TileID]TileID, start, goal TileID) []TileID {
+	// parent maps each node to *a* predecessor; rebuild then reverse.
+	cur := goal
+	path := []TileID{cur}
+	for cur != start {
+		p, ok := parent[cur]
+		if !ok {
+			break
+		}
+		cur = p
+		path = append(path, cur)
+	}
+	// reverse to be start->goal, and drop the first (=start) because you’re already there
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	if len(path) > 0 && path[0] == start {
+		return path[1:]
+	}
+	return path
+}
+
+// small helpers
+func stepDir(id TileID, dir int, w *World) TileID {
+	if dir >= 0 {
+		return w.Loops[id.Loop].Tiles[id.Index].Next
+	}
+	return w.Loops[id.Loop].Tiles[id.Index].Prev
+}
+
+func destAfterSteps(start TileID, steps, dir int, w *World) TileID {
+	cur := start
+	for i := 0; i < steps; i++ {
+		cur = stepDir(cur, dir, w)
+	}
+	return cur
+}
+
+// Initialize shop from persistent shop data
+func (g *Game) InitShop(shopData *ShopType) {
+	// Load from persistent shop data
+	for i := 0; i < 3; i++ {
+		g.ShopCards[i] = shopData.Cards[i]
+		g.ShopPrices[i] = shopData.Prices[i]
+	}
+
+	// Mark shop as discovered
+	if !shopData.Discovered {
+		shopData.Discovered = true
+		g.Logf("Discovered %s!", shopData.Name)
+	}
+}
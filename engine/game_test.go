@@ -0,0 +1,220 @@
+package engine
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// bridgedWorld is two 8-tile loops joined by a bridge (loop 2) from tile 2
+// of loop 0 to tile 5 of loop 1, with a shop (loop 3) off tile 1 of loop 0.
+func bridgedWorld() World {
+	w := World{Loops: []Loop{
+		{Tiles: make([]Tile, 8)},
+		{Tiles: make([]Tile, 8)},
+		makeBridge(Grid{}, 0, 0, 0, 0),
+		makeShop(Grid{}, 0, 0),
+	}}
+	finalizeLoopIndices(&w)
+	linkBoth(&w, TileID{0, 2}, TileID{2, 0})
+	linkBoth(&w, TileID{2, 1}, TileID{1, 5})
+	linkBoth(&w, TileID{0, 1}, TileID{3, 0})
+	return w
+}
+
+func TestBfsFixedDir(t *testing.T) {
+	tests := []struct {
+		name   string
+		steps  int
+		dir    int
+		crown  bool // loop 1 is The Crown
+		open   bool // and the hero carries a Talisman
+		ends   []TileID
+		bridge []TileID // the path to the tile on loop 1, if any
+	}{
+		{name: "past the shop", steps: 2, dir: 1, ends: []TileID{{0, 2}}},
+		{name: "never ends on the bridge", steps: 3, dir: 1, ends: []TileID{{0, 3}}},
+		{name: "over the bridge", steps: 5, dir: 1, ends: []TileID{{0, 5}, {1, 5}},
+			bridge: []TileID{{0, 1}, {0, 2}, {2, 0}, {2, 1}, {1, 5}}},
+		{name: "on after the bridge", steps: 6, dir: 1, ends: []TileID{{0, 6}, {1, 6}},
+			bridge: []TileID{{0, 1}, {0, 2}, {2, 0}, {2, 1}, {1, 5}, {1, 6}}},
+		{name: "backwards", steps: 5, dir: -1, ends: []TileID{{0, 3}}},
+		{name: "sealed crown", steps: 5, dir: 1, crown: true, ends: []TileID{{0, 5}}},
+		{name: "open crown", steps: 5, dir: 1, crown: true, open: true, ends: []TileID{{0, 5}, {1, 5}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := bridgedWorld()
+			w.Loops[1].Type.Crown = tt.crown
+			ends, parent := bfsFixedDir(&w, TileID{0, 0}, tt.steps, tt.dir, tt.open)
+			got := slices.SortedFunc(maps.Keys(ends), func(a, b TileID) int {
+				return (a.Loop-b.Loop)*100 + a.Index - b.Index
+			})
+			if !slices.Equal(got, tt.ends) {
+				t.Fatalf("endpoints %v, want %v", got, tt.ends)
+			}
+			if tt.bridge != nil {
+				last := tt.bridge[len(tt.bridge)-1]
+				if path := backtrackPath(parent, TileID{0, 0}, last); !slices.Equal(path, tt.bridge) {
+					t.Errorf("path %v, want %v", path, tt.bridge)
+				}
+			}
+		})
+	}
+}
+
+func TestDebugMoves(t *testing.T) {
+	tests := []struct {
+		name     string
+		debug    bool
+		talisman bool
+		at       TileID
+		a        Action
+		ok       bool
+		to       TileID
+	}{
+		{name: "new world is off", a: Action{Kind: ActNewWorld}},
+		{name: "step is off", a: Action{Kind: ActStep, Dir: 1}},
+		{name: "follow link is off", at: TileID{2, 0}, a: Action{Kind: ActFollowLink}},
+		{name: "step", debug: true, a: Action{Kind: ActStep, Dir: -1}, ok: true, to: TileID{0, 7}},
+		{name: "follow link", debug: true, at: TileID{0, 2}, a: Action{Kind: ActFollowLink}, ok: true, to: TileID{2, 0}},
+		{name: "no link", debug: true, a: Action{Kind: ActFollowLink}},
+		{name: "into a sealed crown", debug: true, at: TileID{2, 1}, a: Action{Kind: ActFollowLink}},
+		{name: "into an open crown", debug: true, talisman: true, at: TileID{2, 1}, a: Action{Kind: ActFollowLink}, ok: true, to: TileID{1, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGame(1, 1)
+			w := bridgedWorld()
+			w.Loops[1].Type.Crown = true
+			g.World = &w
+			g.Debug = tt.debug
+			p := g.Current()
+			p.At = tt.at
+			if tt.talisman {
+				p.Cards = append(p.Cards, NewTalisman())
+			}
+			err := g.Apply(tt.a)
+			if !tt.ok {
+				if !errors.Is(err, ErrIllegalAction) || p.At != tt.at {
+					t.Fatalf("got %v at %v, want an illegal action at %v", err, p.At, tt.at)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.At != tt.to {
+				t.Errorf("at %v, want %v", p.At, tt.to)
+			}
+		})
+	}
+}
+
+func TestHotSeatTurns(t *testing.T) {
+	g := NewGame(1, 3)
+	if err := g.Apply(Action{Kind: ActEndTurn}); !errors.Is(err, ErrIllegalAction) {
+		t.Fatalf("ending the turn before moving: got %v", err)
+	}
+	if err := g.Apply(Action{Kind: ActRoll}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Apply(Action{Kind: ActChooseDest, Tile: g.Dests[0]}); err != nil {
+		t.Fatal(err)
+	}
+	g.FinishMove()
+	for g.CardActive {
+		if err := g.Apply(Cautious.NextAction(g)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the card is done, but with company at the table the dice wait
+	if g.Active != 0 || !g.Moved || g.Current().Dead {
+		t.Fatalf("hero %d active, moved %v, dead %v; want hero 0 still up after moving", g.Active, g.Moved, g.Current().Dead)
+	}
+	if err := g.Apply(Action{Kind: ActRoll}); !errors.Is(err, ErrIllegalAction) {
+		t.Fatalf("rolling twice: got %v", err)
+	}
+	for _, line := range g.Log {
+		if !strings.HasPrefix(line, "Hero 1: ") {
+			t.Errorf("log line %q is not Hero 1's", line)
+		}
+	}
+	if err := g.Apply(Action{Kind: ActEndTurn}); err != nil {
+		t.Fatal(err)
+	}
+	if g.Active != 1 || g.Moved || g.Log[len(g.Log)-1] != "--- Hero 2's turn ---" {
+		t.Fatalf("hero %d active, moved %v, log ends %q; want Hero 2 to roll", g.Active, g.Moved, g.Log[len(g.Log)-1])
+	}
+}
+
+func TestCancelDest(t *testing.T) {
+	for _, players := range []int{1, 2} {
+		g := NewGame(1, players)
+		g.roll()
+		if err := g.Apply(Action{Kind: ActCancelDest}); !errors.Is(err, ErrIllegalAction) {
+			t.Fatalf("cancelling with somewhere to land: got %v", err)
+		}
+		at := g.Current().At
+		g.Dests = nil
+		if err := g.Apply(Action{Kind: ActCancelDest}); err != nil {
+			t.Fatal(err)
+		}
+		if g.Players[0].At != at || g.Phase != PhaseIdle || g.Active != 1%players {
+			t.Errorf("%d heroes: at %v in phase %v with hero %d up; want the dice passed", players, g.Players[0].At, g.Phase, g.Active)
+		}
+		if players == 1 && !g.CanRoll() {
+			t.Error("a lone hero cannot roll again in the next turn")
+		}
+	}
+}
+
+// meet puts c on the hero's tile and has the hero land there.
+func meet(g *Game, c Card) {
+	at := g.Current().At
+	g.World.Loops[at.Loop].Tiles[at.Index].Card = &c
+	g.Phase, g.StepsRemaining = PhaseAnimating, 1
+	g.FinishMove()
+}
+
+func TestClaimCrown(t *testing.T) {
+	tests := []struct {
+		name     string
+		strength int // the guardian's
+		won      bool
+	}{
+		{name: "beat the guardian", strength: 0, won: true},
+		{name: "fall to the guardian", strength: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGame(1, 2)
+			p := g.Current()
+			p.Strength, p.Health = 50, 1
+			meet(g, NewCrownGuardian(tt.strength))
+			if err := g.Apply(Action{Kind: ActConfirmCard}); err != nil {
+				t.Fatal(err)
+			}
+			if err := g.Apply(Action{Kind: ActAttack}); err != nil {
+				t.Fatal(err)
+			}
+			if p.Won != tt.won || p.Dead == tt.won || g.Over() != tt.won {
+				t.Fatalf("won %v, dead %v, over %v; want won %v", p.Won, p.Dead, g.Over(), tt.won)
+			}
+			if !tt.won {
+				return
+			}
+			if g.Winner() != p {
+				t.Errorf("winner %v, want %s", g.Winner(), p.Name)
+			}
+			if err := g.Apply(Action{Kind: ActCloseCard}); err != nil {
+				t.Fatal(err)
+			}
+			if err := g.Apply(Action{Kind: ActEndTurn}); !errors.Is(err, ErrIllegalAction) {
+				t.Errorf("playing on after the win: got %v", err)
+			}
+		})
+	}
+}
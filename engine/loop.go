@@ -1,22 +1,27 @@
-package main
+package engine
 
 import (
+	"image/color"
 	"math"
 	"math/rand"
-
-	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
+// Vec2 is a position in world pixels. It has the same layout as rl.Vector2,
+// so the front-end can convert it directly.
+type Vec2 struct {
+	X, Y float32
+}
+
 type Grid struct {
-	Origin rl.Vector2 // top-left of grid in pixels
-	Cell   float32    // tileSize
+	Origin Vec2    // top-left of grid in pixels
+	Cell   float32 // tileSize
 }
 
-func (g Grid) Center(cx, cy int) rl.Vector2 {
-	return rl.NewVector2(g.Origin.X+float32(cx)*g.Cell+g.Cell/2,
-		g.Origin.Y+float32(cy)*g.Cell+g.Cell/2)
+func (g Grid) Center(cx, cy int) Vec2 {
+	return Vec2{g.Origin.X + float32(cx)*g.Cell + g.Cell/2,
+		g.Origin.Y + float32(cy)*g.Cell + g.Cell/2}
 }
-func (g Grid) CellOf(p rl.Vector2) (int, int) {
+func (g Grid) CellOf(p Vec2) (int, int) {
 	fx := (p.X - g.Origin.X) / g.Cell
 	fy := (p.Y - g.Origin.Y) / g.Cell
 	cx := int(math.Floor(float64(fx)))
@@ -26,6 +31,7 @@ func (g Grid) CellOf(p rl.Vector2) (int, int) {
 
 type World struct {
 	Loops []Loop
+	Piles map[string]*Pile // the world's encounter decks in play, by Deck.Name
 }
 
 type Loop struct {
@@ -35,48 +41,17 @@ type Loop struct {
 
 type LoopType struct {
 	Name  string
-	Color rl.Color
+	Color color.RGBA
 	Deck  Deck
+	Tier  int  `json:",omitempty"` // difficulty, 1 = where heroes start
+	Crown bool `json:",omitempty"` // sealed to heroes without a Talisman
+	Boss  Card `json:",omitzero"`  // guards one tile of the region; no Type if it has none
 }
 
-var loop1 LoopType = LoopType{
-	Name:  "Outer Fields",
-	Color: rl.NewColor(120, 180, 100, 255), // Peaceful meadow green
-	Deck:  deck1,
-}
-
-var loop2 LoopType = LoopType{
-	Name:  "Forest Paths",
-	Color: rl.NewColor(80, 140, 70, 255), // Deep forest green
-	Deck:  deck2,
-}
-
-var loop3 LoopType = LoopType{
-	Name:  "Desert Sands",
-	Color: rl.NewColor(200, 160, 80, 255), // Warm desert sand
-	Deck:  deck3,
-}
-
-var loop4 LoopType = LoopType{
-	Name:  "Mountain Caves",
-	Color: rl.NewColor(120, 110, 140, 255), // Mysterious cave purple-gray
-	Deck:  deck4,
-}
-
-var loop5 LoopType = LoopType{
-	Name:  "Fire Peaks",
-	Color: rl.NewColor(180, 80, 60, 255), // Volcanic red-orange
-	Deck:  deck5,
-}
-
-var loop6 LoopType = LoopType{
-	Name:  "Shadow Realm",
-	Color: rl.NewColor(60, 40, 80, 255), // Dark mystical purple
-	Deck:  deck6,
+func rgba(r, g, b, a uint8) color.RGBA {
+	return color.RGBA{R: r, G: g, B: b, A: a}
 }
 
-var loops []LoopType = []LoopType{loop1, loop2, loop3, loop4, loop5, loop6}
-
 type TileID struct {
 	Loop, Index int
 }
@@ -87,15 +62,18 @@ type ShopType struct {
 	Prices     [3]int
 	Discovered bool
 	KeeperType int // 0-3 for different shopkeeper types
+	Stocked    int // the round the shelves were last filled
 }
 
 type Tile struct {
-	Pos        rl.Vector2
+	Pos        Vec2
 	Next, Prev TileID
 	Links      []TileID
 	Bridge     bool
 	Shop       bool
 	ShopData   *ShopType // Pointer to shop data if this is a shop tile
+	Boss       bool      `json:",omitempty"` // the region's boss waits here until beaten
+	Card       *Card     // a monster left waiting here, dealt again to whoever lands next
 }
 
 // Choose rectangle dimensions (cols, rows) s.t. perimeter = n and near-square.
@@ -122,7 +100,7 @@ func rectDimsForPerimeter(n int) (cols, rows int, ok bool) {
 }
 
 // Build rect perimeter at top-left grid cell (gx, gy) with exact (cols, rows).
-func buildRectPerimeterLoopAtGrid(g Grid, gx, gy, cols, rows int, loopIndex int) Loop {
+func buildRectPerimeterLoopAtGrid(g Grid, gx, gy, cols, rows int, loopType LoopType) Loop {
 	n := 2*cols + 2*rows - 4
 	tiles := make([]Tile, 0, n)
 
@@ -149,15 +127,7 @@ func buildRectPerimeterLoopAtGrid(g Grid, gx, gy, cols, rows int, loopIndex int)
 		tiles[i].Next = TileID{-1, (i + 1) % n}
 		tiles[i].Prev = TileID{-1, (i - 1 + n) % n}
 	}
-	
-	// Choose loop type: sequential for first 3, then random
-	var loopType LoopType
-	if loopIndex < 3 {
-		loopType = loops[loopIndex] // loops[0], loops[1], loops[2]
-	} else {
-		loopType = loops[rand.Intn(len(loops))]
-	}
-	
+
 	return Loop{Tiles: tiles, Type: loopType}
 }
 
@@ -250,7 +220,7 @@ func linkBoth(w *World, a, b TileID) {
 }
 
 type circle struct {
-	C rl.Vector2
+	C Vec2
 	R float32
 }
 
@@ -264,10 +234,10 @@ const (
 	Down
 )
 
-func shuffledDirs() []Dir {
+func shuffledDirs(r *rand.Rand) []Dir {
 	d := []Dir{Right, Left, Up, Down}
 	for i := range d {
-		j := rand.Intn(i + 1)
+		j := r.Intn(i + 1)
 		d[i], d[j] = d[j], d[i]
 	}
 	return d
@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"math/rand"
+	"slices"
+)
+
+// Pile is a world's own copy of an encounter deck, played like the cards on
+// the table: shuffled, dealt from the top and discarded. Cards a hero keeps
+// leave the deck for good, beaten monsters as trophies. When the draw pile
+// runs out the discards are shuffled back in, and when every card has left
+// the deck a fresh copy is shuffled.
+type Pile struct {
+	Draw    []Card `json:",omitempty"` // face down; the next card is Draw[0]
+	Discard []Card `json:",omitempty"`
+}
+
+// pile is the world's pile for deck d, made the first time it is needed.
+func (w *World) pile(d Deck) *Pile {
+	if w.Piles == nil {
+		w.Piles = map[string]*Pile{}
+	}
+	p, ok := w.Piles[d.Name]
+	if !ok {
+		p = &Pile{Discard: slices.Clone(d.Cards)}
+		w.Piles[d.Name] = p
+	}
+	return p
+}
+
+// draw deals the top card, shuffling first if the draw pile is empty.
+func (p *Pile) draw(r *rand.Rand, d Deck) Card {
+	if len(p.Draw) == 0 {
+		if len(p.Discard) == 0 {
+			p.Discard = slices.Clone(d.Cards)
+		}
+		p.Draw = shuffleWeighted(r, p.Discard)
+		p.Discard = nil
+	}
+	c := p.Draw[0]
+	p.Draw = p.Draw[1:]
+	return c
+}
+
+// shuffleWeighted puts cs in a random order in which heavier cards tend to
+// come up sooner: each place is dealt with drawWeighted from the cards left.
+func shuffleWeighted(r *rand.Rand, cs []Card) []Card {
+	left := slices.Clone(cs)
+	out := make([]Card, 0, len(cs))
+	for len(left) > 0 {
+		total := 0
+		for _, c := range left {
+			total += c.DrawWeight()
+		}
+		n := r.Intn(total)
+		i := 0
+		for ; i < len(left)-1; i++ {
+			if n -= left[i].DrawWeight(); n < 0 {
+				break
+			}
+		}
+		out = append(out, left[i])
+		left = slices.Delete(left, i, i+1)
+	}
+	return out
+}
+
+// Upcoming is what the next draw from deck d could bring: the draw pile, or
+// whatever a reshuffle would make of it when that is empty.
+func (w *World) Upcoming(d Deck) []Card {
+	p := w.Piles[d.Name]
+	switch {
+	case p == nil:
+		return d.Cards
+	case len(p.Draw) > 0:
+		return p.Draw
+	case len(p.Discard) > 0:
+		return p.Discard
+	}
+	return d.Cards
+}
+
+// WaitingAt is the monster left waiting on tile id, if any.
+func (w *World) WaitingAt(id TileID) *Card {
+	return w.Loops[id.Loop].Tiles[id.Index].Card
+}
+
+// dealCard puts the card for the hero's landing tile on the table: the
+// boss, a monster still waiting there, or the top of the region's deck.
+func (g *Game) dealCard() {
+	at := g.Current().At
+	t := &g.World.Loops[at.Loop].Tiles[at.Index]
+	deck := g.World.Loops[at.Loop].Type.Deck
+	switch {
+	case t.Boss:
+		g.Card = g.World.Loops[at.Loop].Type.Boss
+	case t.Card != nil:
+		g.Card = *t.Card
+		t.Card = nil
+		g.Logf("%s is still here", g.Card.Title)
+	case len(deck.Cards) == 0:
+		g.Card = RandCard(g.Rng)
+	default:
+		g.Card = g.World.pile(deck).draw(g.Rng, deck)
+	}
+}
+
+// setAside puts the open card back when the hero has not kept it: a monster
+// that was skipped, fled from or lost to stays on the tile, bosses stay where
+// they are anyway, and anything else goes on the discard pile.
+func (g *Game) setAside() {
+	at := g.Current().At
+	c := g.Card
+	switch {
+	case c.IsBoss():
+	case c.FightStat() != "":
+		g.World.Loops[at.Loop].Tiles[at.Index].Card = &c
+	default:
+		deck := g.World.Loops[at.Loop].Type.Deck
+		if len(deck.Cards) > 0 {
+			p := g.World.pile(deck)
+			p.Discard = append(p.Discard, c)
+		}
+	}
+}
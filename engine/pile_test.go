@@ -0,0 +1,163 @@
+package engine
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func titles(cs []Card) []string {
+	out := make([]string, len(cs))
+	for i, c := range cs {
+		out[i] = c.Title
+	}
+	slices.Sort(out)
+	return out
+}
+
+func testDeck() Deck {
+	d := Deck{Name: "test"}
+	for _, title := range []string{"a", "b", "c", "d", "e"} {
+		c := NewBuffStrength(1)
+		c.Title = title
+		d.Cards = append(d.Cards, c)
+	}
+	d.Cards[0].Weight = 20 // heavier cards tend to come up sooner
+	return d
+}
+
+func TestPile(t *testing.T) {
+	d := testDeck()
+	tests := []struct {
+		name    string
+		discard []string // cards of the first deal that go back on the discard pile
+		want    []string // the next deal
+	}{
+		{name: "all discarded", discard: titles(d.Cards), want: titles(d.Cards)},
+		{name: "some kept", discard: []string{"a", "c", "e"}, want: []string{"a", "c", "e"}},
+		{name: "all kept", want: titles(d.Cards)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+			p := &Pile{Discard: slices.Clone(d.Cards)}
+			var dealt []Card
+			for range d.Cards {
+				c := p.draw(r, d)
+				dealt = append(dealt, c)
+				if slices.Contains(tt.discard, c.Title) {
+					p.Discard = append(p.Discard, c)
+				}
+			}
+			if got := titles(dealt); !slices.Equal(got, titles(d.Cards)) {
+				t.Fatalf("first deal %v, want every card once", got)
+			}
+			if len(p.Draw) != 0 {
+				t.Fatalf("%d cards left to draw", len(p.Draw))
+			}
+			var next []Card
+			for range tt.want {
+				next = append(next, p.draw(r, d))
+			}
+			if got := titles(next); !slices.Equal(got, tt.want) {
+				t.Fatalf("next deal %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShuffleWeighted(t *testing.T) {
+	d := testDeck()
+	r := rand.New(rand.NewSource(1))
+	first := 0
+	for range 1000 {
+		if shuffleWeighted(r, d.Cards)[0].Title == "a" {
+			first++
+		}
+	}
+	// a weighs 20 of 24, so it should lead about 830 times in 1000
+	if first < 750 || first > 900 {
+		t.Fatalf("the heavy card came first %d times in 1000", first)
+	}
+}
+
+func TestUpcoming(t *testing.T) {
+	d := testDeck()
+	tests := []struct {
+		name string
+		pile *Pile
+		want []string
+	}{
+		{name: "no pile yet", want: titles(d.Cards)},
+		{name: "draw pile", pile: &Pile{Draw: d.Cards[:2], Discard: d.Cards[2:]}, want: []string{"a", "b"}},
+		{name: "reshuffle", pile: &Pile{Discard: d.Cards[3:]}, want: []string{"d", "e"}},
+		{name: "fresh copy", pile: &Pile{}, want: titles(d.Cards)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := World{}
+			if tt.pile != nil {
+				w.Piles = map[string]*Pile{d.Name: tt.pile}
+			}
+			if got := titles(w.Upcoming(d)); !slices.Equal(got, tt.want) {
+				t.Fatalf("upcoming %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetAsideDiscards(t *testing.T) {
+	g := NewGame(1, 1)
+	deck := g.World.Loops[0].Type.Deck
+	g.Card = NewBuffStrength(1)
+	g.Card.Title = "set aside"
+	g.setAside()
+	p := g.World.Piles[deck.Name]
+	if p == nil || len(p.Discard) == 0 || p.Discard[len(p.Discard)-1].Title != "set aside" {
+		t.Fatal("the card did not go on the discard pile")
+	}
+	n := len(p.Discard)
+	reward := NewPotion(1)
+	g.Card = NewMonsterStrength(9)
+	g.Card.Reward = &reward
+	g.setAside()
+	if len(p.Discard) != n {
+		t.Fatal("a boss went on the discard pile")
+	}
+}
+
+func TestMonstersWait(t *testing.T) {
+	g := NewGame(1, 2)
+	at := g.Current().At
+	monster := NewMonsterStrength(4)
+	monster.Title = "Troll"
+	g.Card, g.CardActive = monster, true
+	discards := len(g.World.pile(g.World.Loops[at.Loop].Type.Deck).Discard)
+	if err := g.Apply(Action{Kind: ActSkipCard}); err != nil {
+		t.Fatal(err)
+	}
+	if c := g.World.WaitingAt(at); c == nil || c.Title != "Troll" {
+		t.Fatalf("waiting on %v: %v, want the troll", at, c)
+	}
+	if n := len(g.World.pile(g.World.Loops[at.Loop].Type.Deck).Discard); n != discards {
+		t.Fatalf("%d discards, want the troll kept off the pile", n)
+	}
+
+	// whoever lands there next meets it, and it leaves the tile with them
+	g.Active = 1
+	g.Players[1].At = at
+	g.Players[1].Strength = 50
+	g.Phase, g.StepsRemaining = PhaseAnimating, 1
+	g.FinishMove()
+	if g.Card.Title != "Troll" || g.World.WaitingAt(at) != nil || g.Log[len(g.Log)-1] != "Hero 2: Troll is still here" {
+		t.Fatalf("dealt %q with %v left waiting, log %q", g.Card.Title, g.World.WaitingAt(at), g.Log)
+	}
+	for _, kind := range []ActionKind{ActConfirmCard, ActAttack} {
+		if err := g.Apply(Action{Kind: kind}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if g.Battle.Outcome != OutcomeWin || g.World.WaitingAt(at) != nil {
+		t.Errorf("outcome %v with %v waiting, want the troll beaten and gone", g.Battle.Outcome, g.World.WaitingAt(at))
+	}
+}
@@ -0,0 +1,276 @@
+package engine
+
+import (
+	"fmt"
+	"image/color"
+)
+
+type Player struct {
+	Name      string
+	Color     color.RGBA
+	At        TileID // current tile
+	Strength  int
+	Magic     int
+	Health    int
+	Gold      int
+	Cards     []Card
+	Equipped  [len(EquipSlots)]Card // worn items, indexed like EquipSlots; an empty slot has no Type
+	Spells    []Card                `json:",omitempty"` // learned spells, at most SpellSlots
+	Followers []Card                `json:",omitempty"` // companions, at most MaxFollowers
+	Statuses  []Status              `json:",omitempty"` // timed effects, at most one of each kind
+	Bot       Personality           `json:",omitempty"` // set for computer-controlled heroes
+	Dead      bool                  `json:",omitempty"` // health ran out; the hero cannot roll until it respawns
+	Won       bool                  `json:",omitempty"` // claimed The Crown; the run is over
+	Stats     RunStats
+}
+
+// RunStats is the hero's record for the run, shown on the defeat and
+// victory screens.
+type RunStats struct {
+	Turns        int // rolls made
+	FightsWon    int
+	FightsLost   int
+	GoldEarned   int
+	GoldSpent    int
+	DeepestTier  int // highest region tier landed in
+	Deaths       int
+	BossesBeaten int
+	KilledBy     string `json:",omitempty"` // title of the card that last killed the hero
+	KilledIn     string `json:",omitempty"` // LoopType.Name of where that happened
+}
+
+func NewPlayer(at TileID, strength int, magic int, health int) *Player {
+	return &Player{
+		At:       at,
+		Strength: strength,
+		Magic:    magic,
+		Health:   health,
+		Gold:     10,
+	}
+}
+
+// Put near your other types
+type Outcome int
+
+const (
+	OutcomeNone Outcome = iota
+	OutcomeWin          // beat the monster; card collected
+	OutcomeLoss         // struck down by the monster
+	OutcomeFled         // got away from the fight
+	OutcomeBuff         // took a buff
+	OutcomeItem         // picked up an item
+)
+
+type InteractResult struct {
+	Card    Card
+	Outcome Outcome
+	Kept    bool // the card went to the hero and has left its deck
+
+	// Battle transcript (only meaningful for monster and crown cards)
+	Stat       Stat // what the fight is decided by
+	Bonus      int  // added to the hero's rolls by battle spells
+	MonsterHP  int  // wounds the monster can still take
+	MonsterMax int
+	Rounds     []BattleRound
+
+	FollowerNotes []string // what the hero's followers did, for the log and the UI
+
+	// State deltas (useful for logging)
+	HPBefore    int
+	HPAfter     int
+	StrBefore   int
+	StrAfter    int
+	MagicBefore int
+	MagicAfter  int
+	GoldBefore  int
+	GoldAfter   int
+	Died        bool // the fight took the hero's last point of health
+
+	// Friendly summary you can show in the UI
+	Message string
+}
+
+// Interact resolves the hero's encounter with a card that is not fought;
+// fights are played out round by round by the Game.
+func (p *Player) Interact(card *Card) InteractResult {
+	res := InteractResult{
+		Card:        *card,
+		HPBefore:    p.Health,
+		HPAfter:     p.Health,
+		StrBefore:   p.Strength,
+		StrAfter:    p.Strength,
+		MagicBefore: p.Magic,
+		MagicAfter:  p.Magic,
+		GoldBefore:  p.Gold,
+		GoldAfter:   p.Gold,
+		Outcome:     OutcomeNone,
+	}
+
+	switch card.Type {
+	case SpellType:
+		res.Outcome = OutcomeItem
+		if p.learnSpell(*card) {
+			res.Kept = true
+			res.Message = fmt.Sprintf("You learn %s. (%s, cast %s)", card.Title, card.Spell.Effect(), card.Spell.When())
+		} else {
+			res.Message = fmt.Sprintf("Every spell slot is full; %s fades from your mind.", card.Title)
+		}
+
+	case TalismanType:
+		p.Cards = append(p.Cards, *card)
+		res.Outcome = OutcomeItem
+		res.Kept = true
+		res.Message = "You take the Talisman. The way into The Crown is open to you."
+
+	case PotionType:
+		res.Outcome = OutcomeItem
+		if why := p.Fits(*card); why != "" {
+			res.Message = fmt.Sprintf("You leave %s behind: %s.", card.Title, why)
+			break
+		}
+		p.Cards = append(p.Cards, *card)
+		res.Kept = true
+		res.Message = fmt.Sprintf("You pocket %s. (%s)", card.Title, card.PotionEffect())
+
+	case FollowerType:
+		res.Outcome = OutcomeItem
+		if why := p.Fits(*card); why != "" {
+			res.Message = fmt.Sprintf("%s would join you, but %s.", card.Title, why)
+			break
+		}
+		p.Followers = append(p.Followers, *card)
+		res.Kept = true
+		res.Message = fmt.Sprintf("%s joins you! (%s)", card.Title, card.Follower.Effect())
+		res.FollowerNotes = append(res.FollowerNotes, card.Title+" joined")
+
+	case TreasureType:
+		p.Gold += card.Gold
+		p.Stats.GoldEarned += card.Gold
+		res.GoldAfter = p.Gold
+		res.Outcome = OutcomeItem
+		res.Kept = true
+		res.Message = fmt.Sprintf("You find %d gold.", card.Gold)
+		if card.Reward != nil {
+			res.Message += " " + p.claim(*card.Reward)
+		}
+
+	case BuffType:
+		p.Strength += card.Strength
+		p.Magic += card.Magic
+		res.StrAfter = p.Strength
+		res.MagicAfter = p.Magic
+		res.Outcome = OutcomeBuff
+		res.Kept = true
+		p.Cards = append(p.Cards, *card)
+
+		if card.Strength > 0 && card.Magic > 0 {
+			res.Message = fmt.Sprintf("You feel empowered! (+%d Strength, +%d Magic)", card.Strength, card.Magic)
+		} else if card.Strength > 0 {
+			res.Message = fmt.Sprintf("You feel stronger! (+%d Strength)", card.Strength)
+		} else if card.Magic > 0 {
+			res.Message = fmt.Sprintf("You feel more magical! (+%d Magic)", card.Magic)
+		}
+	}
+
+	return res
+}
+
+// Stat is the hero's value for s, worn items and curses included.
+func (p *Player) Stat(s Stat) int {
+	switch s {
+	case StatStrength:
+		return max(p.Strength+p.GearBonus(s)-p.StatusPower(StatusCurse), 0)
+	case StatMagic:
+		return p.Magic + p.GearBonus(s)
+	case StatBoth:
+		return p.Stat(StatStrength) + p.Stat(StatMagic)
+	}
+	return 0
+}
+
+// HasTalisman reports whether the hero may enter The Crown.
+func (p *Player) HasTalisman() bool {
+	for _, c := range p.Cards {
+		if c.Type == TalismanType {
+			return true
+		}
+	}
+	return false
+}
+
+// Helper functions for inventory system
+func (p *Player) GetMonsterStrengthTotal() int {
+	total := 0
+	for _, card := range p.Cards {
+		if card.Type == MonsterType {
+			total += card.Strength
+		}
+	}
+	return total
+}
+
+func (p *Player) GetMagicMonsterTotal() int {
+	total := 0
+	for _, card := range p.Cards {
+		if card.Type == MagicMonsterType {
+			total += card.Magic
+		}
+	}
+	return total
+}
+
+func (p *Player) ExchangeMonsterStrength() bool {
+	needed := 7
+	if p.GetMonsterStrengthTotal() < needed {
+		return false
+	}
+
+	// Remove monster cards with total strength >= 7
+	remaining := needed
+	newCards := []Card{}
+
+	for _, card := range p.Cards {
+		if card.Type == MonsterType && remaining > 0 {
+			// Consume this monster card to fulfill the exchange
+			remaining -= card.Strength
+			if remaining <= 0 {
+				remaining = 0 // We've consumed enough
+			}
+			// Card is consumed, don't add to newCards
+		} else {
+			newCards = append(newCards, card)
+		}
+	}
+
+	p.Cards = newCards
+	p.Strength++
+	return true
+}
+
+func (p *Player) ExchangeMagicMonster() bool {
+	needed := 7
+	if p.GetMagicMonsterTotal() < needed {
+		return false
+	}
+
+	// Remove magic monster cards with total magic >= 7
+	remaining := needed
+	newCards := []Card{}
+
+	for _, card := range p.Cards {
+		if card.Type == MagicMonsterType && remaining > 0 {
+			// Consume this magic monster card to fulfill the exchange
+			remaining -= card.Magic
+			if remaining <= 0 {
+				remaining = 0 // We've consumed enough
+			}
+			// Card is consumed, don't add to newCards
+		} else {
+			newCards = append(newCards, card)
+		}
+	}
+
+	p.Cards = newCards
+	p.Magic++
+	return true
+}
@@ -0,0 +1,118 @@
+package engine
+
+import "testing"
+
+func TestInteract(t *testing.T) {
+	sword := Card{Type: ShopItemType, Title: "Sword", Strength: 2, Slot: Weapon}
+	fullPack := func(p *Player) {
+		for p.PackRoom() > 0 {
+			p.Cards = append(p.Cards, NewPotion(1))
+		}
+	}
+	tests := []struct {
+		name  string
+		setup func(p *Player)
+		card  Card
+		want  Outcome
+		kept  bool
+		check func(t *testing.T, p *Player)
+	}{
+		{
+			name: "strength buff", card: NewBuffStrength(2), want: OutcomeBuff, kept: true,
+			check: func(t *testing.T, p *Player) {
+				if p.Strength != 5 || p.Magic != 3 || len(p.Cards) != 1 {
+					t.Errorf("strength %d, magic %d, %d cards; want 5, 3, 1", p.Strength, p.Magic, len(p.Cards))
+				}
+			},
+		},
+		{
+			name: "magic buff", card: NewBuffMagic(1), want: OutcomeBuff, kept: true,
+			check: func(t *testing.T, p *Player) {
+				if p.Strength != 3 || p.Magic != 4 {
+					t.Errorf("strength %d, magic %d; want 3, 4", p.Strength, p.Magic)
+				}
+			},
+		},
+		{
+			name: "talisman", card: NewTalisman(), want: OutcomeItem, kept: true,
+			check: func(t *testing.T, p *Player) {
+				if !p.HasTalisman() {
+					t.Error("the hero has no talisman")
+				}
+			},
+		},
+		{
+			name: "potion", card: NewPotion(2), want: OutcomeItem, kept: true,
+			check: func(t *testing.T, p *Player) {
+				if len(p.Cards) != 1 {
+					t.Errorf("%d cards, want 1", len(p.Cards))
+				}
+			},
+		},
+		{
+			name: "potion with a full pack", setup: fullPack, card: NewPotion(2), want: OutcomeItem,
+			check: func(t *testing.T, p *Player) {
+				if p.PackRoom() != 0 || p.PackItems() != PackSize {
+					t.Errorf("pack holds %d of %d", p.PackItems(), p.PackCapacity())
+				}
+			},
+		},
+		{
+			name: "spell", card: NewSpell(SpellBattle, 1), want: OutcomeItem, kept: true,
+			check: func(t *testing.T, p *Player) {
+				if len(p.Spells) != 1 {
+					t.Errorf("%d spells, want 1", len(p.Spells))
+				}
+			},
+		},
+		{
+			name: "spell with every slot taken", card: NewSpell(SpellStride, 1), want: OutcomeItem,
+			setup: func(p *Player) { p.Spells = []Card{NewSpell(SpellBattle, 1)} },
+			check: func(t *testing.T, p *Player) {
+				if len(p.Spells) != 1 || p.Spells[0].Spell.Kind != SpellBattle {
+					t.Errorf("spells %v, want only the battle spell", p.Spells)
+				}
+			},
+		},
+		{
+			name: "follower", card: NewFollower(FollowerFighter, 1), want: OutcomeItem, kept: true,
+			check: func(t *testing.T, p *Player) {
+				if len(p.Followers) != 1 {
+					t.Errorf("%d followers, want 1", len(p.Followers))
+				}
+			},
+		},
+		{
+			name: "treasure", card: NewTreasure("Purse", 5, nil), want: OutcomeItem, kept: true,
+			check: func(t *testing.T, p *Player) {
+				if p.Gold != 15 || p.Stats.GoldEarned != 5 {
+					t.Errorf("gold %d, earned %d; want 15, 5", p.Gold, p.Stats.GoldEarned)
+				}
+			},
+		},
+		{
+			name: "treasure with gear", card: NewTreasure("Chest", 0, &sword), want: OutcomeItem, kept: true,
+			check: func(t *testing.T, p *Player) {
+				if c, ok := p.Worn(Weapon); !ok || c.Title != "Sword" {
+					t.Errorf("wielding %v, want the sword", c)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPlayer(TileID{}, 3, 3, StartHealth)
+			if tt.setup != nil {
+				tt.setup(p)
+			}
+			res := p.Interact(&tt.card)
+			if res.Outcome != tt.want || res.Kept != tt.kept {
+				t.Errorf("outcome %v, kept %v; want %v, %v", res.Outcome, res.Kept, tt.want, tt.kept)
+			}
+			if res.Message == "" {
+				t.Error("no message")
+			}
+			tt.check(t, p)
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"io/fs"
+	"math/rand"
+	"path"
+	"slices"
+)
+
+// RegionsFile lists the regions worlds are built from:
+//
+//	{
+//	  "regions": [
+//	    {
+//	      "name": "Outer Fields", "color": "#78b464", "deck": "deck1", "tier": 1,
+//	      "shopkeepers": ["mystic", "ogre"], "weight": 1, "minSize": 8, "maxSize": 27
+//	    }
+//	  ]
+//	}
+//
+// deck names an entry in DecksFile. The first loops of a world walk up the
+// tiers, one per tier from 1 to starterTiers; after that regions are picked
+// by weight, so a weight of 0 keeps a region to its starter slot. Shops only
+// open in a region for the listed keepers, none if the list is empty. Loops
+// get minSize..maxSize tiles, rounded up to an even number.
+//
+// Any region but the crown may add a "boss" and the "reward" for beating
+// it, both cards as in DecksFile. The boss is a monster or magicMonster
+// stronger than anything in the region's deck; the reward is anything that
+// is not fought, shopItem included:
+//
+//	"boss": {"type": "monster", "strength": 7, "hp": 3, "title": "Bandit Chief"},
+//	"reward": {"type": "shopItem", "strength": 2, "slot": "weapon", "title": "Chief's Cutlass"}
+//
+// Exactly one region is marked "crown": true. It is never dealt like the
+// others; instead every world turns one loop near its middle into The Crown,
+// which only heroes carrying a Talisman can enter. Its deck holds the final
+// encounter.
+const RegionsFile = "regions.json"
+
+// starterTiers is how many of the first loops are dealt in tier order.
+const starterTiers = 3
+
+// Region is the template for the loops of one kind.
+type Region struct {
+	Name        string
+	Color       color.RGBA
+	Deck        string
+	Tier        int
+	Shopkeepers []int // KeeperTypes allowed to open shops here
+	Weight      int
+	MinSize     int
+	MaxSize     int
+	Crown       bool
+	Boss        Card // no Type if the region has no boss
+}
+
+func (rg Region) loopType() LoopType {
+	return LoopType{Name: rg.Name, Color: rg.Color, Deck: deckNamed(rg.Deck), Tier: rg.Tier, Crown: rg.Crown, Boss: rg.Boss}
+}
+
+// pickRegion deals the region for the i-th loop of a world.
+func pickRegion(r *rand.Rand, i int) Region {
+	all := content.Regions
+	var pool []Region
+	if i < starterTiers {
+		for _, rg := range all {
+			if rg.Tier == i+1 && !rg.Crown {
+				pool = append(pool, rg)
+			}
+		}
+	}
+	starter := len(pool) > 0
+	if !starter {
+		for _, rg := range all {
+			if rg.Weight > 0 && !rg.Crown {
+				pool = append(pool, rg)
+			}
+		}
+	}
+	total := 0
+	for _, rg := range pool {
+		total += regionWeight(rg, starter)
+	}
+	n := r.Intn(total)
+	for _, rg := range pool {
+		if n -= regionWeight(rg, starter); n < 0 {
+			return rg
+		}
+	}
+	return pool[len(pool)-1]
+}
+
+// regionWeight lets a weight-0 region still fill its starter slot.
+func regionWeight(rg Region, starter bool) int {
+	if starter {
+		return max(rg.Weight, 1)
+	}
+	return rg.Weight
+}
+
+type regionJSON struct {
+	Name        string    `json:"name"`
+	Color       string    `json:"color"`
+	Deck        string    `json:"deck"`
+	Tier        int       `json:"tier"`
+	Shopkeepers []string  `json:"shopkeepers"`
+	Weight      int       `json:"weight"`
+	MinSize     int       `json:"minSize"`
+	MaxSize     int       `json:"maxSize"`
+	Crown       bool      `json:"crown"`
+	Boss        *cardJSON `json:"boss"`
+	Reward      *cardJSON `json:"reward"`
+}
+
+// Loops smaller than 4 tiles cannot be laid out as a rectangle; much bigger
+// ones stop fitting next to each other.
+const (
+	minLoopSize = 4
+	maxLoopSize = 60
+)
+
+func loadRegions(fsys fs.FS, dir string, decks map[string]Deck) ([]Region, error) {
+	data, err := fs.ReadFile(fsys, RegionsFile)
+	if err != nil {
+		return nil, err
+	}
+	f := &dataFile{name: path.Join(dir, RegionsFile), data: data}
+	var out []Region
+	err = f.object(f.dec(), func(dec *json.Decoder, key string, off int64) error {
+		if key != "regions" {
+			return f.errorf(off, "unknown section %q (want regions)", key)
+		}
+		return f.array(dec, func(off int64) error {
+			var rj regionJSON
+			if err := dec.Decode(&rj); err != nil {
+				return f.wrap(off, err)
+			}
+			rg, err := rj.region(decks)
+			if err != nil {
+				return f.errorf(off, "%v", err)
+			}
+			if i := slices.IndexFunc(out, func(o Region) bool { return o.Name == rg.Name }); i >= 0 {
+				return f.errorf(off, "region %q is defined twice", rg.Name)
+			}
+			out = append(out, rg)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !slices.ContainsFunc(out, func(rg Region) bool { return rg.Weight > 0 && !rg.Crown }) {
+		return nil, fmt.Errorf("%s: at least one region besides the crown needs a weight above 0", f.name)
+	}
+	crowns := 0
+	for _, rg := range out {
+		if rg.Crown {
+			crowns++
+		}
+	}
+	if crowns != 1 {
+		return nil, fmt.Errorf("%s: exactly one region must be the crown, found %d", f.name, crowns)
+	}
+	return out, nil
+}
+
+// region validates a region entry and fills in its defaults.
+func (rj *regionJSON) region(decks map[string]Deck) (Region, error) {
+	rg := Region{
+		Name:    rj.Name,
+		Deck:    rj.Deck,
+		Tier:    rj.Tier,
+		Weight:  rj.Weight,
+		MinSize: rj.MinSize,
+		MaxSize: rj.MaxSize,
+		Crown:   rj.Crown,
+	}
+	if rg.Name == "" {
+		return rg, errors.New("region has no name")
+	}
+	deck, ok := decks[rg.Deck]
+	if !ok {
+		return rg, fmt.Errorf("region %q: no deck named %q in %s", rg.Name, rg.Deck, DecksFile)
+	}
+	if rg.Crown && !slices.ContainsFunc(deck.Cards, func(c Card) bool { return c.Type == CrownType }) {
+		return rg, fmt.Errorf("region %q: the crown's deck %q has no crown card", rg.Name, rg.Deck)
+	}
+	c, err := parseColor(rj.Color)
+	if err != nil {
+		return rg, fmt.Errorf("region %q: %v", rg.Name, err)
+	}
+	rg.Color = c
+	if rg.Tier < 1 {
+		return rg, fmt.Errorf("region %q: tier must be 1 or more", rg.Name)
+	}
+	if rg.Weight < 0 {
+		return rg, fmt.Errorf("region %q: weight cannot be negative", rg.Name)
+	}
+	if rg.MinSize == 0 && rg.MaxSize == 0 {
+		rg.MinSize, rg.MaxSize = 8, 27
+	}
+	if rg.MinSize < minLoopSize || rg.MaxSize > maxLoopSize || rg.MinSize > rg.MaxSize {
+		return rg, fmt.Errorf("region %q: sizes must satisfy %d <= minSize <= maxSize <= %d", rg.Name, minLoopSize, maxLoopSize)
+	}
+	for _, k := range rj.Shopkeepers {
+		i := slices.Index(ShopKeepers, k)
+		if i < 0 {
+			return rg, fmt.Errorf("region %q: unknown shopkeeper %q (want one of %v)", rg.Name, k, ShopKeepers)
+		}
+		rg.Shopkeepers = append(rg.Shopkeepers, i)
+	}
+	if err := rj.boss(&rg, deck); err != nil {
+		return rg, fmt.Errorf("region %q: %v", rg.Name, err)
+	}
+	return rg, nil
+}
+
+// boss validates the region's boss and reward and sets rg.Boss.
+func (rj *regionJSON) boss(rg *Region, deck Deck) error {
+	switch {
+	case rj.Boss == nil && rj.Reward == nil:
+		return nil
+	case rj.Boss == nil || rj.Reward == nil:
+		return errors.New("a boss and its reward go together")
+	case rg.Crown:
+		return errors.New("the crown has its guardian, not a boss")
+	}
+	boss, err := rj.Boss.card(false)
+	if err != nil {
+		return fmt.Errorf("boss: %v", err)
+	}
+	if boss.Type != MonsterType && boss.Type != MagicMonsterType {
+		return errors.New("boss: a boss is a monster or magicMonster")
+	}
+	for _, c := range deck.Cards {
+		if c.FightStat() != "" && c.Power() >= boss.Power() {
+			return fmt.Errorf("boss: must be stronger than everything in deck %q, which has a %d", rg.Deck, c.Power())
+		}
+	}
+	// a reward may be worn like shop stock or be anything else a deck deals
+	reward, err := rj.Reward.card(rj.Reward.Type == ShopItemType)
+	if err != nil {
+		return fmt.Errorf("reward: %v", err)
+	}
+	if reward.FightStat() != "" {
+		return errors.New("reward: cannot be fought")
+	}
+	boss.Reward = &reward
+	rg.Boss = boss
+	return nil
+}
+
+// parseColor reads "#rrggbb" or "#rrggbbaa".
+func parseColor(s string) (color.RGBA, error) {
+	var r, g, b, a uint8 = 0, 0, 0, 255
+	var err error
+	switch len(s) {
+	case 7:
+		_, err = fmt.Sscanf(s, "#%02x%02x%02x", &r, &g, &b)
+	case 9:
+		_, err = fmt.Sscanf(s, "#%02x%02x%02x%02x", &r, &g, &b, &a)
+	default:
+		err = errors.New("bad length")
+	}
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("color %q is not #rrggbb", s)
+	}
+	return rgba(r, g, b, a), nil
+}
@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadRegions(t *testing.T) {
+	fields := `"name": "Woods", "color": "#508c46", "deck": "deck1", "tier": 1`
+	crown := `{"name": "Top", "color": "#e6be3c", "deck": "crown", "tier": 7, "crown": true}`
+	tests := []struct {
+		name    string
+		regions string
+		err     string // part of the error, "" if it loads
+	}{
+		{name: "fine", regions: `{"regions": [{` + fields + `, "weight": 2, "shopkeepers": ["ogre", "pig"]}, ` + crown + `]}`},
+		{name: "no crown", regions: `{"regions": [{` + fields + `, "weight": 1}]}`, err: "exactly one region must be the crown, found 0"},
+		{name: "two crowns", regions: `{"regions": [{` + fields + `, "weight": 1}, ` + crown + `, ` + strings.Replace(crown, "Top", "Peak", 1) + `]}`,
+			err: "found 2"},
+		{name: "nothing to deal", regions: `{"regions": [{` + fields + `}, ` + crown + `]}`, err: "needs a weight above 0"},
+		{name: "twice", regions: `{"regions": [{` + fields + `, "weight": 1}, {` + fields + `}, ` + crown + `]}`, err: `region "Woods" is defined twice`},
+		{name: "no name", regions: `{"regions": [{"deck": "deck1", "tier": 1}]}`, err: "region has no name"},
+		{name: "unknown deck", regions: `{"regions": [{"name": "Woods", "deck": "deck99", "tier": 1}]}`, err: `no deck named "deck99"`},
+		{name: "crown without a guardian", regions: `{"regions": [{"name": "Top", "deck": "deck1", "tier": 7, "crown": true}]}`, err: "has no crown card"},
+		{name: "bad colour", regions: `{"regions": [{"name": "Woods", "color": "green", "deck": "deck1", "tier": 1}]}`, err: `color "green" is not #rrggbb`},
+		{name: "no tier", regions: `{"regions": [{"name": "Woods", "color": "#508c46", "deck": "deck1"}]}`, err: "tier must be 1 or more"},
+		{name: "sizes", regions: `{"regions": [{` + fields + `, "minSize": 10, "maxSize": 6}]}`, err: "minSize <= maxSize"},
+		{name: "shopkeeper", regions: `{"regions": [{` + fields + `, "shopkeepers": ["elf"]}]}`, err: `unknown shopkeeper "elf"`},
+		{name: "line", regions: "{\"regions\": [\n  " + crown + ",\n  {\"name\": \"Woods\"}\n]}", err: "test/regions.json:3: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := loadRegions(fstest.MapFS{RegionsFile: {Data: []byte(tt.regions)}}, "test", content.Decks)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got %v, want an error about %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			woods := out[0]
+			if woods.MinSize != 8 || woods.MaxSize != 27 || len(woods.Shopkeepers) != 2 || woods.Shopkeepers[1] != 3 {
+				t.Errorf("woods loaded as %+v", woods)
+			}
+		})
+	}
+}
+
+func TestRandomRegions(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		regions, counts := RandomRegions(rand.New(rand.NewSource(seed)))
+		if len(regions) < 20 || len(regions) != len(counts) {
+			t.Fatalf("seed %d: %d regions and %d counts", seed, len(regions), len(counts))
+		}
+		for i, rg := range regions {
+			if rg.Crown {
+				t.Errorf("seed %d: loop %d dealt The Crown", seed, i)
+			}
+			if i < starterTiers && rg.Tier != i+1 {
+				t.Errorf("seed %d: loop %d is tier %d, want %d", seed, i, rg.Tier, i+1)
+			}
+			if counts[i] < rg.MinSize || counts[i] > rg.MaxSize {
+				t.Errorf("seed %d: loop %d has %d tiles, outside %s's %d..%d", seed, i, counts[i], rg.Name, rg.MinSize, rg.MaxSize)
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// RecordingVersion is bumped whenever Action or the rules change in a way
+// that makes old recordings play out differently.
+const RecordingVersion = 1
+
+// ReplayDir is where the front-end writes recordings.
+const ReplayDir = "replays"
+
+// Recording is everything needed to reproduce a run: the seed, the data
+// and the decisions made from them.
+type Recording struct {
+	Version int
+	Data    string // DataSum of the decks and regions the run was dealt from
+	Seed    int64
+	Players int
+	Bots    []Personality `json:",omitempty"` // per hero; "" for humans
+	Death   DeathRule
+	Debug   bool `json:",omitempty"`
+	Actions []Action
+}
+
+// Recording captures the run so far.
+func (g *Game) Recording() Recording {
+	rec := Recording{Version: RecordingVersion, Data: DataSum(), Seed: g.Seed, Players: len(g.Players), Death: g.DeathRule, Debug: g.Debug, Actions: g.Actions}
+	for i, p := range g.Players {
+		if p.Bot != "" {
+			if rec.Bots == nil {
+				rec.Bots = make([]Personality, len(g.Players))
+			}
+			rec.Bots[i] = p.Bot
+		}
+	}
+	return rec
+}
+
+// SaveRecording writes the run so far to dir/name and returns the path.
+func (g *Game) SaveRecording(dir, name string) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(g.Recording(), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	return path, os.WriteFile(path, data, 0o644)
+}
+
+// LoadRecording reads a recording written by SaveRecording. It must have
+// been made with the decks and regions in use now.
+func LoadRecording(path string) (Recording, error) {
+	var rec Recording
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return rec, err
+	}
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return rec, fmt.Errorf("%s: %w", path, err)
+	}
+	if rec.Version != RecordingVersion {
+		return rec, fmt.Errorf("%s: recording version %d not supported (want %d)", path, rec.Version, RecordingVersion)
+	}
+	if rec.Data != DataSum() {
+		return rec, fmt.Errorf("%s: recorded with other decks and regions (data %s, loaded %s)", path, rec.Data, DataSum())
+	}
+	return rec, nil
+}
+
+// Replay feeds a recording back through Apply, one action at a time.
+type Replay struct {
+	Actions []Action
+	Next    int // index of the next action to apply
+}
+
+// StartReplay creates the game a recording was made from and a Replay that
+// will drive it. Bots are restored too so they carry on once it is done.
+func StartReplay(rec Recording) (*Game, *Replay) {
+	g := NewGame(rec.Seed, rec.Players)
+	g.DeathRule = rec.Death
+	g.Debug = rec.Debug
+	for i, b := range rec.Bots {
+		if i < len(g.Players) {
+			g.Players[i].Bot = b
+		}
+	}
+	return g, &Replay{Actions: rec.Actions}
+}
+
+func (r *Replay) Done() bool { return r.Next >= len(r.Actions) }
+
+// Step applies the next recorded action if the game is ready for one. It
+// reports whether an action was applied. An error means the recording does
+// not match the rules it is being played against.
+func (r *Replay) Step(g *Game) (bool, error) {
+	if r.Done() || !g.Ready() {
+		return false, nil
+	}
+	a := r.Actions[r.Next]
+	if err := g.Apply(a); err != nil {
+		return false, fmt.Errorf("replay action %d: %w", r.Next, err)
+	}
+	r.Next++
+	return true, nil
+}
+
+// Run plays the rest of the recording without animation.
+func (r *Replay) Run(g *Game) error {
+	for !r.Done() {
+		g.FinishMove()
+		if _, err := r.Step(g); err != nil {
+			return err
+		}
+	}
+	g.FinishMove()
+	return nil
+}
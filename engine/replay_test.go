@@ -0,0 +1,136 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReplay(t *testing.T) {
+	tests := []struct {
+		name  string
+		seed  int64
+		bots  []Personality
+		n     int
+		death DeathRule
+	}{
+		{name: "cautious", seed: 1, bots: []Personality{Cautious}, n: 150},
+		{name: "reckless permadeath", seed: 2, bots: []Personality{Reckless}, n: 150, death: Permadeath},
+		{name: "hot seat", seed: 3, bots: []Personality{Greedy, Cautious}, n: 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := botGame(tt.seed, tt.bots...)
+			if tt.death != "" {
+				g.DeathRule = tt.death
+			}
+			playTo(t, g, tt.n)
+
+			path, err := g.SaveRecording(t.TempDir(), "run.json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec, err := LoadRecording(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rec.Actions) != len(g.Actions) {
+				t.Fatalf("%d actions recorded, want %d", len(rec.Actions), len(g.Actions))
+			}
+			replayed, r := StartReplay(rec)
+			if err := r.Run(replayed); err != nil {
+				t.Fatal(err)
+			}
+			sameSave(t, g, replayed)
+		})
+	}
+}
+
+func TestReplayRejects(t *testing.T) {
+	g := botGame(4, Cautious)
+	playTo(t, g, 30)
+	tests := []struct {
+		name   string
+		change func(rec *Recording)
+		load   string // what LoadRecording should complain about
+		run    bool   // Run should fail instead
+	}{
+		{name: "old version", change: func(rec *Recording) { rec.Version-- }, load: "recording version"},
+		{name: "other data", change: func(rec *Recording) { rec.Data = "0123456789abcdef" }, load: "other decks and regions"},
+		{name: "action out of place", change: func(rec *Recording) {
+			rec.Actions = append([]Action{{Kind: ActEndTurn}}, rec.Actions...)
+		}, run: true},
+		{name: "landing spot off the roll", change: func(rec *Recording) {
+			for i, a := range rec.Actions {
+				if a.Kind == ActChooseDest {
+					rec.Actions[i].Tile = TileID{Loop: -1}
+					return
+				}
+			}
+		}, run: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := g.Recording()
+			rec.Actions = append([]Action(nil), rec.Actions...)
+			tt.change(&rec)
+			data, err := json.Marshal(rec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(t.TempDir(), "run.json")
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			rec, err = LoadRecording(path)
+			if tt.load != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.load) {
+					t.Fatalf("got %v, want an error about %q", err, tt.load)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			replayed, r := StartReplay(rec)
+			if err := r.Run(replayed); !errors.Is(err, ErrIllegalAction) {
+				t.Fatalf("got %v, want an illegal action", err)
+			}
+		})
+	}
+}
+
+func TestDataSum(t *testing.T) {
+	builtin := content
+	t.Cleanup(func() { content = builtin })
+
+	if err := LoadData("data"); err != nil {
+		t.Fatal(err)
+	}
+	if DataSum() != builtin.Sum {
+		t.Fatalf("the files the game ships with sum to %s, built in %s", DataSum(), builtin.Sum)
+	}
+	dir := t.TempDir()
+	for _, name := range []string{DecksFile, RegionsFile} {
+		data, err := os.ReadFile(filepath.Join("data", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name == DecksFile {
+			data = bytes.Replace(data, []byte(`"strength": 2`), []byte(`"strength": 3`), 1)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := LoadData(dir); err != nil {
+		t.Fatal(err)
+	}
+	if DataSum() == builtin.Sum {
+		t.Fatal("edited data has the same sum")
+	}
+}
@@ -0,0 +1,37 @@
+package engine
+
+import "math/rand"
+
+// countingSource wraps the standard source and remembers how many values it
+// has produced, so a saved game can be resumed with the exact same dice:
+// re-seed, then skip the values that were already used.
+type countingSource struct {
+	src   rand.Source64
+	Draws uint64
+}
+
+func newCountingSource(seed int64) *countingSource {
+	return &countingSource{src: rand.NewSource(seed).(rand.Source64)}
+}
+
+func (s *countingSource) Int63() int64 {
+	s.Draws++
+	return s.src.Int63()
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.Draws++
+	return s.src.Uint64()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.Draws = 0
+}
+
+// skip advances the source by n values without handing them out.
+func (s *countingSource) skip(n uint64) {
+	for ; n > 0; n-- {
+		s.Uint64()
+	}
+}
@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestCountingSourceSkip(t *testing.T) {
+	for _, n := range []int{0, 1, 7, 100} {
+		a := newCountingSource(42)
+		r := rand.New(a)
+		for range n {
+			r.Intn(6) // whatever mix of values rand asks for
+			r.Float64()
+		}
+		b := newCountingSource(42)
+		b.skip(a.Draws)
+		if b.Draws != a.Draws {
+			t.Fatalf("skipped to %d draws, want %d", b.Draws, a.Draws)
+		}
+		for i := range 10 {
+			if x, y := a.Uint64(), b.Uint64(); x != y {
+				t.Fatalf("after %d rolls, value %d: %d, resumed %d", n, i, x, y)
+			}
+		}
+	}
+}
+
+func TestSeededDice(t *testing.T) {
+	rolls := func(seed int64) []int {
+		g := NewGame(seed, 2)
+		var out []int
+		for range 20 {
+			g.roll()
+			out = append(out, g.LastRoll)
+			g.Phase = PhaseIdle
+			g.endTurn()
+		}
+		return out
+	}
+	a, b, c := rolls(5), rolls(5), rolls(6)
+	if !slices.Equal(a, b) {
+		t.Fatalf("seed 5 rolled %v, then %v", a, b)
+	}
+	if slices.Equal(a, c) {
+		t.Fatalf("seeds 5 and 6 both rolled %v", a)
+	}
+}
@@ -0,0 +1,271 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SaveVersion is bumped whenever the on-disk layout changes. Older files are
+// rejected rather than half-loaded.
+const SaveVersion = 1
+
+// SaveDir is where the front-end keeps its save files.
+const SaveDir = "saves"
+
+// saveFile is the versioned on-disk form of a game in progress. Only state
+// that survives between turns is stored; a game can be saved while idle.
+type saveFile struct {
+	Version int
+	Saved   time.Time
+
+	Seed  int64
+	Draws uint64 // RNG values consumed so far
+
+	Turn     int
+	Round    int
+	LastRoll int
+	Log      []string
+
+	Players   []Player
+	Active    int
+	Moved     bool
+	DeathRule DeathRule
+	Debug     bool `json:",omitempty"`
+	World     *World
+	Actions   []Action // the run so far, so recordings survive a save/load
+}
+
+// worldJSON replaces the *ShopType pointers on tiles with indexes into a
+// shared shop table, so tiles that point at the same shop still do after a
+// round trip.
+type worldJSON struct {
+	Loops []loopJSON
+	Shops []ShopType
+	Piles map[string]*Pile `json:",omitempty"`
+}
+
+type loopJSON struct {
+	Type  LoopType
+	Tiles []tileJSON
+}
+
+type tileJSON struct {
+	Pos        Vec2
+	Next, Prev TileID
+	Links      []TileID `json:",omitempty"`
+	Bridge     bool     `json:",omitempty"`
+	Shop       bool     `json:",omitempty"`
+	ShopRef    int      `json:",omitempty"` // 1-based index into Shops, 0 = none
+	Boss       bool     `json:",omitempty"`
+	Card       *Card    `json:",omitempty"`
+}
+
+func (w World) MarshalJSON() ([]byte, error) {
+	out := worldJSON{Loops: make([]loopJSON, len(w.Loops)), Piles: w.Piles}
+	refs := map[*ShopType]int{}
+	for li, loop := range w.Loops {
+		lj := loopJSON{Type: loop.Type, Tiles: make([]tileJSON, len(loop.Tiles))}
+		for ti, t := range loop.Tiles {
+			tj := tileJSON{Pos: t.Pos, Next: t.Next, Prev: t.Prev, Links: t.Links, Bridge: t.Bridge, Shop: t.Shop, Boss: t.Boss, Card: t.Card}
+			if t.ShopData != nil {
+				ref, ok := refs[t.ShopData]
+				if !ok {
+					out.Shops = append(out.Shops, *t.ShopData)
+					ref = len(out.Shops)
+					refs[t.ShopData] = ref
+				}
+				tj.ShopRef = ref
+			}
+			lj.Tiles[ti] = tj
+		}
+		out.Loops[li] = lj
+	}
+	return json.Marshal(out)
+}
+
+func (w *World) UnmarshalJSON(data []byte) error {
+	var in worldJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	shops := make([]*ShopType, len(in.Shops))
+	for i := range in.Shops {
+		shops[i] = &in.Shops[i]
+	}
+	w.Loops = make([]Loop, len(in.Loops))
+	w.Piles = in.Piles
+	for li, lj := range in.Loops {
+		loop := Loop{Type: lj.Type, Tiles: make([]Tile, len(lj.Tiles))}
+		for ti, tj := range lj.Tiles {
+			t := Tile{Pos: tj.Pos, Next: tj.Next, Prev: tj.Prev, Links: tj.Links, Bridge: tj.Bridge, Shop: tj.Shop, Boss: tj.Boss, Card: tj.Card}
+			if tj.ShopRef != 0 {
+				if tj.ShopRef < 0 || tj.ShopRef > len(shops) {
+					return fmt.Errorf("loop %d tile %d: shop %d out of range", li, ti, tj.ShopRef)
+				}
+				t.ShopData = shops[tj.ShopRef-1]
+			}
+			loop.Tiles[ti] = t
+		}
+		w.Loops[li] = loop
+	}
+	return nil
+}
+
+// CanSave reports whether the game is between moves. Saving mid-roll or with
+// a card on the table is not supported.
+func (g *Game) CanSave() bool {
+	return g.Phase == PhaseIdle && !g.CardActive && !g.ShopActive
+}
+
+// Save writes the game to w in the current save format.
+func (g *Game) Save(w io.Writer) error {
+	if !g.CanSave() {
+		return errors.New("finish the current move before saving")
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(saveFile{
+		Version:   SaveVersion,
+		Saved:     time.Now(),
+		Seed:      g.Seed,
+		Draws:     g.src.Draws,
+		Turn:      g.Turn,
+		Round:     g.Round,
+		LastRoll:  g.LastRoll,
+		Log:       g.Log,
+		Players:   g.Players,
+		Active:    g.Active,
+		Moved:     g.Moved,
+		DeathRule: g.DeathRule,
+		Debug:     g.Debug,
+		World:     g.World,
+		Actions:   g.Actions,
+	})
+}
+
+// Load reads a game written by Save.
+func Load(r io.Reader) (*Game, error) {
+	var sf saveFile
+	if err := json.NewDecoder(r).Decode(&sf); err != nil {
+		return nil, err
+	}
+	if sf.Version != SaveVersion {
+		return nil, fmt.Errorf("save version %d not supported (want %d)", sf.Version, SaveVersion)
+	}
+	if sf.World == nil || len(sf.World.Loops) == 0 {
+		return nil, errors.New("save has no world")
+	}
+	if len(sf.Players) == 0 || sf.Active < 0 || sf.Active >= len(sf.Players) {
+		return nil, errors.New("save has no active player")
+	}
+	g := &Game{
+		Seed:      sf.Seed,
+		Turn:      sf.Turn,
+		Round:     sf.Round,
+		LastRoll:  sf.LastRoll,
+		Log:       sf.Log,
+		Players:   sf.Players,
+		Active:    sf.Active,
+		Moved:     sf.Moved,
+		DeathRule: sf.DeathRule,
+		Debug:     sf.Debug,
+		World:     sf.World,
+		Actions:   sf.Actions,
+	}
+	g.seedRng(sf.Seed, sf.Draws)
+	return g, nil
+}
+
+// SaveFile saves the game under dir, named after its seed and turn, and
+// returns the path written.
+func (g *Game) SaveFile(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("seed%d-turn%d.json", g.Seed, g.Turn))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := g.Save(f); err != nil {
+		f.Close()
+		os.Remove(path)
+		return "", err
+	}
+	return path, f.Close()
+}
+
+// LoadFile loads the save at path.
+func LoadFile(path string) (*Game, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	g, err := Load(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return g, nil
+}
+
+// SaveInfo is the summary shown for a save on the load screen.
+type SaveInfo struct {
+	Path  string
+	Saved time.Time
+	Seed  int64
+	Turn  int
+	Err   error // set when the file could not be read; it is listed but not loadable
+}
+
+// ListSaves returns the saves in dir, newest first. A missing directory just
+// means there are no saves yet.
+func ListSaves(dir string) ([]SaveInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []SaveInfo
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		info := SaveInfo{Path: filepath.Join(dir, e.Name())}
+		info.Err = readSaveHeader(&info)
+		out = append(out, info)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Saved.After(out[j].Saved) })
+	return out, nil
+}
+
+func readSaveHeader(info *SaveInfo) error {
+	f, err := os.Open(info.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var hdr struct {
+		Version int
+		Saved   time.Time
+		Seed    int64
+		Turn    int
+	}
+	if err := json.NewDecoder(f).Decode(&hdr); err != nil {
+		return err
+	}
+	info.Saved, info.Seed, info.Turn = hdr.Saved, hdr.Seed, hdr.Turn
+	if hdr.Version != SaveVersion {
+		return fmt.Errorf("version %d not supported", hdr.Version)
+	}
+	return nil
+}
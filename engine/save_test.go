@@ -0,0 +1,151 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// botGame is a game between the given personalities.
+func botGame(seed int64, bots ...Personality) *Game {
+	g := NewGame(seed, len(bots))
+	for i, b := range bots {
+		g.Players[i].Bot = b
+	}
+	return g
+}
+
+// playTo lets the bots take at least n actions and stops at the next point
+// the game can be saved.
+func playTo(t *testing.T, g *Game, n int) {
+	t.Helper()
+	for i := 0; i < n || !g.CanSave(); i++ {
+		if i > n+500 {
+			t.Fatalf("no point to save at after %d actions", i)
+		}
+		if err := g.RunBots(1); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// saved is the game's save, field by field, without the time it was made.
+func saved(t *testing.T, g *Game) map[string]json.RawMessage {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := g.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	delete(out, "Saved")
+	return out
+}
+
+// sameSave fails unless a and b would write the same save.
+func sameSave(t *testing.T, a, b *Game) {
+	t.Helper()
+	sa, sb := saved(t, a), saved(t, b)
+	for k, v := range sa {
+		if !bytes.Equal(sb[k], v) {
+			t.Fatalf("%s differs:\n%.300s\n%.300s", k, v, sb[k])
+		}
+	}
+}
+
+func TestSaveRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		seed int64
+		bots []Personality
+		n    int
+	}{
+		{name: "fresh", seed: 1, bots: []Personality{Cautious}},
+		{name: "solo", seed: 2, bots: []Personality{Greedy}, n: 60},
+		{name: "hot seat", seed: 3, bots: []Personality{Cautious, Reckless, Greedy}, n: 120},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := botGame(tt.seed, tt.bots...)
+			playTo(t, g, tt.n)
+			var buf bytes.Buffer
+			if err := g.Save(&buf); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Load(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sameSave(t, g, got)
+
+			shops := func(g *Game) (n int) {
+				for _, l := range g.World.Loops {
+					for _, tile := range l.Tiles {
+						n += b2i(tile.ShopData != nil)
+					}
+				}
+				return n
+			}
+			if shops(got) != shops(g) {
+				t.Fatalf("%d shops after loading, want %d", shops(got), shops(g))
+			}
+
+			// the loaded game carries on exactly as the original does
+			playTo(t, g, 40)
+			playTo(t, got, 40)
+			sameSave(t, g, got)
+		})
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	g := botGame(5, Cautious)
+	playTo(t, g, 10)
+	tests := []struct {
+		name   string
+		change func(sf map[string]any)
+		want   string
+	}{
+		{name: "old version", change: func(sf map[string]any) { sf["Version"] = SaveVersion - 1 }, want: "save version"},
+		{name: "no world", change: func(sf map[string]any) { delete(sf, "World") }, want: "no world"},
+		{name: "no players", change: func(sf map[string]any) { sf["Players"] = nil }, want: "no active player"},
+		{name: "active out of range", change: func(sf map[string]any) { sf["Active"] = 3 }, want: "no active player"},
+		{name: "bad shop", change: func(sf map[string]any) {
+			w := sf["World"].(map[string]any)
+			w["Shops"] = nil
+		}, want: "out of range"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := g.Save(&buf); err != nil {
+				t.Fatal(err)
+			}
+			var sf map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &sf); err != nil {
+				t.Fatal(err)
+			}
+			tt.change(sf)
+			data, err := json.Marshal(sf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(bytes.NewReader(data)); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got %v, want an error about %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveMidMove(t *testing.T) {
+	g := botGame(1, Cautious)
+	if err := g.Apply(Action{Kind: ActRoll}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Save(&bytes.Buffer{}); err == nil {
+		t.Fatal("saved in the middle of a move")
+	}
+}
@@ -0,0 +1,320 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ScriptedType is a card whose whole effect is its Script, so designers can
+// write new cards without touching the rules.
+const ScriptedType CardType = "scripted"
+
+// Script is a card's effect written out in the deck data as statements
+// separated by semicolons:
+//
+//	gain strength 2; lose gold d6; if roll>=5 then heal 1
+//
+// The ops are gain and lose (strength, magic, gold or health by an amount),
+// heal and hurt (health), move (tiles along the loop), poison and curse
+// (power and turns) and bless (turns). An amount is a number, dN or MdN.
+// A statement may start with "if X then", where X compares roll, strength,
+// magic, gold or health with a number using <, <=, =, >= or >. roll is one
+// d6 thrown as the script starts, shared by all its statements.
+type Script string
+
+// scriptStmt is one compiled statement: an effect, perhaps under a
+// condition.
+type scriptStmt struct {
+	If     *scriptCond
+	Field  string // strength, magic, gold, health, move or status
+	Sign   int    // +1 to gain, -1 to lose
+	Amount dice
+	Status Status
+}
+
+type scriptCond struct {
+	Subject string // roll, strength, magic, gold or health
+	Cmp     string
+	Value   int
+}
+
+// dice is an amount: N when Sides is 0, else N dice of Sides.
+type dice struct{ N, Sides int }
+
+func (d dice) roll(r *rand.Rand) int {
+	if d.Sides == 0 {
+		return d.N
+	}
+	total := 0
+	for range d.N {
+		total += r.Intn(d.Sides) + 1
+	}
+	return total
+}
+
+func (d dice) mean() float64 {
+	if d.Sides == 0 {
+		return float64(d.N)
+	}
+	return float64(d.N) * float64(d.Sides+1) / 2
+}
+
+var (
+	scriptOps      = []string{"gain", "lose", "heal", "hurt", "move", "poison", "curse", "bless"}
+	scriptFields   = []string{"strength", "magic", "gold", "health"}
+	scriptSubjects = []string{"roll", "strength", "magic", "gold", "health"}
+	scriptCmps     = []string{">=", "<=", ">", "<", "="} // longest first, for splitting
+)
+
+// compile parses the script. Its errors name the statement at fault.
+func (s Script) compile() ([]scriptStmt, error) {
+	var out []scriptStmt
+	for i, src := range strings.Split(string(s), ";") {
+		if strings.TrimSpace(src) == "" {
+			continue
+		}
+		st, err := compileStmt(strings.Fields(src))
+		if err != nil {
+			return nil, fmt.Errorf("statement %d (%s): %v", i+1, strings.TrimSpace(src), err)
+		}
+		out = append(out, st)
+	}
+	if len(out) == 0 {
+		return nil, errors.New("the script is empty")
+	}
+	return out, nil
+}
+
+// Statements is the script's statements as written, for the card modal.
+func (s Script) Statements() []string {
+	var out []string
+	for _, src := range strings.Split(string(s), ";") {
+		if src = strings.TrimSpace(src); src != "" {
+			out = append(out, src)
+		}
+	}
+	return out
+}
+
+func compileStmt(words []string) (scriptStmt, error) {
+	var st scriptStmt
+	if len(words) > 0 && words[0] == "if" {
+		then := -1
+		for i, w := range words {
+			if w == "then" {
+				then = i
+				break
+			}
+		}
+		if then < 0 {
+			return st, errors.New("if without then")
+		}
+		c, err := compileCond(strings.Join(words[1:then], ""))
+		if err != nil {
+			return st, err
+		}
+		st.If = &c
+		words = words[then+1:]
+	}
+	if len(words) == 0 {
+		return st, errors.New("no op")
+	}
+	op, args := words[0], words[1:]
+	want := func(n int) error {
+		if len(args) != n {
+			return fmt.Errorf("%s takes %d arguments, not %d", op, n, len(args))
+		}
+		return nil
+	}
+	var err error
+	st.Sign = 1
+	switch op {
+	case "gain", "lose":
+		if err = want(2); err != nil {
+			return st, err
+		}
+		if !slices.Contains(scriptFields, args[0]) {
+			return st, fmt.Errorf("cannot %s %q (want one of %v)", op, args[0], scriptFields)
+		}
+		st.Field = args[0]
+		if op == "lose" {
+			st.Sign = -1
+		}
+		st.Amount, err = parseDice(args[1])
+	case "heal", "hurt", "move":
+		if err = want(1); err != nil {
+			return st, err
+		}
+		st.Field = "health"
+		switch op {
+		case "hurt":
+			st.Sign = -1
+		case "move":
+			st.Field = "move"
+		}
+		st.Amount, err = parseDice(args[0])
+	case "poison", "curse":
+		if err = want(2); err != nil {
+			return st, err
+		}
+		st.Field = "status"
+		st.Status, err = parseStatus(StatusKind(op), args[0], args[1])
+	case "bless":
+		if err = want(1); err != nil {
+			return st, err
+		}
+		st.Field = "status"
+		st.Status, err = parseStatus(StatusBlessing, "0", args[0])
+	default:
+		return st, fmt.Errorf("unknown op %q (want one of %v)", op, scriptOps)
+	}
+	return st, err
+}
+
+func compileCond(s string) (scriptCond, error) {
+	for _, cmp := range scriptCmps {
+		subject, value, ok := strings.Cut(s, cmp)
+		if !ok {
+			continue
+		}
+		if !slices.Contains(scriptSubjects, subject) {
+			return scriptCond{}, fmt.Errorf("cannot test %q (want one of %v)", subject, scriptSubjects)
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return scriptCond{}, fmt.Errorf("%q is not a number", value)
+		}
+		return scriptCond{Subject: subject, Cmp: cmp, Value: n}, nil
+	}
+	return scriptCond{}, fmt.Errorf("condition %q has no comparison (want one of %v)", s, scriptCmps)
+}
+
+// parseDice reads an amount: 3, d6 or 2d6.
+func parseDice(s string) (dice, error) {
+	n, sides, isDice := strings.Cut(s, "d")
+	if !isDice {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 0 {
+			return dice{}, fmt.Errorf("%q is not an amount (want a number, dN or MdN)", s)
+		}
+		return dice{N: v}, nil
+	}
+	d := dice{N: 1}
+	var err error
+	if n != "" {
+		d.N, err = strconv.Atoi(n)
+	}
+	if err == nil {
+		d.Sides, err = strconv.Atoi(sides)
+	}
+	if err != nil || d.N < 1 || d.Sides < 2 {
+		return dice{}, fmt.Errorf("%q is not an amount (want a number, dN or MdN)", s)
+	}
+	return d, nil
+}
+
+func parseStatus(kind StatusKind, power, turns string) (Status, error) {
+	p, err1 := strconv.Atoi(power)
+	t, err2 := strconv.Atoi(turns)
+	if err1 != nil || err2 != nil {
+		return Status{}, fmt.Errorf("%s needs whole numbers", kind)
+	}
+	sj := statusJSON{Kind: kind, Power: p, Turns: t}
+	return sj.status()
+}
+
+// holds reports whether the condition is met for p with the script's roll.
+func (c scriptCond) holds(p *Player, roll int) bool {
+	v := roll
+	switch c.Subject {
+	case "strength":
+		v = p.Stat(StatStrength)
+	case "magic":
+		v = p.Stat(StatMagic)
+	case "gold":
+		v = p.Gold
+	case "health":
+		v = p.Health
+	}
+	switch c.Cmp {
+	case ">=":
+		return v >= c.Value
+	case "<=":
+		return v <= c.Value
+	case ">":
+		return v > c.Value
+	case "<":
+		return v < c.Value
+	}
+	return v == c.Value
+}
+
+// usesRoll reports whether any statement tests the roll.
+func usesRoll(stmts []scriptStmt) bool {
+	for _, st := range stmts {
+		if st.If != nil && st.If.Subject == "roll" {
+			return true
+		}
+	}
+	return false
+}
+
+// effect is the statement as an Effect, n being its amount.
+func (st scriptStmt) effect(n int) Effect {
+	n *= st.Sign
+	switch st.Field {
+	case "strength":
+		return Effect{Strength: n}
+	case "magic":
+		return Effect{Magic: n}
+	case "gold":
+		return Effect{Gold: n}
+	case "health":
+		return Effect{Health: n}
+	case "move":
+		return Effect{Move: n}
+	}
+	return Effect{Status: st.Status}
+}
+
+// runScript plays the open card's script on the current hero and returns
+// what happened, for the card's message. The roll is thrown first, and only
+// if the script tests it; then each statement whose condition holds draws
+// its amount and takes effect, until the hero falls.
+func (g *Game) runScript() string {
+	p, c := g.Current(), g.Card
+	stmts, err := c.Script.compile()
+	if err != nil {
+		// the deck loader has already checked it, so this is a bad save
+		g.Logf("%s: %v", c.Title, err)
+		return ""
+	}
+	var parts []string
+	roll := 0
+	if usesRoll(stmts) {
+		roll = g.Rng.Intn(6) + 1
+		parts = append(parts, fmt.Sprintf("You roll %d", roll))
+	}
+	for _, st := range stmts {
+		if p.Dead {
+			break
+		}
+		if st.If != nil && !st.If.holds(p, roll) {
+			continue
+		}
+		parts = append(parts, g.applyEffect(p, st.effect(st.Amount.roll(g.Rng)), c))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "nothing happens")
+	}
+	msg := strings.Join(parts, "; ")
+	g.Logf("%s: %s", c.Title, msg)
+	if p.Dead {
+		msg += ". You have fallen"
+	}
+	return msg + "."
+}
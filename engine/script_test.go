@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScriptCompile(t *testing.T) {
+	tests := []struct {
+		script Script
+		stmts  int
+		err    string // part of the error, "" if it compiles
+	}{
+		{script: "gain strength 2", stmts: 1},
+		{script: "lose gold d6; heal 1;", stmts: 2},
+		{script: "if roll>=5 then gain magic 2d6", stmts: 1},
+		{script: "if gold < 3 then gain gold 3; poison 1 2; curse 2 1; bless 1; move 2; hurt 1", stmts: 6},
+		{script: " ; ", err: "empty"},
+		{script: "gain luck 1", err: `statement 1 (gain luck 1): cannot gain "luck"`},
+		{script: "heal 1; gain strength", err: "statement 2 (gain strength): gain takes 2 arguments, not 1"},
+		{script: "dance 1", err: `unknown op "dance"`},
+		{script: "heal d1", err: `"d1" is not an amount`},
+		{script: "lose gold -2", err: `"-2" is not an amount`},
+		{script: "if roll>=5 gain gold 1", err: "if without then"},
+		{script: "if luck>2 then heal 1", err: `cannot test "luck"`},
+		{script: "if roll then heal 1", err: "has no comparison"},
+		{script: "if roll>=x then heal 1", err: `"x" is not a number`},
+		{script: "bless 1 2", err: "bless takes 1 arguments, not 2"},
+		{script: "poison 0 2", err: "a poison status needs power"},
+	}
+	for _, tt := range tests {
+		stmts, err := tt.script.compile()
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%q: %v", tt.script, err)
+		case tt.err == "" && len(stmts) != tt.stmts:
+			t.Errorf("%q: %d statements, want %d", tt.script, len(stmts), tt.stmts)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%q: got %v, want an error about %q", tt.script, err, tt.err)
+		}
+	}
+}
+
+func TestRunScript(t *testing.T) {
+	tests := []struct {
+		name   string
+		script Script
+		setup  func(p *Player)
+		check  func(p *Player) bool
+		want   string // part of the message
+	}{
+		{name: "gain and lose", script: "gain strength 2; lose gold 4",
+			check: func(p *Player) bool { return p.Strength == 5 && p.Gold == 6 }, want: "+2 Strength; -4 gold"},
+		{name: "gold cannot go negative", script: "lose gold 50",
+			check: func(p *Player) bool { return p.Gold == 0 }},
+		{name: "healing is capped", script: "heal 9", setup: func(p *Player) { p.Health = StartHealth - 1 },
+			check: func(p *Player) bool { return p.Health == StartHealth }},
+		{name: "condition holds", script: "if gold>=10 then gain magic 1",
+			check: func(p *Player) bool { return p.Magic == 4 }},
+		{name: "condition fails", script: "if health<2 then gain magic 1",
+			check: func(p *Player) bool { return p.Magic == 3 }, want: "nothing happens"},
+		{name: "statuses", script: "poison 1 2; bless 1",
+			check: func(p *Player) bool { return p.HasStatus(StatusPoison) && p.HasStatus(StatusBlessing) }},
+		{name: "stops when the hero falls", script: "hurt 99; gain gold 5",
+			check: func(p *Player) bool { return p.Dead && p.Gold == 10 }, want: "You have fallen"},
+		{name: "rolls only when tested", script: "if roll>=1 then gain gold 1",
+			check: func(p *Player) bool { return p.Gold == 11 }, want: "You roll"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGame(1, 1)
+			p := g.Current()
+			if tt.setup != nil {
+				tt.setup(p)
+			}
+			g.Card = Card{Type: ScriptedType, Title: "Test", Script: tt.script}
+			draws := g.src.Draws
+			msg := g.runScript()
+			if !tt.check(p) {
+				t.Errorf("%q left %+v", tt.script, *p)
+			}
+			if !strings.Contains(msg, tt.want) {
+				t.Errorf("message %q, want %q", msg, tt.want)
+			}
+			if rolled := strings.Contains(string(tt.script), "roll"); rolled != (g.src.Draws > draws) {
+				t.Errorf("drew from the RNG: %v, want %v", g.src.Draws > draws, rolled)
+			}
+		})
+	}
+}
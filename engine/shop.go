@@ -0,0 +1,136 @@
+package engine
+
+import "fmt"
+
+// ShopRestockRounds is how long a shop's shelves stay as they are. A hero
+// who walks in that many rounds after they were last filled finds fresh
+// stock.
+const ShopRestockRounds = 10
+
+// AdjacentShop returns the shop linked to the hero's tile, if any.
+func (g *Game) AdjacentShop() *ShopType {
+	cur := g.World.Loops[g.Current().At.Loop].Tiles[g.Current().At.Index]
+	for _, link := range cur.Links {
+		if isShopTile(g.World, link) {
+			shopTile := &g.World.Loops[link.Loop].Tiles[link.Index]
+			if shopTile.ShopData != nil {
+				return shopTile.ShopData
+			}
+		}
+	}
+	return nil
+}
+
+// openShop enters the shop next to the hero. It reports false when there is
+// no shop to enter.
+func (g *Game) openShop() bool {
+	shop := g.AdjacentShop()
+	if shop == nil {
+		return false
+	}
+	if g.Round-shop.Stocked >= ShopRestockRounds {
+		g.restock(shop)
+	}
+	g.InitShop(shop)
+	g.ShopActive = true
+	return true
+}
+
+func (g *Game) closeShop() {
+	g.ShopActive = false
+}
+
+// restock fills every shelf of shop afresh for the current round.
+func (g *Game) restock(shop *ShopType) {
+	for i := range shop.Cards {
+		shop.Cards[i] = RandShopCard(g.Rng, shop.KeeperType, g.Round)
+		shop.Prices[i] = ShopPrice(g.Rng, shop.Cards[i])
+	}
+	shop.Stocked = g.Round
+	if shop.Discovered {
+		g.Logf("%s has new stock", shop.Name)
+	}
+}
+
+// buyShopItem purchases the card in the given shop slot and restocks it.
+func (g *Game) buyShopItem(slot int) error {
+	price := g.ShopPrices[slot]
+	if why := g.Current().Fits(g.ShopCards[slot]); why != "" {
+		return fmt.Errorf("%s: %w", why, ErrIllegalAction)
+	}
+	if g.Current().Gold < price {
+		return fmt.Errorf("need %d gold, have %d: %w", price, g.Current().Gold, ErrIllegalAction)
+	}
+	g.Current().Gold -= price
+	g.Current().Stats.GoldSpent += price
+	purchasedCard := g.ShopCards[slot]
+
+	// Equipment goes on straight away if its slot is free, otherwise into the pack
+	if purchasedCard.Type == ShopItemType {
+		effectMsg := ""
+		if purchasedCard.Strength > 0 && purchasedCard.Magic > 0 {
+			effectMsg = fmt.Sprintf(" (+%d STR, +%d MAG)", purchasedCard.Strength, purchasedCard.Magic)
+		} else if purchasedCard.Strength > 0 {
+			effectMsg = fmt.Sprintf(" (+%d STR)", purchasedCard.Strength)
+		} else if purchasedCard.Magic > 0 {
+			effectMsg = fmt.Sprintf(" (+%d MAG)", purchasedCard.Magic)
+		}
+		if _, worn := g.Current().Worn(purchasedCard.Slot); worn {
+			g.Current().Cards = append(g.Current().Cards, purchasedCard)
+			g.Logf("Purchased %s for %d gold%s - it goes in your pack", purchasedCard.Title, price, effectMsg)
+		} else {
+			g.Current().Equipped[purchasedCard.Slot.Index()] = purchasedCard
+			g.Logf("Purchased and equipped %s for %d gold%s", purchasedCard.Title, price, effectMsg)
+		}
+	} else if purchasedCard.Type == SpellType {
+		g.Current().learnSpell(purchasedCard)
+		g.Logf("Purchased %s for %d gold (%s)", purchasedCard.Title, price, purchasedCard.Spell.Effect())
+	} else if purchasedCard.Type == PotionType {
+		g.Current().Cards = append(g.Current().Cards, purchasedCard)
+		g.Logf("Purchased %s for %d gold (%s)", purchasedCard.Title, price, purchasedCard.PotionEffect())
+	} else if purchasedCard.Type == FollowerType {
+		g.Current().Followers = append(g.Current().Followers, purchasedCard)
+		g.Logf("Hired %s for %d gold (%s)", purchasedCard.Title, price, purchasedCard.Follower.Effect())
+	} else if purchasedCard.Type == TalismanType {
+		g.Current().Cards = append(g.Current().Cards, purchasedCard)
+		g.Logf("Purchased the Talisman for %d gold - The Crown awaits", price)
+	} else {
+		// Regular cards go to inventory without immediate effects
+		g.Current().Cards = append(g.Current().Cards, purchasedCard)
+		g.Logf("Purchased item for %d gold (added to inventory)", price)
+	}
+
+	// Replace purchased card with new one and update persistent shop data
+	shop := g.AdjacentShop()
+	var keeperType int
+	if shop != nil {
+		keeperType = shop.KeeperType
+	}
+	newCard := RandShopCard(g.Rng, keeperType, g.Round)
+	newPrice := ShopPrice(g.Rng, newCard)
+	g.ShopCards[slot] = newCard
+	g.ShopPrices[slot] = newPrice
+	if shop != nil {
+		shop.Cards[slot] = newCard
+		shop.Prices[slot] = newPrice
+	}
+	return nil
+}
+
+// exchangeMonsterStrength trades strength trophies for +1 STR and logs it.
+func (g *Game) exchangeMonsterStrength() bool {
+	if !g.Current().ExchangeMonsterStrength() {
+		return false
+	}
+	g.Logf("Exchanged monster strength for +1 STR")
+	return true
+}
+
+// exchangeMagicMonster trades magic trophies for +1 MAG and logs it.
+func (g *Game) exchangeMagicMonster() bool {
+	if !g.Current().ExchangeMagicMonster() {
+		return false
+	}
+	g.Logf("Exchanged magic monster strength for +1 MAG")
+	return true
+}
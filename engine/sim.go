@@ -0,0 +1,216 @@
+package engine
+
+import (
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// SimConfig describes a batch of headless games for balance testing.
+type SimConfig struct {
+	Games    int
+	Seed     int64       // game i is played from Seed+i, so a batch is reproducible
+	MaxTurns int         // a game still going after this many rolls is given up on
+	Policy   Personality // how the simulated hero plays
+}
+
+// maxSimActions stops a game whose policy has wedged itself.
+const maxSimActions = 100_000
+
+// DeckStats counts the fights fought against one deck.
+type DeckStats struct {
+	Fights, Wins, Losses, Fled int
+	Rounds                     int
+	GoldEarned                 int
+}
+
+// AvgRounds is how long a fight against the deck lasts on average.
+func (d DeckStats) AvgRounds() float64 {
+	if d.Fights == 0 {
+		return 0
+	}
+	return float64(d.Rounds) / float64(d.Fights)
+}
+
+// WinRate is the share of fights against the deck the hero won.
+func (d DeckStats) WinRate() float64 {
+	if d.Fights == 0 {
+		return 0
+	}
+	return float64(d.Wins) / float64(d.Fights)
+}
+
+// SimReport sums up a batch of simulated games.
+type SimReport struct {
+	Config     SimConfig
+	Games      int
+	Wins       int            // games won by claiming The Crown
+	Timeouts   int            // games given up after MaxTurns rolls
+	Talismans  int            // games in which the hero got hold of a Talisman
+	Turns      []int          // rolls played, one per game, sorted
+	Deaths     map[string]int // LoopType.Name of the tile each dead hero fell on
+	GoldEarned int
+	GoldSpent  int
+	Decks      map[string]*DeckStats // by Deck.Name
+	Errors     []error               // games stopped by an illegal policy action
+}
+
+func (r *SimReport) WinRate() float64 {
+	if r.Games == 0 {
+		return 0
+	}
+	return float64(r.Wins) / float64(r.Games)
+}
+
+func (r *SimReport) AvgTurns() float64 {
+	if len(r.Turns) == 0 {
+		return 0
+	}
+	total := 0
+	for _, t := range r.Turns {
+		total += t
+	}
+	return float64(total) / float64(len(r.Turns))
+}
+
+func (r *SimReport) MedianTurns() int {
+	if len(r.Turns) == 0 {
+		return 0
+	}
+	return r.Turns[len(r.Turns)/2]
+}
+
+func newSimReport(cfg SimConfig) *SimReport {
+	return &SimReport{Config: cfg, Deaths: map[string]int{}, Decks: map[string]*DeckStats{}}
+}
+
+func (r *SimReport) deck(name string) *DeckStats {
+	if name == "" {
+		name = "(bridge)"
+	}
+	d := r.Decks[name]
+	if d == nil {
+		d = &DeckStats{}
+		r.Decks[name] = d
+	}
+	return d
+}
+
+func (r *SimReport) merge(o *SimReport) {
+	r.Games += o.Games
+	r.Wins += o.Wins
+	r.Timeouts += o.Timeouts
+	r.Talismans += o.Talismans
+	r.Turns = append(r.Turns, o.Turns...)
+	for k, v := range o.Deaths {
+		r.Deaths[k] += v
+	}
+	r.GoldEarned += o.GoldEarned
+	r.GoldSpent += o.GoldSpent
+	for k, v := range o.Decks {
+		d := r.deck(k)
+		d.Fights += v.Fights
+		d.Wins += v.Wins
+		d.Losses += v.Losses
+		d.Fled += v.Fled
+		d.Rounds += v.Rounds
+		d.GoldEarned += v.GoldEarned
+	}
+	r.Errors = append(r.Errors, o.Errors...)
+}
+
+// Simulate plays cfg.Games single-hero permadeath games on all CPUs and
+// collects the numbers we tune decks and prices by. A hero wins by claiming
+// The Crown; games that drag on past cfg.MaxTurns rolls are given up.
+func Simulate(cfg SimConfig) *SimReport {
+	if cfg.Policy == "" {
+		cfg.Policy = Cautious
+	}
+	games := make(chan int)
+	results := make(chan *SimReport)
+	var wg sync.WaitGroup
+	for range runtime.NumCPU() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rep := newSimReport(cfg)
+			for i := range games {
+				simGame(cfg, cfg.Seed+int64(i), rep)
+			}
+			results <- rep
+		}()
+	}
+	go func() {
+		for i := range cfg.Games {
+			games <- i
+		}
+		close(games)
+		wg.Wait()
+		close(results)
+	}()
+
+	out := newSimReport(cfg)
+	for rep := range results {
+		out.merge(rep)
+	}
+	slices.Sort(out.Turns)
+	return out
+}
+
+// simGame plays one game, adds it to rep and returns it.
+func simGame(cfg SimConfig, seed int64, rep *SimReport) *Game {
+	g := NewGame(seed, 1)
+	hero := g.Current()
+	hero.Bot = cfg.Policy
+	g.DeathRule = Permadeath
+	rep.Games++
+
+	for range maxSimActions {
+		if hero.Won && !g.CardActive {
+			rep.Wins++
+			break
+		}
+		if hero.Dead {
+			rep.Deaths[hero.Stats.KilledIn]++
+			break
+		}
+		if g.Turn >= cfg.MaxTurns && g.CanRoll() {
+			rep.Timeouts++
+			break
+		}
+
+		a := cfg.Policy.NextAction(g)
+		gold, settled := hero.Gold, g.Battle != nil && !g.InBattle()
+		if err := g.Apply(a); err != nil {
+			rep.Errors = append(rep.Errors, err)
+			break
+		}
+		g.FinishMove()
+
+		// checked afterwards: an ambush can start and end a fight as the
+		// hero lands
+		if b := g.Battle; b != nil && !g.InBattle() && !settled {
+			d := rep.deck(g.World.Loops[hero.At.Loop].Type.Deck.Name)
+			d.Fights++
+			d.Rounds += len(b.Rounds)
+			switch b.Outcome {
+			case OutcomeWin:
+				d.Wins++
+				d.GoldEarned += b.GoldAfter - b.GoldBefore
+			case OutcomeLoss:
+				d.Losses++
+			case OutcomeFled:
+				d.Fled++
+			}
+		}
+		if a.Kind == ActBuy {
+			rep.GoldSpent += gold - hero.Gold
+		}
+	}
+	if hero.HasTalisman() {
+		rep.Talismans++
+	}
+	rep.GoldEarned += hero.Stats.GoldEarned // from fights, treasure, events and places alike
+	rep.Turns = append(rep.Turns, g.Turn)
+	return g
+}
@@ -0,0 +1,29 @@
+package engine
+
+import "testing"
+
+func TestSimGameCounts(t *testing.T) {
+	for _, policy := range []Personality{Cautious, Greedy, Reckless} {
+		for seed := int64(1); seed <= 10; seed++ {
+			cfg := SimConfig{Games: 1, MaxTurns: 200, Policy: policy}
+			rep := newSimReport(cfg)
+			g := simGame(cfg, seed, rep)
+			if len(rep.Errors) > 0 {
+				t.Fatalf("%s seed %d: %v", policy, seed, rep.Errors[0])
+			}
+			hero := g.Players[0].Stats
+			var won, lost int
+			for _, d := range rep.Decks {
+				won += d.Wins
+				lost += d.Losses
+			}
+			if won != hero.FightsWon || lost != hero.FightsLost {
+				t.Errorf("%s seed %d: counted %d won, %d lost; the hero has %d, %d",
+					policy, seed, won, lost, hero.FightsWon, hero.FightsLost)
+			}
+			if rep.GoldEarned != hero.GoldEarned {
+				t.Errorf("%s seed %d: %d gold earned, the hero has %d", policy, seed, rep.GoldEarned, hero.GoldEarned)
+			}
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+)
+
+// SpellKind says when a spell can be cast and what it does.
+type SpellKind string
+
+const (
+	SpellBattle   SpellKind = "battle"   // before or during a fight: adds Power to the hero's rolls
+	SpellStride   SpellKind = "stride"   // after rolling: adds Power to the roll
+	SpellTeleport SpellKind = "teleport" // instead of rolling: jump next to a discovered shop
+)
+
+var SpellKinds = []SpellKind{SpellBattle, SpellStride, SpellTeleport}
+
+// Spell is what a spell card does when cast. Spells come from encounter
+// decks, shops and trading in trophies, sit in the hero's spell slots and
+// are gone once cast.
+type Spell struct {
+	Kind  SpellKind
+	Power int `json:",omitempty"` // fight bonus or extra steps; unused by teleport
+}
+
+// When is the moment the spell is cast at, for the UI.
+func (s Spell) When() string {
+	switch s.Kind {
+	case SpellBattle:
+		return "before or during a fight"
+	case SpellStride:
+		return "after rolling"
+	case SpellTeleport:
+		return "instead of rolling"
+	}
+	return ""
+}
+
+// Effect describes what the spell does, for the UI and the log.
+func (s Spell) Effect() string {
+	switch s.Kind {
+	case SpellBattle:
+		return fmt.Sprintf("+%d to your fight rolls", s.Power)
+	case SpellStride:
+		return fmt.Sprintf("+%d to your roll", s.Power)
+	case SpellTeleport:
+		return "jump to a discovered shop"
+	}
+	return ""
+}
+
+// SpellDeck is the encounter deck a trophy trade deals spells from.
+const SpellDeck = "spells"
+
+// SpellTrophyCost is the trophy value, of either kind, a new spell costs.
+const SpellTrophyCost = 7
+
+// SpellSlots is how many spells the hero can hold: one per two points of
+// Magic, and never fewer than one.
+func (p *Player) SpellSlots() int { return max(1, p.Magic/2) }
+
+// learnSpell puts a spell card in a free slot. It reports false when every
+// slot is taken.
+func (p *Player) learnSpell(c Card) bool {
+	if len(p.Spells) >= p.SpellSlots() {
+		return false
+	}
+	p.Spells = append(p.Spells, c)
+	return true
+}
+
+// SpellSlot is the first of the hero's spell slots holding a spell of kind,
+// or -1.
+func (p *Player) SpellSlot(kind SpellKind) int {
+	for i, c := range p.Spells {
+		if c.Spell.Kind == kind {
+			return i
+		}
+	}
+	return -1
+}
+
+// CanCast reports why the hero's spell in slot cannot be cast right now, or
+// nil if it can.
+func (g *Game) CanCast(slot int) error {
+	p := g.Current()
+	if slot < 0 || slot >= len(p.Spells) {
+		return fmt.Errorf("no spell in slot %d: %w", slot, ErrIllegalAction)
+	}
+	s := p.Spells[slot].Spell
+	var ok bool
+	switch s.Kind {
+	case SpellBattle:
+		ok = g.CardActive && !g.CardResolved && g.Card.FightStat() != ""
+	case SpellStride:
+		ok = g.Phase == PhaseTargetSelect
+	case SpellTeleport:
+		ok = !g.CardActive && !g.ShopActive && g.CanRoll() && len(g.TeleportDests()) > 0
+	}
+	if !ok {
+		return fmt.Errorf("%s can only be cast %s: %w", p.Spells[slot].Title, s.When(), ErrIllegalAction)
+	}
+	return nil
+}
+
+// castSpell casts and forgets the spell in slot. Teleports go to target,
+// which must be one of TeleportDests.
+func (g *Game) castSpell(slot int, target TileID) error {
+	if err := g.CanCast(slot); err != nil {
+		return err
+	}
+	p := g.Current()
+	c := p.Spells[slot]
+	switch c.Spell.Kind {
+	case SpellBattle:
+		g.FightBonus += c.Spell.Power
+		if g.InBattle() {
+			// casting mid-fight takes up the round
+			g.endRound(BattleRound{Move: MoveSpell, Item: c.Title})
+		} else {
+			g.Logf("You cast %s: +%d to this fight", c.Title, c.Spell.Power)
+		}
+	case SpellStride:
+		g.LastRoll += c.Spell.Power
+		g.StepsRemaining = g.LastRoll
+		g.Dests = gatherAllLandingSpots(p.At, g.StepsRemaining, g.World, g.crownOpen())
+		g.Logf("You cast %s: the roll becomes %d", c.Title, g.LastRoll)
+	case SpellTeleport:
+		if !slices.Contains(g.TeleportDests(), target) {
+			return fmt.Errorf("tile %v is not beside a discovered shop: %w", target, ErrIllegalAction)
+		}
+		// the jump takes the turn's move, so statuses tick as for a roll
+		if g.spendTurn() {
+			p.At = target
+			g.Logf("You cast %s and step out beside %s", c.Title, g.AdjacentShop().Name)
+		}
+	}
+	p.Spells = slices.Delete(p.Spells, slot, slot+1)
+	return nil
+}
+
+// TeleportDests lists the tiles beside shops that any hero has discovered,
+// in world order, leaving out the one the hero stands on.
+func (g *Game) TeleportDests() []TileID {
+	var out []TileID
+	for _, l := range g.World.Loops {
+		for _, t := range l.Tiles {
+			if !t.Shop || t.ShopData == nil || !t.ShopData.Discovered {
+				continue
+			}
+			for _, id := range t.Links {
+				if id == g.Current().At || isShopTile(g.World, id) || isBridgeTile(g.World, id) {
+					continue
+				}
+				if g.World.Loops[id.Loop].Type.Crown && !g.crownOpen() {
+					continue
+				}
+				if !slices.Contains(out, id) {
+					out = append(out, id)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// exchangeForSpell trades trophies worth SpellTrophyCost, oldest first, for
+// a spell dealt from SpellDeck.
+func (g *Game) exchangeForSpell() error {
+	p := g.Current()
+	deck := content.Decks[SpellDeck]
+	var why string
+	switch {
+	case len(deck.Cards) == 0:
+		why = "there are no spells to learn"
+	case len(p.Spells) >= p.SpellSlots():
+		why = "every spell slot is taken"
+	case p.TrophyValue() < SpellTrophyCost:
+		why = fmt.Sprintf("a spell costs trophies worth %d", SpellTrophyCost)
+	}
+	if why != "" {
+		return fmt.Errorf("%s: %w", why, ErrIllegalAction)
+	}
+	remaining := SpellTrophyCost
+	kept := p.Cards[:0:0]
+	for _, c := range p.Cards {
+		if isTrophy(c) && remaining > 0 {
+			remaining -= c.Power()
+			continue
+		}
+		kept = append(kept, c)
+	}
+	p.Cards = kept
+	c := drawWeighted(g.Rng, deck.Cards)
+	p.learnSpell(c)
+	g.Logf("Traded trophies for a spell: %s (%s)", c.Title, c.Spell.Effect())
+	return nil
+}
+
+// TrophyValue sums the power of the hero's monster trophies.
+func (p *Player) TrophyValue() int {
+	total := 0
+	for _, c := range p.Cards {
+		if isTrophy(c) {
+			total += c.Power()
+		}
+	}
+	return total
+}
@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// discoverShops marks every shop in the world as found.
+func discoverShops(g *Game) {
+	for _, l := range g.World.Loops {
+		for _, tile := range l.Tiles {
+			if tile.ShopData != nil {
+				tile.ShopData.Discovered = true
+			}
+		}
+	}
+}
+
+func TestCastSpell(t *testing.T) {
+	tests := []struct {
+		name  string
+		spell Card
+		setup func(t *testing.T, g *Game)
+		ok    bool
+		check func(t *testing.T, g *Game)
+	}{
+		{name: "battle spell with no fight", spell: NewSpell(SpellBattle, 2)},
+		{name: "stride before rolling", spell: NewSpell(SpellStride, 2)},
+		{name: "teleport with no shop found", spell: NewSpell(SpellTeleport, 0)},
+		{name: "battle spell before the fight", spell: NewSpell(SpellBattle, 2), ok: true,
+			setup: func(t *testing.T, g *Game) {
+				g.Card = NewMonsterStrength(4)
+				g.CardActive = true
+			},
+			check: func(t *testing.T, g *Game) {
+				if g.FightBonus != 2 {
+					t.Errorf("fight bonus %d, want 2", g.FightBonus)
+				}
+			}},
+		{name: "stride after rolling", spell: NewSpell(SpellStride, 2), ok: true,
+			setup: func(t *testing.T, g *Game) { g.roll() },
+			check: func(t *testing.T, g *Game) {
+				if g.StepsRemaining != g.LastRoll || g.LastRoll < 3 {
+					t.Errorf("roll %d with %d steps, want the roll raised by 2", g.LastRoll, g.StepsRemaining)
+				}
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGame(1, 1)
+			p := g.Current()
+			p.Spells = []Card{tt.spell}
+			if tt.setup != nil {
+				tt.setup(t, g)
+			}
+			log := len(g.Log)
+			err := g.Apply(Action{Kind: ActCastSpell})
+			if !tt.ok {
+				if !errors.Is(err, ErrIllegalAction) {
+					t.Fatalf("got %v, want an illegal action", err)
+				}
+				if len(g.Log) != log || len(p.Spells) != 1 {
+					t.Fatalf("a rejected cast logged %q or used the spell", g.Log[log:])
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(p.Spells) != 0 {
+				t.Error("the spell was not used up")
+			}
+			tt.check(t, g)
+		})
+	}
+}
+
+func TestTeleportSpendsTheTurn(t *testing.T) {
+	g := NewGame(1, 1)
+	discoverShops(g)
+	p := g.Current()
+	p.Spells = []Card{NewSpell(SpellTeleport, 0)}
+	p.Statuses = []Status{{StatusPoison, 1, 2}}
+	dests := g.TeleportDests()
+	if len(dests) == 0 {
+		t.Fatal("nowhere to teleport to")
+	}
+	if err := g.Apply(Action{Kind: ActCastSpell, Tile: TileID{Loop: -1}}); !errors.Is(err, ErrIllegalAction) {
+		t.Fatalf("teleporting off the list: got %v, want an illegal action", err)
+	}
+	if err := g.Apply(Action{Kind: ActCastSpell, Tile: dests[0]}); err != nil {
+		t.Fatal(err)
+	}
+	if p.At != dests[0] || !g.Moved || g.CanRoll() {
+		t.Fatalf("at %v, moved %v; want at %v with the move spent", p.At, g.Moved, dests[0])
+	}
+	if g.Turn != 1 || p.Stats.Turns != 1 {
+		t.Errorf("turn %d, hero turns %d; want 1, 1", g.Turn, p.Stats.Turns)
+	}
+	if p.Health != StartHealth-1 || !slices.Equal(p.Statuses, []Status{{StatusPoison, 1, 1}}) {
+		t.Errorf("health %d, statuses %v; want the poison to bite", p.Health, p.Statuses)
+	}
+}
@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+)
+
+// StatusKind is a lasting effect on a hero.
+type StatusKind string
+
+const (
+	StatusPoison   StatusKind = "poison"   // lose Power health at the start of each turn
+	StatusCurse    StatusKind = "curse"    // -Power to Strength while it lasts
+	StatusBlessing StatusKind = "blessing" // the movement die comes up six
+)
+
+var StatusKinds = []StatusKind{StatusPoison, StatusCurse, StatusBlessing}
+
+// Status is a timed effect on a hero. It ticks at the start of each of the
+// hero's rolls and wears off when its turns run out.
+type Status struct {
+	Kind  StatusKind
+	Power int `json:",omitempty"` // health lost or Strength taken; unused by blessings
+	Turns int // rolls left before it wears off
+}
+
+// Effect describes what the status does, for the UI and the log.
+func (s Status) Effect() string {
+	switch s.Kind {
+	case StatusPoison:
+		return fmt.Sprintf("-%d health a turn", s.Power)
+	case StatusCurse:
+		return fmt.Sprintf("-%d Strength", s.Power)
+	case StatusBlessing:
+		return "your roll is a six"
+	}
+	return ""
+}
+
+// String is the status in short with its turns left, like "poison 2".
+func (s Status) String() string { return fmt.Sprintf("%s %d", s.Kind, s.Turns) }
+
+// statusPrice is what a status adds to a potion's cost in the shops.
+func statusPrice(s Status) int {
+	if s.Kind == StatusBlessing {
+		return s.Turns * 6
+	}
+	return 0
+}
+
+// afflict puts s on the hero. A status of a kind the hero already has
+// replaces it.
+func (p *Player) afflict(s Status) {
+	if i := slices.IndexFunc(p.Statuses, func(o Status) bool { return o.Kind == s.Kind }); i >= 0 {
+		p.Statuses[i] = s
+		return
+	}
+	p.Statuses = append(p.Statuses, s)
+}
+
+// StatusPower is the Power of the hero's status of kind, or 0.
+func (p *Player) StatusPower(kind StatusKind) int {
+	for _, s := range p.Statuses {
+		if s.Kind == kind {
+			return s.Power
+		}
+	}
+	return 0
+}
+
+// HasStatus reports whether the hero is under a status of kind.
+func (p *Player) HasStatus(kind StatusKind) bool {
+	return slices.ContainsFunc(p.Statuses, func(s Status) bool { return s.Kind == kind })
+}
+
+// tickStatuses runs the current hero's statuses at the start of a roll:
+// poison bites, every status loses a turn and the spent ones wear off.
+func (g *Game) tickStatuses() {
+	p := g.Current()
+	kept := p.Statuses[:0]
+	for _, s := range p.Statuses {
+		if s.Kind == StatusPoison && !p.Dead {
+			p.Health = max(p.Health-s.Power, 0)
+			g.Logf("Poison: you lose %d health", s.Power)
+			if p.Health == 0 {
+				p.Dead = true
+				g.died(Card{Title: "Poison"})
+			}
+		}
+		if s.Turns--; s.Turns > 0 {
+			kept = append(kept, s)
+		} else {
+			g.Logf("Your %s wears off", s.Kind)
+		}
+	}
+	p.Statuses = kept
+}
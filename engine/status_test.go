@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTickStatuses(t *testing.T) {
+	tests := []struct {
+		name     string
+		health   int
+		statuses []Status
+		ticks    int
+		health2  int      // health after the ticks
+		strength int      // Stat(StatStrength) after the ticks, from 3
+		left     []Status // what is still on the hero
+		dead     bool
+	}{
+		{name: "poison bites", health: 5, statuses: []Status{{StatusPoison, 1, 2}}, ticks: 1,
+			health2: 4, strength: 3, left: []Status{{StatusPoison, 1, 1}}},
+		{name: "poison wears off", health: 5, statuses: []Status{{StatusPoison, 1, 2}}, ticks: 3,
+			health2: 3, strength: 3},
+		{name: "poison kills", health: 2, statuses: []Status{{StatusPoison, 2, 3}}, ticks: 1,
+			strength: 3, left: []Status{{StatusPoison, 2, 2}}, dead: true},
+		{name: "curse", health: 5, statuses: []Status{{StatusCurse, 2, 2}}, ticks: 1,
+			health2: 5, strength: 1, left: []Status{{StatusCurse, 2, 1}}},
+		{name: "curse lifts", health: 5, statuses: []Status{{StatusCurse, 5, 2}}, ticks: 2,
+			health2: 5, strength: 3},
+		{name: "each on its own clock", health: 5, statuses: []Status{{StatusCurse, 1, 1}, {StatusBlessing, 0, 3}}, ticks: 1,
+			health2: 5, strength: 3, left: []Status{{StatusBlessing, 0, 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGame(1, 1)
+			p := g.Current()
+			p.Health = tt.health
+			p.Statuses = slices.Clone(tt.statuses)
+			for range tt.ticks {
+				g.tickStatuses()
+			}
+			if p.Health != tt.health2 || p.Stat(StatStrength) != tt.strength || p.Dead != tt.dead {
+				t.Errorf("health %d, strength %d, dead %v; want %d, %d, %v",
+					p.Health, p.Stat(StatStrength), p.Dead, tt.health2, tt.strength, tt.dead)
+			}
+			if !slices.Equal(p.Statuses, tt.left) {
+				t.Errorf("statuses %v, want %v", p.Statuses, tt.left)
+			}
+		})
+	}
+}
+
+func TestAfflictReplaces(t *testing.T) {
+	p := NewPlayer(TileID{}, 3, 3, StartHealth)
+	p.afflict(Status{StatusPoison, 1, 3})
+	p.afflict(Status{StatusCurse, 1, 2})
+	p.afflict(Status{StatusPoison, 2, 1})
+	want := []Status{{StatusPoison, 2, 1}, {StatusCurse, 1, 2}}
+	if !slices.Equal(p.Statuses, want) {
+		t.Fatalf("statuses %v, want %v", p.Statuses, want)
+	}
+}
+
+func TestBlessingRollsSix(t *testing.T) {
+	g := NewGame(1, 1)
+	g.Current().Statuses = []Status{{StatusBlessing, 0, 3}}
+	for i := range 3 {
+		g.roll()
+		if g.LastRoll != 6 {
+			t.Fatalf("blessed roll %d came up %d", i+1, g.LastRoll)
+		}
+	}
+	if g.Current().HasStatus(StatusBlessing) {
+		t.Fatal("the blessing did not wear off")
+	}
+}
+
+func TestStatusJSON(t *testing.T) {
+	tests := []struct {
+		in statusJSON
+		ok bool
+	}{
+		{statusJSON{Kind: StatusPoison, Power: 1, Turns: 2}, true},
+		{statusJSON{Kind: StatusBlessing, Turns: 1}, true},
+		{statusJSON{Kind: "plague", Power: 1, Turns: 2}, false},
+		{statusJSON{Kind: StatusCurse, Power: 1}, false},
+		{statusJSON{Kind: StatusCurse, Turns: 2}, false},
+		{statusJSON{Kind: StatusBlessing, Power: 1, Turns: 2}, false},
+	}
+	for _, tt := range tests {
+		if _, err := tt.in.status(); (err == nil) != tt.ok {
+			t.Errorf("%+v: got %v, want ok %v", tt.in, err, tt.ok)
+		}
+	}
+}
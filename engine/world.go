@@ -0,0 +1,328 @@
+package engine
+
+import (
+	"math/rand"
+	"slices"
+)
+
+const (
+	TileSize = 44 // grid cell size in world pixels
+
+	crossLinkChance = 0.95
+	shopChance      = 0.20
+)
+
+// RandomRegions picks how many loops a fresh world has, which region each
+// of them belongs to and how many tiles it gets.
+func RandomRegions(r *rand.Rand) ([]Region, []int) {
+	numOfLoops := r.Intn(30) + 20
+	regions := []Region{}
+	loopCounts := []int{}
+	for i := range numOfLoops {
+		rg := pickRegion(r, i)
+		regions = append(regions, rg)
+		loopCounts = append(loopCounts, rg.MinSize+r.Intn(rg.MaxSize-rg.MinSize+1))
+	}
+	return regions, loopCounts
+}
+
+// BuildWorldCountsRandom lays out one loop per region with counts[i] tiles
+// and wires bridges and shops between them. The loop picked by centralLeaf
+// becomes The Crown. The shops are stocked for the given round.
+func BuildWorldCountsRandom(r *rand.Rand, regions []Region, counts []int, round int) World {
+	// even counts
+	for i, n := range counts {
+		if n%2 != 0 {
+			counts[i] = n + 1
+		}
+	}
+
+	g := Grid{Cell: TileSize}
+
+	// dims
+	cols := make([]int, len(counts))
+	rows := make([]int, len(counts))
+	for i, n := range counts {
+		c, r, ok := rectDimsForPerimeter(n)
+		if !ok {
+			panic("cannot factor even tile count")
+		}
+		cols[i], rows[i] = c, r
+	}
+
+	// placement specs + which earlier loop each loop is anchored to
+	specs := make([]rectSpec, len(counts))
+	parent := make([]int, len(counts)) // parent[i] = anchor loop index; -1 for the first
+	occ := map[cell]bool{}
+
+	// seed: first loop at (0,0)
+	specs[0] = rectSpec{gx: 0, gy: 0, cols: cols[0], rows: rows[0]}
+	parent[0] = -1
+	markRectPerimeter(occ, specs[0].gx, specs[0].gy, specs[0].cols, specs[0].rows)
+
+	// place the rest; sometimes attach to a random previous loop instead of (i-1)
+	for i := 1; i < len(counts); i++ {
+		// candidate anchors in priority order
+		anchors := []int{i - 1}
+		if i >= 2 && r.Float32() < crossLinkChance {
+			anchors = append([]int{r.Intn(i - 1)}, anchors...) // pick from 0..i-2
+		}
+
+		placed := false
+		for _, aidx := range anchors {
+			A := specs[aidx]
+			for _, d := range shuffledDirs(r) {
+				pr := placeAround(A, cols[i], rows[i], d)
+
+				// corridor must be free; B perimeter must be free
+				if anyOccupied(occ, pr.bridge1, pr.bridge2) {
+					continue
+				}
+				if wouldCollideRectPerimeter(occ, pr.specB.gx, pr.specB.gy, pr.specB.cols, pr.specB.rows) {
+					continue
+				}
+
+				// accept
+				specs[i] = pr.specB
+				parent[i] = aidx
+				markRectPerimeter(occ, pr.specB.gx, pr.specB.gy, pr.specB.cols, pr.specB.rows)
+				markCells(occ, pr.bridge1, pr.bridge2)
+				placed = true
+				break
+			}
+			if placed {
+				break
+			}
+		}
+
+		if !placed {
+			// fallback: attach to previous on the right
+			pr := placeAround(specs[i-1], cols[i], rows[i], Right)
+			specs[i] = pr.specB
+			parent[i] = i - 1
+			markRectPerimeter(occ, pr.specB.gx, pr.specB.gy, pr.specB.cols, pr.specB.rows)
+			markCells(occ, pr.bridge1, pr.bridge2)
+		}
+	}
+
+	if rg, ok := crownRegion(); ok {
+		if i := centralLeaf(specs, parent); i >= 0 {
+			regions = slices.Clone(regions)
+			regions[i] = rg
+		}
+	}
+
+	// build loops
+	world := World{Loops: make([]Loop, 0, len(counts)+(len(counts)-1))}
+	for i := range counts {
+		world.Loops = append(world.Loops, buildRectPerimeterLoopAtGrid(
+			g, specs[i].gx, specs[i].gy, specs[i].cols, specs[i].rows, regions[i].loopType()))
+	}
+	finalizeLoopIndices(&world)
+
+	// connect each loop i to its chosen anchor parent[i] with a 2-tile bridge
+	for i := 1; i < len(specs); i++ {
+		j := parent[i]
+		if j < 0 {
+			continue
+		}
+
+		// recompute the exact corridor cells and facing mids
+		dir := inferDir(specs[j], specs[i])
+		pr := placeAround(specs[j], specs[i].cols, specs[i].rows, dir)
+
+		ai := findTileIndexAtCell(g, world.Loops[j], pr.aMidX, pr.aMidY)
+		bi := findTileIndexAtCell(g, world.Loops[i], pr.bMidX, pr.bMidY)
+
+		bridgeLoop := makeBridge(g, pr.bridge1.X, pr.bridge1.Y, pr.bridge2.X, pr.bridge2.Y)
+		world.Loops = append(world.Loops, bridgeLoop)
+		bridgeLoopIdx := len(world.Loops) - 1
+		finalizeLoopIndices(&world)
+
+		// one-to-one links
+		world.Loops[bridgeLoopIdx].Tiles[0].Links = []TileID{{Loop: j, Index: ai}}
+		world.Loops[bridgeLoopIdx].Tiles[1].Links = []TileID{{Loop: i, Index: bi}}
+		world.Loops[j].Tiles[ai].Links = []TileID{{Loop: bridgeLoopIdx, Index: 0}}
+		world.Loops[i].Tiles[bi].Links = []TileID{{Loop: bridgeLoopIdx, Index: 1}}
+	}
+	addExtraCycleBridges(g, specs, &world, occ)
+
+	spawnShops(r, g, specs, regions, &world, occ, round)
+	placeBosses(r, &world, len(specs))
+	return world
+}
+
+// infer which cardinal placement produced b from a
+func inferDir(a, b rectSpec) Dir {
+	_, ayR := sideMidCell(a.gx, a.gy, a.cols, a.rows, "right")
+	if b.gx == a.gx+a.cols+2 && b.gy == ayR-b.rows/2 {
+		return Right
+	}
+	_, ayL := sideMidCell(a.gx, a.gy, a.cols, a.rows, "left")
+	if b.gx == a.gx-2-b.cols && b.gy == ayL-b.rows/2 {
+		return Left
+	}
+	axB, _ := sideMidCell(a.gx, a.gy, a.cols, a.rows, "bottom")
+	if b.gx == axB-b.cols/2 && b.gy == a.gy+a.rows+2 {
+		return Down
+	}
+	axT, _ := sideMidCell(a.gx, a.gy, a.cols, a.rows, "top")
+	if b.gx == axT-b.cols/2 && b.gy == a.gy-2-b.rows {
+		return Up
+	}
+	return Right // fallback
+}
+func spawnShops(r *rand.Rand, g Grid, specs []rectSpec, regions []Region, world *World, occ map[cell]bool, round int) {
+	shopCounter := 0
+	for li := range specs {
+		// only consider the original loops (skip bridge loops added later)
+		if li >= len(specs) {
+			break
+		}
+
+		keepers := regions[li].Shopkeepers
+		if len(keepers) == 0 {
+			continue // no trade in this region
+		}
+		loop := &world.Loops[li]
+		sp := specs[li]
+
+		for ti := range loop.Tiles {
+			pt := &loop.Tiles[ti]
+			if pt.Bridge { // skip bridge endpoints
+				continue
+			}
+			if r.Float32() >= shopChance {
+				continue
+			}
+
+			cx, cy := g.CellOf(pt.Pos)
+			dx, dy, ok := outwardDirForCellOnRect(sp, cx, cy)
+			if !ok {
+				continue // safety: tile not recognized as perimeter
+			}
+			shopCell := cell{cx + dx, cy + dy}
+			if anyOccupied(occ, shopCell) {
+				continue // don't overlap existing things
+			}
+
+			// create the 1-tile shop loop with persistent inventory
+			shop := makeShop(g, shopCell.X, shopCell.Y)
+
+			// Initialize shop with unique inventory
+			keeperType := keepers[r.Intn(len(keepers))] // one of the region's shopkeepers
+			var shopName string
+			switch keeperType {
+			case 0:
+				shopNames := []string{"Mystic Emporium", "Arcane Artifacts", "Crystal Cave", "Wizard's Workshop", "Magic Mirror Shop"}
+				shopName = shopNames[r.Intn(len(shopNames))]
+			case 1:
+				shopNames := []string{"Ogre's Armory", "Beast & Bone", "Iron Fist Trading", "Brutal Bargains", "Stone Club Store"}
+				shopName = shopNames[r.Intn(len(shopNames))]
+			case 2:
+				shopNames := []string{"Banana Bazaar", "Jungle Goods", "Monkey Business", "Vine & Vine", "Treetop Treasures"}
+				shopName = shopNames[r.Intn(len(shopNames))]
+			case 3:
+				shopNames := []string{"Pig & Whistle", "Truffle Traders", "Muddy Boots", "Farm Fresh Finds", "Snort & Shop"}
+				shopName = shopNames[r.Intn(len(shopNames))]
+			case 4:
+				shopNames := []string{"Ocean's Bounty", "Tidal Treasures", "Deep Sea Depot", "Whale Song Shop", "Coral Curiosities"}
+				shopName = shopNames[r.Intn(len(shopNames))]
+			}
+			shopData := &ShopType{
+				Name:       shopName,
+				Discovered: false,
+				KeeperType: keeperType,
+				Stocked:    round,
+			}
+			// Generate 3 random shop cards
+			for i := 0; i < 3; i++ {
+				shopData.Cards[i] = RandShopCard(r, shopData.KeeperType, round)
+				// Calculate price based on card attributes
+				shopData.Prices[i] = ShopPrice(r, shopData.Cards[i])
+			}
+
+			shop.Tiles[0].ShopData = shopData
+			world.Loops = append(world.Loops, shop)
+			shopIdx := len(world.Loops) - 1
+			finalizeLoopIndices(world)
+
+			// link shop <-> perimeter tile
+			world.Loops[shopIdx].Tiles[0].Links = []TileID{{Loop: li, Index: ti}}
+			pt.Links = append(pt.Links, TileID{Loop: shopIdx, Index: 0})
+
+			// reserve the cell so nothing else spawns here
+			markCells(occ, shopCell)
+			shopCounter++
+		}
+	}
+}
+
+// Try to add extra 2-tile bridges between any two original loops (not bridge loops)
+// when they are already placed with the exact 2-cell corridor alignment.
+func addExtraCycleBridges(g Grid, specs []rectSpec, world *World, occ map[cell]bool) {
+	n := len(specs) // only original rectangles
+DIRS:
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			// If they are placed with a 2-cell gap on any side, placeAround(spec[i], specs[j], d)
+			// will reproduce specs[j] exactly. Use that to detect adjacency.
+			for _, d := range []Dir{Right, Left, Up, Down} {
+				pr := placeAround(specs[i], specs[j].cols, specs[j].rows, d)
+				// exact match?
+				if pr.specB.gx != specs[j].gx || pr.specB.gy != specs[j].gy ||
+					pr.specB.cols != specs[j].cols || pr.specB.rows != specs[j].rows {
+					continue
+				}
+				// corridor must be free and not already used by another bridge/shop
+				if anyOccupied(occ, pr.bridge1, pr.bridge2) {
+					continue
+				}
+				// mid tiles on each loop
+				ai := findTileIndexAtCell(g, world.Loops[i], pr.aMidX, pr.aMidY)
+				bi := findTileIndexAtCell(g, world.Loops[j], pr.bMidX, pr.bMidY)
+				if ai < 0 || bi < 0 {
+					continue
+				}
+
+				// build the bridge loop
+				bridge := makeBridge(g, pr.bridge1.X, pr.bridge1.Y, pr.bridge2.X, pr.bridge2.Y)
+				world.Loops = append(world.Loops, bridge)
+				bridgeIdx := len(world.Loops) - 1
+				finalizeLoopIndices(world)
+
+				// link ends: i <-> bridge[0], j <-> bridge[1]
+				world.Loops[bridgeIdx].Tiles[0].Links = []TileID{{Loop: i, Index: ai}}
+				world.Loops[bridgeIdx].Tiles[1].Links = []TileID{{Loop: j, Index: bi}}
+				world.Loops[i].Tiles[ai].Links = append(world.Loops[i].Tiles[ai].Links, TileID{Loop: bridgeIdx, Index: 0})
+				world.Loops[j].Tiles[bi].Links = append(world.Loops[j].Tiles[bi].Links, TileID{Loop: bridgeIdx, Index: 1})
+
+				// reserve the corridor cells so nothing else uses them
+				markCells(occ, pr.bridge1, pr.bridge2)
+
+				// We found/added the closing bridge for (i,j); don't try other dirs for this pair.
+				continue DIRS
+			}
+		}
+	}
+}
+
+// BFS over Links only (zero-cost moves)
+func collectLinkClosure(start TileID, w *World) []TileID {
+	q := []TileID{start}
+	seen := map[TileID]bool{start: true}
+	out := []TileID{start}
+	for len(q) > 0 {
+		cur := q[0]
+		q = q[1:]
+		t := &w.Loops[cur.Loop].Tiles[cur.Index]
+		for _, nb := range t.Links {
+			if !seen[nb] {
+				seen[nb] = true
+				out = append(out, nb)
+				q = append(q, nb)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func buildWorld(seed int64) (World, []int) {
+	r := rand.New(rand.NewSource(seed))
+	regions, counts := RandomRegions(r)
+	return BuildWorldCountsRandom(r, regions, counts, 0), counts
+}
+
+func TestBuildWorldCountsRandom(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		w, counts := buildWorld(seed)
+		reach := reachableFromStart(&w)
+		crowns := 0
+		for li, l := range w.Loops {
+			if li < len(counts) && len(l.Tiles) != counts[li] {
+				t.Errorf("seed %d: loop %d has %d tiles, want %d", seed, li, len(l.Tiles), counts[li])
+			}
+			if l.Type.Crown {
+				crowns++
+			}
+			for ti, tile := range l.Tiles {
+				id := TileID{li, ti}
+				if next := tile.Next; next.Loop != li || l.Tiles[next.Index].Prev != id {
+					t.Errorf("seed %d: %v and its next tile %v are not wired both ways", seed, id, next)
+				}
+				for _, nb := range tile.Links {
+					if !slices.Contains(w.Loops[nb.Loop].Tiles[nb.Index].Links, id) {
+						t.Errorf("seed %d: %v links to %v but not back", seed, id, nb)
+					}
+				}
+				if tile.Bridge && len(tile.Links) != 1 {
+					t.Errorf("seed %d: bridge tile %v has %d links, want 1", seed, id, len(tile.Links))
+				}
+				if shop := tile.ShopData; shop != nil {
+					for i, c := range shop.Cards {
+						if c.Type == "" || shop.Prices[i] <= 0 {
+							t.Errorf("seed %d: %s shelf %d holds %v for %d", seed, shop.Name, i, c.Title, shop.Prices[i])
+						}
+					}
+				}
+			}
+			if li < len(counts) && !l.Type.Crown && !reach[TileID{li, 0}] {
+				t.Errorf("seed %d: loop %d (%s) cannot be reached", seed, li, l.Type.Name)
+			}
+		}
+		if crowns != 1 {
+			t.Errorf("seed %d: %d loops are The Crown, want 1", seed, crowns)
+		}
+	}
+}
+
+func TestBuildWorldCountsRandomIsDeterministic(t *testing.T) {
+	a, _ := buildWorld(7)
+	b, _ := buildWorld(7)
+	ja, err := json.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ja, jb) {
+		t.Fatal("the same seed built two different worlds")
+	}
+}
@@ -2,385 +2,126 @@ package main
 
 import (
 	"fmt"
-	"math/rand"
-	"sort"
+	"strings"
 
+	"github.com/Meduza3/talisman/engine"
+	"github.com/Meduza3/talisman/netplay"
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
-const stepDelay = 0.18 // seconds between tile steps while resolving a roll
-type Phase int
-
-const (
-	PhaseIdle Phase = iota
-	PhaseTargetSelect
-	PhaseAnimating
-)
-
+// Game is the engine state plus everything the front-end keeps between
+// frames: menu cursors, button hit boxes and animation timers.
 type Game struct {
-	Player         Player
-	World          *World
-	Turn           int
-	LastRoll       int
-	StepsRemaining int
-	stepAccum      float32
+	*engine.Game
 
-	Dir   int   // +1 = CW (Right/Next), -1 = CCW (Left/Prev)
-	Phase Phase // Idle -> ChooseDir -> Animating
+	Selected int // cursor into Dests while picking a landing spot
 
-	// selection
-	Dests    []TileID // all valid landing spots this turn
-	Selected int      // index into Dests
-	Path     []TileID // animation path after confirm (excluding current tile)
+	// Replay playback (nil when a human is playing)
+	Replay     *engine.Replay
+	replayWait float32
 
-	CardActive   bool
-	Card         Card
-	CardResolved bool
-	Log          []string // newest last
+	botWait float32 // time since the current bot's last decision
 
-	CardMsg string // result message after Interact
+	// Network play (nil in a local game). The embedded engine.Game is then
+	// the client's mirror of the server's game.
+	Net *netplay.Client
+
+	Status string // why the last action was rejected, or a connection problem; shown in the HUD
 
 	// Inventory menu
-	InventoryActive      bool
-	StrengthButtonBounds rl.Rectangle
-	MagicButtonBounds    rl.Rectangle
-	InventorySelectedIndex int // Selected item index: 0-N for cards, then buttons
+	InventoryActive           bool
+	StrengthButtonBounds      rl.Rectangle
+	MagicButtonBounds         rl.Rectangle
+	SpellButtonBounds         rl.Rectangle
+	InventorySelectedIndex    int // Selected item index: 0-N for cards, then buttons, then spells
 	InventoryCardScrollOffset int // For scrolling through cards
 
+	// Teleport target picker, open while a teleport spell is being aimed
+	Teleporting      bool
+	TeleportSlot     int // index into the hero's Spells
+	TeleportDests    []engine.TileID
+	TeleportSelected int
+
 	// Shop inventory
-	ShopActive     bool
-	ShopCards      [3]Card
-	ShopPrices     [3]int
-	ShopSelected   int
+	ShopSelected     int
 	ExitButtonBounds rl.Rectangle
 	LastPurchaseTime float32 // For shopkeeper animation
-}
-
-// keep only the last N lines
-const logMax = 8
-
-func (g *Game) logf(format string, args ...any) {
-	g.Log = append(g.Log, fmt.Sprintf(format, args...))
-	if len(g.Log) > logMax {
-		g.Log = g.Log[len(g.Log)-logMax:]
-	}
-}
-func (g *Game) CanRoll() bool { return g.Phase == PhaseIdle }
-
-func (g *Game) Roll() {
-	g.LastRoll = rand.Intn(6) + 1
-	g.StepsRemaining = g.LastRoll
-	g.Turn++
 
-	// build destinations immediately (both directions; bridges allowed; no shops)
-	g.Dests = gatherAllLandingSpots(g.Player.At, g.StepsRemaining, g.World) // see below
-	g.Selected = 0
-	g.Path = nil
-	g.stepAccum = 0
-	g.Phase = PhaseTargetSelect
+	// Load-game screen
+	LoadActive   bool
+	LoadSaves    []engine.SaveInfo
+	LoadSelected int
 }
 
-func (g *Game) Update(dt float32, w *World) {
-	if g.Phase != PhaseAnimating || g.StepsRemaining <= 0 {
-		return
-	}
-	g.stepAccum += dt
-	for g.StepsRemaining > 0 && g.stepAccum >= stepDelay {
-		if len(g.Path) > 0 {
-			g.Player.At = g.Path[0]
-			g.Path = g.Path[1:]
+// newGame seats the human heroes first and then one computer hero per
+// personality in bots.
+func newGame(seed int64, humans int, bots []engine.Personality, death engine.DeathRule) *engine.Game {
+	g := engine.NewGame(seed, humans+len(bots))
+	g.DeathRule = death
+	for i, b := range bots {
+		if seat := humans + i; seat < len(g.Players) {
+			g.Players[seat].Bot = b
 		}
-		g.StepsRemaining--
-		g.stepAccum -= stepDelay
-	}
-	if g.StepsRemaining == 0 {
-		// Spawn a card for the tile we just landed on
-		curLoop := w.Loops[g.Player.At.Loop]
-		g.Card = DrawFromDeck(curLoop.Type.Deck)
-		g.CardActive = true
-		g.CardResolved = false
-		g.CardMsg = ""
-		g.Phase = PhaseIdle // keep idle for input; modal will capture keys
-		g.Dests = nil
-		g.Path = nil
-	}
-}
-
-// DrawFromDeck returns a random card from the deck (fallback to RandCard if empty).
-func DrawFromDeck(d Deck) Card {
-	if len(d.Cards) == 0 {
-		return RandCard()
-	}
-	return d.Cards[rand.Intn(len(d.Cards))]
-}
-
-func isShopTile(w *World, id TileID) bool {
-	t := w.Loops[id.Loop].Tiles[id.Index]
-	if t.Shop { // explicit flag for 1-tile shop loops
-		return true
 	}
-	// if ever you mark perimeter tiles as Shop in future, this still works
-	return false
+	return g
 }
 
-func b2i(b bool) int {
-	if b {
-		return 1
+// rematch starts a fresh run with the same seats and rules as g.
+func rematch(g *engine.Game, seed int64) *engine.Game {
+	next := engine.NewGame(seed, len(g.Players))
+	next.DeathRule = g.DeathRule
+	next.Debug = g.Debug
+	for i := range g.Players {
+		next.Players[i].Bot = g.Players[i].Bot
 	}
-	return 0
+	return next
 }
 
-// helper: is this a bridge tile?
-func isBridgeTile(w *World, id TileID) bool {
-	return w.Loops[id.Loop].Tiles[id.Index].Bridge
-}
-
-// helper: next/prev step along the loop in a fixed direction
-func stepAlongDir(w *World, id TileID, dir int) TileID {
-	if dir >= 0 {
-		return w.Loops[id.Loop].Tiles[id.Index].Next
-	}
-	return w.Loops[id.Loop].Tiles[id.Index].Prev
-}
-
-// Bridge FSM phases while searching a single roll.
-const (
-	bridgeNone   = 0 // not on a bridge, not in a forced sequence
-	bridgePhase1 = 1 // just stepped ONTO the first bridge tile -> must go to the other bridge tile next
-	bridgePhase2 = 2 // now on the second bridge tile -> must EXIT to its linked perimeter tile
-)
-
-// BFS in a fixed direction. Every hop costs 1 step.
-// Returns: set of legal endpoints (not shops, not bridge tiles) and a parent map to backtrack one path.
-func bfsFixedDir(w *World, start TileID, steps int, dir int) (endpoints map[TileID]bool, parent map[TileID]TileID) {
-	type State struct {
-		id          TileID
-		k           int  // steps left
-		usedBridge  bool // true once we've stepped onto any bridge tile this roll
-		bridgePhase int  // bridgeNone / bridgePhase1 / bridgePhase2
-	}
-
-	// queue & visited (include usedBridge and bridgePhase in the key)
-	queue := []State{{id: start, k: steps, usedBridge: false, bridgePhase: bridgeNone}}
-	seen := map[[5]int]bool{
-		{start.Loop, start.Index, steps, 0, bridgeNone}: true,
-	}
-
-	parent = map[TileID]TileID{}  // store one predecessor per tile (enough to backtrack a shortest path)
-	endpoints = map[TileID]bool{} // tiles reachable with exactly 0 steps
-
-	for len(queue) > 0 {
-		cur := queue[0]
-		queue = queue[1:]
-
-		// If no steps left, we can end here (but never on shop or bridge tiles).
-		if cur.k == 0 {
-			if !isShopTile(w, cur.id) && !isBridgeTile(w, cur.id) {
-				endpoints[cur.id] = true
-			}
+// parseBots reads the -bots flag: a comma-separated list of personalities.
+func parseBots(s string) ([]engine.Personality, error) {
+	var bots []engine.Personality
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
 			continue
 		}
-
-		// Current tile info
-		ct := w.Loops[cur.id.Loop].Tiles[cur.id.Index]
-		onBridge := ct.Bridge
-
-		// Generate candidate neighbors BEFORE gating:
-		stepNeighbor := stepAlongDir(w, cur.id, dir) // along-loop step in fixed dir
-
-		// Candidate link neighbors from here (shops will be filtered out)
-		linkNeighbors := ct.Links
-
-		// Now apply the movement rules / gating based on the bridge FSM.
-		enqueue := func(nextID TileID, nextK int, used bool, phase int) {
-			key := [5]int{nextID.Loop, nextID.Index, nextK, btoi(used), phase}
-			if seen[key] {
-				return
-			}
-			seen[key] = true
-			// store parent only once (shortest due to BFS)
-			if _, ok := parent[nextID]; !ok {
-				parent[nextID] = cur.id
-			}
-			queue = append(queue, State{id: nextID, k: nextK, usedBridge: used, bridgePhase: phase})
+		p, err := engine.ParsePersonality(name)
+		if err != nil {
+			return nil, err
 		}
-
-		switch cur.bridgePhase {
-		case bridgeNone:
-			// Normal movement: along the loop (if not a shop), and possibly enter a bridge (if not used yet).
-			// 1) along-loop step
-			if !isShopTile(w, stepNeighbor) && !isBridgeTile(w, stepNeighbor) {
-				enqueue(stepNeighbor, cur.k-1, cur.usedBridge, bridgeNone)
-			}
-
-			// 2) follow any links that are legal
-			for _, nb := range linkNeighbors {
-				// skip shops
-				if isShopTile(w, nb) {
-					continue
-				}
-				if isBridgeTile(w, nb) {
-					// entering a bridge is allowed ONLY if we haven't used one yet
-					if cur.usedBridge {
-						continue
-					}
-					// Step ONTO the first bridge tile (cost 1), must go to the other bridge tile next.
-					enqueue(nb, cur.k-1, true /*used now*/, bridgePhase1)
-				} else {
-					// non-bridge, non-shop link (rare in your map, but keep rule clean)
-					enqueue(nb, cur.k-1, cur.usedBridge, bridgeNone)
-				}
-			}
-
-			// Edge case: if we *start* already standing on a bridge tile, force the same logic as Phase1.
-			// (This shouldn't happen in normal flow, but keeps the search robust.)
-			if onBridge {
-				// The bridge loop has next/prev both pointing to the other bridge tile,
-				// so just force the "go to other bridge tile" step.
-				other := stepNeighbor
-				if !isShopTile(w, other) && isBridgeTile(w, other) {
-					enqueue(other, cur.k-1, true, bridgePhase2)
-				}
-			}
-
-		case bridgePhase1:
-			// We are on the FIRST bridge tile: the ONLY legal move is to the other bridge tile via loop step.
-			other := stepNeighbor // in the 2-tile bridge loop, this is the other bridge tile
-			if isBridgeTile(w, other) {
-				enqueue(other, cur.k-1, cur.usedBridge, bridgePhase2)
-			}
-			// No links allowed from this tile in this phase (can't hop off mid-bridge).
-
-		case bridgePhase2:
-			// We are on the SECOND bridge tile: the ONLY legal move is to EXIT via its link to perimeter.
-			for _, nb := range linkNeighbors {
-				if isShopTile(w, nb) {
-					continue
-				}
-				// The exit must be to a NON-bridge tile.
-				if isBridgeTile(w, nb) {
-					continue
-				}
-				enqueue(nb, cur.k-1, cur.usedBridge, bridgeNone)
-			}
-			// Do NOT allow stepping along the tiny bridge loop here (would bounce back).
-		}
-	}
-
-	return
-}
-
-func btoi(b bool) int {
-	if b {
-		return 1
-	}
-	return 0
-}
-
-// union of CW and CCW endpoints + remember a parent map for later path extraction
-type reachResult struct {
-	endpoints map[TileID]bool
-	parentCW  map[TileID]TileID
-	parentCCW map[TileID]TileID
-}
-
-// run both directions and merge
-func reachBothDirs(w *World, start TileID, steps int) reachResult {
-	endsCW, pCW := bfsFixedDir(w, start, steps, +1)
-	endsCC, pCC := bfsFixedDir(w, start, steps, -1)
-	union := map[TileID]bool{}
-	for id := range endsCW {
-		union[id] = true
-	}
-	for id := range endsCC {
-		union[id] = true
-	}
-	// never include the start as a “destination” if steps>0
-	if steps > 0 {
-		delete(union, start)
+		bots = append(bots, p)
 	}
-	return reachResult{endpoints: union, parentCW: pCW, parentCCW: pCC}
+	return bots, nil
 }
 
-// gather + stable order for UI
-func gatherAllLandingSpots(start TileID, steps int, w *World) []TileID {
-	r := reachBothDirs(w, start, steps)
-	out := make([]TileID, 0, len(r.endpoints))
-	for id := range r.endpoints {
-		out = append(out, id)
-	}
-	// stable-ish ordering: loop asc, index asc
-	sort.Slice(out, func(i, j int) bool {
-		if out[i].Loop != out[j].Loop {
-			return out[i].Loop < out[j].Loop
-		}
-		return out[i].Index < out[j].Index
-	})
-	return out
-}
-
-func buildPathTo(w *World, start TileID, steps int, goal TileID) []TileID {
-	endsCW, pCW := bfsFixedDir(w, start, steps, +1)
-	if endsCW[goal] {
-		return backtrackPath(pCW, start, goal)
-	}
-	endsCC, pCC := bfsFixedDir(w, start, steps, -1)
-	if endsCC[goal] {
-		return backtrackPath(pCC, start, goal)
-	}
-	return nil
-}
-
-func backtrackPath(parent map[TileID]TileID, start, goal TileID) []TileID {
-	// parent maps each node to *a* predecessor; rebuild then reverse.
-	cur := goal
-	path := []TileID{cur}
-	for cur != start {
-		p, ok := parent[cur]
-		if !ok {
-			break
-		}
-		cur = p
-		path = append(path, cur)
-	}
-	// reverse to be start->goal, and drop the first (=start) because you’re already there
-	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
-		path[i], path[j] = path[j], path[i]
-	}
-	if len(path) > 0 && path[0] == start {
-		return path[1:]
+// startTeleport aims the teleport spell in slot: the player picks one of
+// the tiles beside a discovered shop before it is cast.
+func (g *Game) startTeleport(slot int) {
+	if err := g.CanCast(slot); err != nil {
+		g.Status = fmt.Sprintf("%s needs a discovered shop and an unrolled turn", g.Current().Spells[slot].Title)
+		return
 	}
-	return path
+	g.Teleporting = true
+	g.TeleportSlot = slot
+	g.TeleportDests = g.Game.TeleportDests()
+	g.TeleportSelected = 0
+	g.InventoryActive = false
 }
 
-// small helpers
-func stepDir(id TileID, dir int, w *World) TileID {
-	if dir >= 0 {
-		return w.Loops[id.Loop].Tiles[id.Index].Next
+// act applies a player decision. A rejected action leaves the game as it
+// was, and the reason goes to Status for the HUD. Online the decision goes
+// to the server instead and its answer shows up a few frames later.
+func (g *Game) act(a engine.Action) bool {
+	g.Status = ""
+	if g.Net != nil {
+		return g.Net.Send(a) == nil
 	}
-	return w.Loops[id.Loop].Tiles[id.Index].Prev
-}
-
-func destAfterSteps(start TileID, steps, dir int, w *World) TileID {
-	cur := start
-	for i := 0; i < steps; i++ {
-		cur = stepDir(cur, dir, w)
+	if err := g.Apply(a); err != nil {
+		g.Status = err.Error()
+		return false
 	}
-	return cur
+	return true
 }
 
-// Initialize shop from persistent shop data
-func (g *Game) InitShop(shopData *ShopType) {
-	// Load from persistent shop data
-	for i := 0; i < 3; i++ {
-		g.ShopCards[i] = shopData.Cards[i]
-		g.ShopPrices[i] = shopData.Prices[i]
-	}
-	g.ShopSelected = 0
-	
-	// Mark shop as discovered
-	if !shopData.Discovered {
-		shopData.Discovered = true
-		g.logf("Discovered %s!", shopData.Name)
-	}
-}
+// vec converts an engine position to a raylib vector.
+func vec(p engine.Vec2) rl.Vector2 { return rl.Vector2(p) }
@@ -1,12 +1,19 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"log"
 	"math"
 	"math/rand"
+	"os"
 	"runtime"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/Meduza3/talisman/engine"
+	"github.com/Meduza3/talisman/netplay"
 	_ "github.com/gen2brain/raylib-go/raygui"
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
@@ -14,15 +21,15 @@ import (
 const (
 	screenWidth  = 1600
 	screenHeight = 900
-	tileSize     = 44
+	tileSize     = engine.TileSize
 	fontSize     = 14
 	tilePad      = 10 // spacing between tiles around a loop (affects loop radius)
 	margin       = 80 // keep loops away from window edges
 	loopGap      = 30 // minimum clearance between different loops (circle-to-circle)
 	menuHeight   = 120
 
-	crossLinkChance = 0.95
-	shopChance      = 0.20
+	replayDelay = 0.4 // seconds between actions when playing back a recording
+	botDelay    = 0.5 // seconds a computer hero "thinks" before each decision
 )
 
 var gameFont rl.Font
@@ -35,17 +42,42 @@ func drawText(text string, x, y int32, fontSize int32, color rl.Color) {
 }
 
 // Helper function to get card name for logging
-func cardName(c *Card) string {
+func cardName(c *engine.Card) string {
 	switch c.Type {
-	case monsterType:
+	case engine.MonsterType:
 		return fmt.Sprintf("Monster(STR %d)", c.Strength)
-	case magicMonsterType:
-		return fmt.Sprintf("Magic Monster(MAG %d)", c.Magic)
-	case buffType:
+	case engine.MagicMonsterType:
+		return fmt.Sprintf("Magic Monster(MAG %d, fought with Magic)", c.Magic)
+	case engine.BuffType:
 		if c.Strength > 0 {
 			return fmt.Sprintf("Buff(+%d STR)", c.Strength)
 		}
 		return fmt.Sprintf("Buff(+%d MAG)", c.Magic)
+	case engine.TalismanType:
+		return "Talisman"
+	case engine.CrownType:
+		return fmt.Sprintf("Crown Guardian(STR %d)", c.Strength)
+	case engine.SpellType:
+		return fmt.Sprintf("Spell(%s)", c.Spell.Effect())
+	case engine.PotionType:
+		if c.Status.Kind != "" {
+			return fmt.Sprintf("Potion(+%d HP, %s)", c.Heal, c.Status)
+		}
+		return fmt.Sprintf("Potion(+%d HP)", c.Heal)
+	case engine.FollowerType:
+		return fmt.Sprintf("Follower(%s)", c.Follower.Effect())
+	case engine.EventType:
+		return fmt.Sprintf("Event(%s)", c.Effect.Describe())
+	case engine.StrangerType:
+		return fmt.Sprintf("Stranger(%d offers)", len(c.Offers))
+	case engine.PlaceType:
+		return fmt.Sprintf("Place(%d outcomes)", len(c.Table))
+	case engine.TrapType:
+		return fmt.Sprintf("Trap(%s %d)", c.CheckStat(), c.Difficulty())
+	case engine.TreasureType:
+		return fmt.Sprintf("Treasure(%d gold)", c.Gold)
+	case engine.ScriptedType:
+		return fmt.Sprintf("Scripted(%s)", c.Script)
 	}
 	return "Unknown Card"
 }
@@ -53,6 +85,49 @@ func cardName(c *Card) string {
 func main() {
 	runtime.LockOSThread() // <-- must be first on macOS
 
+	if len(os.Args) > 1 && os.Args[1] == "sim" {
+		runSim(os.Args[2:])
+		return
+	}
+
+	seed := flag.Int64("seed", 0, "RNG seed for a reproducible run (0 = pick one from the clock)")
+	replayPath := flag.String("replay", "", "play back a recording written to "+engine.ReplayDir+"/")
+	players := flag.Int("players", 1, fmt.Sprintf("number of human heroes taking turns at this keyboard (up to %d with -bots)", engine.MaxPlayers))
+	botsFlag := flag.String("bots", "", "comma-separated personalities for computer heroes seated after the humans: cautious, greedy, reckless")
+	dataDir := flag.String("data", "", "load decks and regions from this directory instead of the built-in data (e.g. engine/data)")
+	serve := flag.String("serve", "", "host a LAN game on this address (e.g. :"+netplay.DefaultPort+") without opening a window")
+	connect := flag.String("connect", "", "join a LAN game at host[:port]")
+	deathFlag := flag.String("death", string(engine.Respawn), "what happens to a fallen hero: respawn (lose half gold and trophies) or permadeath")
+	debug := flag.Bool("debug", false, "allow the sandbox moves: Space makes a new world, the arrows walk the loop and E follows a link")
+	flag.Parse()
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	if *dataDir != "" {
+		if err := engine.LoadData(*dataDir); err != nil {
+			log.Fatal(err)
+		}
+	}
+	bots, err := parseBots(*botsFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	death, err := engine.ParseDeathRule(*deathFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *serve != "" {
+		runServer(*serve, newGame(*seed, *players, bots, death))
+		return
+	}
+	var client *netplay.Client
+	if *connect != "" {
+		if client, err = netplay.Dial(withPort(*connect)); err != nil {
+			log.Fatal(err)
+		}
+		defer client.Close()
+	}
+
 	rl.InitWindow(int32(screenWidth), int32(screenHeight), "Talisman")
 	defer rl.CloseWindow()
 	rl.SetTargetFPS(60)
@@ -65,22 +140,32 @@ func main() {
 	gameFont = rl.LoadFont("assets/mediaval_font.otf")
 	// gameFont = rl.GetFontDefault()
 
-	numOfLoops := rand.Intn(30) + 20
-	loopCounts := []int{}
-	for range numOfLoops {
-		loopCounts = append(loopCounts, rand.Intn(20)+8)
+	game := Game{Game: newGame(*seed, *players, bots, death)}
+	game.Debug = *debug
+	if *replayPath != "" {
+		rec, err := engine.LoadRecording(*replayPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		g, replay := engine.StartReplay(rec)
+		game = Game{Game: g, Replay: replay}
+		game.Logf("Replaying %s (%d actions)", *replayPath, len(rec.Actions))
 	}
-	world := BuildWorldCountsRandom(loopCounts)
-
-	finalizeLoopIndices(&world)
-
-	game := Game{
-		Player: *NewPlayer(TileID{0, 0}, 3, 3, 5),
-		World:  &world,
+	if client != nil {
+		game = Game{Game: client.Game, Net: client}
 	}
+	// Keep the last run's actions on disk even if we crash, so it can be replayed.
+	defer func() {
+		if game.Net != nil {
+			return // the server keeps the recording
+		}
+		if _, err := game.SaveRecording(engine.ReplayDir, "last.json"); err != nil {
+			log.Printf("writing recording: %v", err)
+		}
+	}()
 	// Center the camera on the playfield above the menu bar
 	cam = rl.Camera2D{
-		Target: playerPos(&world, &game),
+		Target: playerPos(game.World, &game),
 		// Offset is where the Target appears on the screen (we want it centered horizontally,
 		// and vertically centered in the play area above the menu).
 		Offset:   rl.NewVector2(float32(screenWidth)/2, float32(screenHeight-menuHeight)/2),
@@ -100,29 +185,166 @@ func main() {
 		if rl.IsKeyPressed(rl.KeyZero) {
 			cam.Zoom = 1.0
 		}
-		updateCamera(&cam, playerPos(&world, &game), dt)
+		updateCamera(&cam, playerPos(game.World, &game), dt)
+
+		if game.Net != nil {
+			if err := game.Net.Poll(); err != nil {
+				game.Status = err.Error()
+			}
+			// saves, loads and debug moves belong to whoever runs the server
+			goto NET_INPUT
+		}
+
+		if rl.IsKeyPressed(rl.KeySpace) && game.Replay == nil && game.Debug {
+			game.act(engine.Action{Kind: engine.ActNewWorld})
+		}
+
+		// Save / load hotkeys
+		if rl.IsKeyPressed(rl.KeyF5) && !game.LoadActive {
+			if path, err := game.SaveFile(engine.SaveDir); err != nil {
+				game.Logf("Save failed: %v", err)
+			} else {
+				game.Logf("Game saved to %s", path)
+			}
+		}
+		if rl.IsKeyPressed(rl.KeyF6) {
+			name := fmt.Sprintf("seed%d-turn%d.json", game.Seed, game.Turn)
+			if path, err := game.SaveRecording(engine.ReplayDir, name); err != nil {
+				game.Logf("Recording failed: %v", err)
+			} else {
+				game.Logf("Recording written to %s", path)
+			}
+		}
+		if rl.IsKeyPressed(rl.KeyF9) && !game.LoadActive {
+			saves, err := engine.ListSaves(engine.SaveDir)
+			if err != nil {
+				game.Logf("Cannot list saves: %v", err)
+			}
+			game.LoadActive = true
+			game.LoadSaves = saves
+			game.LoadSelected = 0
+		}
+		// Handle load screen input
+		if game.LoadActive {
+			if rl.IsKeyPressed(rl.KeyEscape) || rl.IsKeyPressed(rl.KeyF9) {
+				game.LoadActive = false
+			}
+			if n := len(game.LoadSaves); n > 0 {
+				if rl.IsKeyPressed(rl.KeyDown) {
+					game.LoadSelected = (game.LoadSelected + 1) % n
+				}
+				if rl.IsKeyPressed(rl.KeyUp) {
+					game.LoadSelected = (game.LoadSelected - 1 + n) % n
+				}
+				if rl.IsKeyPressed(rl.KeyEnter) || rl.IsKeyPressed(rl.KeyKpEnter) {
+					sel := game.LoadSaves[game.LoadSelected]
+					loaded, err := engine.LoadFile(sel.Path)
+					if err != nil {
+						game.Logf("Load failed: %v", err)
+					} else {
+						// fresh UI state for the loaded game
+						game = Game{Game: loaded}
+						game.Logf("Loaded %s", sel.Path)
+						cam.Target = playerPos(game.World, &game)
+					}
+				}
+			}
+			goto AFTER_INPUT
+		}
+
+		// A replay drives the game instead of the keyboard
+		if game.Replay != nil {
+			game.replayWait += dt
+			if game.replayWait >= replayDelay {
+				applied, err := game.Replay.Step(game.Game)
+				if err != nil {
+					game.Logf("Replay stopped: %v", err)
+					game.Replay = nil
+				} else if applied {
+					game.replayWait = 0
+					if game.Replay.Done() {
+						game.Logf("Replay finished - you have control")
+						game.Replay = nil
+					}
+				}
+			}
+			goto AFTER_INPUT
+		}
+
+		// Computer heroes play through the same actions as the keyboard
+		if bot := game.Current().Bot; bot != "" && !(game.Over() && !game.CardActive) {
+			game.botWait += dt
+			if game.Ready() && game.botWait >= botDelay {
+				game.botWait = 0
+				if !game.act(bot.NextAction(game.Game)) {
+					game.Logf("%s bot is stuck - handing the hero to the keyboard", bot)
+					game.Current().Bot = ""
+				}
+			}
+			goto AFTER_INPUT
+		}
+
+	NET_INPUT:
+		if game.Net != nil && !game.Net.MyTurn() {
+			goto AFTER_INPUT
+		}
+
+		// Victory screen: start over
+		if game.Winner() != nil && !game.CardActive {
+			if game.Net == nil && (rl.IsKeyPressed(rl.KeyEnter) || rl.IsKeyPressed(rl.KeyKpEnter)) {
+				game = Game{Game: rematch(game.Game, time.Now().UnixNano())}
+				cam.Target = playerPos(game.World, &game)
+			}
+			goto AFTER_INPUT
+		}
+
+		// Defeat screen: respawn, pass the dice, or start over
+		if game.Current().Dead && !game.CardActive {
+			if rl.IsKeyPressed(rl.KeyEnter) || rl.IsKeyPressed(rl.KeyKpEnter) {
+				switch {
+				case game.Over() && game.Net == nil:
+					game = Game{Game: rematch(game.Game, time.Now().UnixNano())}
+					cam.Target = playerPos(game.World, &game)
+				case game.Over():
+				case game.DeathRule == engine.Respawn:
+					game.act(engine.Action{Kind: engine.ActRespawn})
+				default:
+					game.act(engine.Action{Kind: engine.ActEndTurn})
+				}
+			}
+			goto AFTER_INPUT
+		}
 
-		if rl.IsKeyPressed(rl.KeySpace) {
-			numOfLoops := rand.Intn(30) + 20
-			loopCounts := []int{}
-			for range numOfLoops {
-				loopCounts = append(loopCounts, rand.Intn(20)+8)
+		// Aiming a teleport: pick the shop to step out beside
+		if game.Teleporting {
+			if n := len(game.TeleportDests); n > 0 {
+				if rl.IsKeyPressed(rl.KeyRight) || rl.IsKeyPressed(rl.KeyDown) {
+					game.TeleportSelected = (game.TeleportSelected + 1) % n
+				}
+				if rl.IsKeyPressed(rl.KeyLeft) || rl.IsKeyPressed(rl.KeyUp) {
+					game.TeleportSelected = (game.TeleportSelected - 1 + n) % n
+				}
+				if rl.IsKeyPressed(rl.KeyEnter) || rl.IsKeyPressed(rl.KeyKpEnter) {
+					game.act(engine.Action{Kind: engine.ActCastSpell, Slot: game.TeleportSlot, Tile: game.TeleportDests[game.TeleportSelected]})
+					game.Teleporting = false
+				}
 			}
-			world = BuildWorldCountsRandom(loopCounts)
-			// reset player to safe starting position
-			game.Player.At = TileID{0, 0}
-			game.StepsRemaining = 0
-			game.Phase = PhaseIdle
+			if rl.IsKeyPressed(rl.KeyEscape) {
+				game.Teleporting = false
+			}
+			goto AFTER_INPUT
 		}
+
 		// Handle inventory input
 		if game.InventoryActive {
 			if rl.IsKeyPressed(rl.KeyEscape) || rl.IsKeyPressed(rl.KeyQ) {
 				game.InventoryActive = false
 			}
 
-			// Calculate total items (cards + 2 exchange buttons)
-			totalCards := len(game.Player.Cards)
-			totalItems := totalCards + 2 // cards + 2 exchange buttons
+			// Calculate total items (cards + 3 exchange buttons + spells + equipment slots + followers)
+			totalCards := len(game.Current().Cards)
+			totalSpells := len(game.Current().Spells)
+			totalItems := totalCards + 3 + totalSpells + len(engine.EquipSlots) + len(game.Current().Followers)
 
 			// Handle horizontal navigation with left/right arrows
 			if rl.IsKeyPressed(rl.KeyLeft) {
@@ -158,48 +380,67 @@ func main() {
 			if rl.IsKeyPressed(rl.KeyEnter) || rl.IsKeyPressed(rl.KeyKpEnter) {
 				if game.InventorySelectedIndex < totalCards {
 					// Selected item is a card - use it if it's a shop item or buff
-					selectedCard := game.Player.Cards[game.InventorySelectedIndex]
-					if selectedCard.Type == shopItemType {
-						// Shop items do nothing when used from inventory (effects already applied at purchase)
-						game.logf("You examine %s - its power has already been absorbed.", selectedCard.Title)
-
+					selectedCard := game.Current().Cards[game.InventorySelectedIndex]
+					if selectedCard.Type == engine.ShopItemType {
+						game.act(engine.Action{Kind: engine.ActEquip, Slot: game.InventorySelectedIndex})
+					}
+					if selectedCard.Type == engine.PotionType && game.act(engine.Action{Kind: engine.ActUseItem, Slot: game.InventorySelectedIndex}) {
+						game.InventorySelectedIndex = max(0, min(game.InventorySelectedIndex, totalItems-2))
 					}
 				} else if game.InventorySelectedIndex == totalCards {
 					// First exchange button (strength)
-					if game.Player.ExchangeMonsterStrength() {
-						game.logf("Exchanged monster strength for +1 STR")
-					}
+					game.act(engine.Action{Kind: engine.ActExchangeStrength})
 				} else if game.InventorySelectedIndex == totalCards+1 {
 					// Second exchange button (magic)
-					if game.Player.ExchangeMagicMonster() {
-						game.logf("Exchanged magic monster strength for +1 MAG")
+					game.act(engine.Action{Kind: engine.ActExchangeMagic})
+				} else if game.InventorySelectedIndex == totalCards+2 {
+					// Third exchange button (spell)
+					game.act(engine.Action{Kind: engine.ActExchangeSpell})
+				} else if slot := game.InventorySelectedIndex - totalCards - 3; slot < totalSpells {
+					// A spell: teleports are aimed first, the rest cast straight away
+					if game.Current().Spells[slot].Spell.Kind == engine.SpellTeleport {
+						game.startTeleport(slot)
+					} else {
+						game.act(engine.Action{Kind: engine.ActCastSpell, Slot: slot})
 					}
+					game.InventorySelectedIndex = min(game.InventorySelectedIndex, totalItems-2)
+				} else if slot -= totalSpells; slot < len(engine.EquipSlots) {
+					// An equipment slot: take the item off
+					game.act(engine.Action{Kind: engine.ActUnequip, Slot: slot})
+				} else if game.act(engine.Action{Kind: engine.ActDismiss, Slot: slot - len(engine.EquipSlots)}) {
+					// A follower: send it away
+					game.InventorySelectedIndex = min(game.InventorySelectedIndex, totalItems-2)
+				}
+			}
+
+			// X leaves the selected pack item behind
+			if rl.IsKeyPressed(rl.KeyX) && game.InventorySelectedIndex < totalCards {
+				if game.act(engine.Action{Kind: engine.ActDrop, Slot: game.InventorySelectedIndex}) {
+					game.InventorySelectedIndex = max(0, min(game.InventorySelectedIndex, totalItems-2))
 				}
 			}
 
 			// Legacy number key and mouse support
 			if rl.IsKeyPressed(rl.KeyOne) {
-				if game.Player.ExchangeMonsterStrength() {
-					game.logf("Exchanged monster strength for +1 STR")
-				}
+				game.act(engine.Action{Kind: engine.ActExchangeStrength})
 			}
 			if rl.IsKeyPressed(rl.KeyTwo) {
-				if game.Player.ExchangeMagicMonster() {
-					game.logf("Exchanged magic monster strength for +1 MAG")
-				}
+				game.act(engine.Action{Kind: engine.ActExchangeMagic})
+			}
+			if rl.IsKeyPressed(rl.KeyThree) {
+				game.act(engine.Action{Kind: engine.ActExchangeSpell})
 			}
 			// Handle mouse clicks on buttons
 			if rl.IsMouseButtonPressed(rl.MouseButtonLeft) {
 				mousePos := rl.GetMousePosition()
 				if rl.CheckCollisionPointRec(mousePos, game.StrengthButtonBounds) {
-					if game.Player.ExchangeMonsterStrength() {
-						game.logf("Exchanged monster strength for +1 STR")
-					}
+					game.act(engine.Action{Kind: engine.ActExchangeStrength})
 				}
 				if rl.CheckCollisionPointRec(mousePos, game.MagicButtonBounds) {
-					if game.Player.ExchangeMagicMonster() {
-						game.logf("Exchanged magic monster strength for +1 MAG")
-					}
+					game.act(engine.Action{Kind: engine.ActExchangeMagic})
+				}
+				if rl.CheckCollisionPointRec(mousePos, game.SpellButtonBounds) {
+					game.act(engine.Action{Kind: engine.ActExchangeSpell})
 				}
 			}
 			goto AFTER_INPUT
@@ -208,7 +449,7 @@ func main() {
 		// Handle shop input
 		if game.ShopActive {
 			if rl.IsKeyPressed(rl.KeyEscape) || rl.IsKeyPressed(rl.KeyQ) {
-				game.ShopActive = false
+				game.act(engine.Action{Kind: engine.ActCloseShop})
 			}
 			// Navigate cards with arrow keys or 1/2/3
 			if rl.IsKeyPressed(rl.KeyRight) {
@@ -241,191 +482,111 @@ func main() {
 			// Purchase with Enter or close shop if exit selected
 			if rl.IsKeyPressed(rl.KeyEnter) || rl.IsKeyPressed(rl.KeyKpEnter) {
 				if game.ShopSelected == 3 { // Exit button selected
-					game.ShopActive = false
-				} else { // Card selected
-					price := game.ShopPrices[game.ShopSelected]
-					if game.Player.Gold >= price {
-						game.Player.Gold -= price
-						purchasedCard := game.ShopCards[game.ShopSelected]
-
-						// Apply shop item effects immediately or add to inventory
-						if purchasedCard.Type == shopItemType {
-							// Apply effects immediately for shop items
-							game.Player.Strength += purchasedCard.Strength
-							game.Player.Magic += purchasedCard.Magic
-							// Add to inventory for display purposes
-							game.Player.Cards = append(game.Player.Cards, purchasedCard)
-							if purchasedCard.Title != "" {
-								effectMsg := ""
-								if purchasedCard.Strength > 0 && purchasedCard.Magic > 0 {
-									effectMsg = fmt.Sprintf(" (+%d STR, +%d MAG)", purchasedCard.Strength, purchasedCard.Magic)
-								} else if purchasedCard.Strength > 0 {
-									effectMsg = fmt.Sprintf(" (+%d STR)", purchasedCard.Strength)
-								} else if purchasedCard.Magic > 0 {
-									effectMsg = fmt.Sprintf(" (+%d MAG)", purchasedCard.Magic)
-								}
-								game.logf("Purchased %s for %d gold%s", purchasedCard.Title, price, effectMsg)
-							} else {
-								game.logf("Purchased shop item for %d gold", price)
-							}
-						} else {
-							// Regular cards go to inventory without immediate effects
-							game.Player.Cards = append(game.Player.Cards, purchasedCard)
-							game.logf("Purchased item for %d gold (added to inventory)", price)
-						}
-
-						game.LastPurchaseTime = float32(rl.GetTime()) // Trigger shopkeeper animation
-
-						// Replace purchased card with new one and update persistent shop data
-						cur := world.Loops[game.Player.At.Loop].Tiles[game.Player.At.Index]
-						var keeperType int
-						for _, link := range cur.Links {
-							if isShopTile(&world, link) {
-								shopTile := &world.Loops[link.Loop].Tiles[link.Index]
-								if shopTile.ShopData != nil {
-									keeperType = shopTile.ShopData.KeeperType
-									break
-								}
-							}
-						}
-						newCard := RandShopCard(keeperType)
-						game.ShopCards[game.ShopSelected] = newCard
-						baseCost := 0
-						if newCard.Strength > 0 && newCard.Magic > 0 {
-							baseCost = (newCard.Strength + newCard.Magic) * 4
-						} else {
-							baseCost = max(newCard.Strength, newCard.Magic) * 3
-						}
-						newPrice := baseCost + rand.Intn(5) + 1
-						game.ShopPrices[game.ShopSelected] = newPrice
-
-						// Update the persistent shop data
-						cur = world.Loops[game.Player.At.Loop].Tiles[game.Player.At.Index]
-						for _, link := range cur.Links {
-							if isShopTile(&world, link) {
-								shopTile := &world.Loops[link.Loop].Tiles[link.Index]
-								if shopTile.ShopData != nil {
-									shopTile.ShopData.Cards[game.ShopSelected] = newCard
-									shopTile.ShopData.Prices[game.ShopSelected] = newPrice
-									break
-								}
-							}
-						}
-					} else {
-						game.logf("Not enough gold! Need %d, have %d", price, game.Player.Gold)
-					}
+					game.act(engine.Action{Kind: engine.ActCloseShop})
+				} else if game.act(engine.Action{Kind: engine.ActBuy, Slot: game.ShopSelected}) { // Card selected
+					game.LastPurchaseTime = float32(rl.GetTime()) // Trigger shopkeeper animation
 				}
 			}
 			// Handle mouse clicks on exit button
 			if rl.IsMouseButtonPressed(rl.MouseButtonLeft) {
 				mousePos := rl.GetMousePosition()
 				if rl.CheckCollisionPointRec(mousePos, game.ExitButtonBounds) {
-					game.ShopActive = false
+					game.act(engine.Action{Kind: engine.ActCloseShop})
 				}
 			}
 			goto AFTER_INPUT
 		}
 
+		// Handle battle input
+		if game.CardActive && game.Battle != nil {
+			if game.InBattle() {
+				if rl.IsKeyPressed(rl.KeyA) || rl.IsKeyPressed(rl.KeyEnter) || rl.IsKeyPressed(rl.KeyKpEnter) {
+					game.act(engine.Action{Kind: engine.ActAttack})
+				}
+				if rl.IsKeyPressed(rl.KeyE) {
+					game.act(engine.Action{Kind: engine.ActEvade})
+				}
+				if rl.IsKeyPressed(rl.KeyF) {
+					game.act(engine.Action{Kind: engine.ActFlee})
+				}
+				if slot := game.Current().PotionSlot(); rl.IsKeyPressed(rl.KeyI) && slot >= 0 {
+					game.act(engine.Action{Kind: engine.ActUseItem, Slot: slot})
+				}
+				if slot := game.Current().SpellSlot(engine.SpellBattle); rl.IsKeyPressed(rl.KeyC) && slot >= 0 {
+					game.act(engine.Action{Kind: engine.ActCastSpell, Slot: slot})
+				}
+			} else if rl.IsKeyPressed(rl.KeyEnter) || rl.IsKeyPressed(rl.KeyKpEnter) || rl.IsKeyPressed(rl.KeySpace) || rl.IsKeyPressed(rl.KeyEscape) {
+				game.act(engine.Action{Kind: engine.ActCloseCard})
+			}
+			goto AFTER_INPUT
+		}
+
 		// Handle card input
 		if game.CardActive {
-			confirm, cancel := game.Card.Display()
+			confirm, cancel := displayCard(&game.Card, game.Current(), game.FightBonus)
 			if !game.CardResolved {
-				if confirm {
-					res := game.Player.Interact(&game.Card)
-
-					switch res.Card.Type {
-					case monsterType, magicMonsterType:
-						// detailed fight log
-						game.logf("Fight! You: STR %d + d6(%d) = %d", res.StrBefore, res.YourDie, res.YourTot)
-						if res.Card.Type == monsterType {
-							game.logf("       Monster: STR %d + d6(%d) = %d", res.Card.Strength, res.MonDie, res.MonTot)
-						} else {
-							game.logf("       Monster: MAG %d + d6(%d) = %d", res.Card.Magic, res.MonDie, res.MonTot)
-						}
-						switch res.Outcome {
-						case OutcomeWin:
-							goldGained := res.GoldAfter - res.GoldBefore
-							if goldGained > 0 {
-								game.logf("Result: You win and take the card. (+%d gold)", goldGained)
-							} else {
-								game.logf("Result: You win and take the card.")
-							}
-						case OutcomeLoss:
-							game.logf("Result: You lose. HP %d → %d", res.HPBefore, res.HPAfter)
-						case OutcomeTie:
-							game.logf("Result: Tie. No effect.")
-						}
-					case buffType:
-						// buff log - handle both strength and magic
-						strDelta := res.StrAfter - res.StrBefore
-						magicDelta := res.MagicAfter - res.MagicBefore
-
-						if strDelta > 0 && magicDelta > 0 {
-							game.logf("Buff: +%d STR, +%d MAG (STR %d → %d, MAG %d → %d)",
-								strDelta, magicDelta, res.StrBefore, res.StrAfter, res.MagicBefore, res.MagicAfter)
-						} else if strDelta > 0 {
-							game.logf("Buff: +%d STR (STR %d → %d)", strDelta, res.StrBefore, res.StrAfter)
-						} else if magicDelta > 0 {
-							game.logf("Buff: +%d MAG (MAG %d → %d)", magicDelta, res.MagicBefore, res.MagicAfter)
-						}
+				if slot := game.Current().SpellSlot(engine.SpellBattle); rl.IsKeyPressed(rl.KeyC) && slot >= 0 {
+					game.act(engine.Action{Kind: engine.ActCastSpell, Slot: slot})
+				}
+				for i := range game.Card.Offers {
+					if rl.IsKeyPressed(rl.KeyOne + int32(i)) {
+						game.act(engine.Action{Kind: engine.ActChoose, Slot: i})
 					}
-
-					// Show the friendly one-line toast in the modal
-					game.CardMsg = res.Message
-					game.CardResolved = true
+				}
+				if confirm {
+					game.act(engine.Action{Kind: engine.ActConfirmCard})
 				}
 				if cancel {
 					// skipped; just close
-					game.CardActive = false
+					game.act(engine.Action{Kind: engine.ActSkipCard})
 				}
 			} else {
 				// Card is resolved, close on any key EXCEPT escape unless explicitly requested
 				if rl.IsKeyPressed(rl.KeyEnter) || rl.IsKeyPressed(rl.KeyKpEnter) || rl.IsKeyPressed(rl.KeySpace) {
-					game.CardActive = false
+					game.act(engine.Action{Kind: engine.ActCloseCard})
 				}
 				// Only close with escape if user specifically wants to skip the message
 				if rl.IsKeyPressed(rl.KeyEscape) {
-					game.CardActive = false
+					game.act(engine.Action{Kind: engine.ActCloseCard})
 				}
 			}
 			goto AFTER_INPUT
 		}
 		switch game.Phase {
-		case PhaseIdle:
-			if rl.IsKeyPressed(rl.KeyR) {
-				game.Roll()
+		case engine.PhaseIdle:
+			if rl.IsKeyPressed(rl.KeyR) && game.act(engine.Action{Kind: engine.ActRoll}) {
+				game.Selected = 0
 			}
 			if rl.IsKeyPressed(rl.KeyQ) {
 				game.InventoryActive = true
 			}
-			// (optional) manual testing when idle
-			if rl.IsKeyPressed(rl.KeyRight) {
-				game.Player.At = world.Loops[game.Player.At.Loop].Tiles[game.Player.At.Index].Next
+			if rl.IsKeyPressed(rl.KeyN) {
+				game.act(engine.Action{Kind: engine.ActEndTurn})
 			}
-			if rl.IsKeyPressed(rl.KeyLeft) {
-				game.Player.At = world.Loops[game.Player.At.Loop].Tiles[game.Player.At.Index].Prev
+			if rl.IsKeyPressed(rl.KeyT) {
+				if slot := game.Current().SpellSlot(engine.SpellTeleport); slot >= 0 {
+					game.startTeleport(slot)
+				}
+			}
+			// manual testing when idle, with -debug
+			if rl.IsKeyPressed(rl.KeyRight) && game.Debug {
+				game.act(engine.Action{Kind: engine.ActStep, Dir: +1})
+			}
+			if rl.IsKeyPressed(rl.KeyLeft) && game.Debug {
+				game.act(engine.Action{Kind: engine.ActStep, Dir: -1})
 			}
 			if rl.IsKeyPressed(rl.KeyE) {
-				cur := world.Loops[game.Player.At.Loop].Tiles[game.Player.At.Index]
 				// Check if player is standing next to a shop (through links)
-				shopFound := false
-				for _, link := range cur.Links {
-					if isShopTile(&world, link) {
-						shopTile := &world.Loops[link.Loop].Tiles[link.Index]
-						if shopTile.ShopData != nil {
-							game.InitShop(shopTile.ShopData)
-							game.ShopActive = true
-							shopFound = true
-							break
-						}
+				if game.AdjacentShop() != nil {
+					if game.act(engine.Action{Kind: engine.ActOpenShop}) {
+						game.ShopSelected = 0
 					}
-				}
-				// If no shop found, use original behavior (move through link)
-				if !shopFound && len(cur.Links) > 0 {
-					game.Player.At = cur.Links[0]
+				} else if game.Debug {
+					// If no shop found, use original behavior (move through link)
+					game.act(engine.Action{Kind: engine.ActFollowLink})
 				}
 			}
-		case PhaseTargetSelect:
+		case engine.PhaseTargetSelect:
 			// cycle through all legal landing spots
 			if rl.IsKeyPressed(rl.KeyRight) || rl.IsKeyPressed(rl.KeyDown) {
 				if len(game.Dests) > 0 {
@@ -438,52 +599,40 @@ func main() {
 				}
 			}
 			// confirm: build path and start animating
-			if rl.IsKeyPressed(rl.KeyEnter) || rl.IsKeyPressed(rl.KeyKpEnter) {
-				target := game.Dests[game.Selected]
-				game.Path = buildPathTo(&world, game.Player.At, game.LastRoll, target)
-				game.StepsRemaining = len(game.Path) // drive animation by path length now
-				game.Phase = PhaseAnimating
+			if (rl.IsKeyPressed(rl.KeyEnter) || rl.IsKeyPressed(rl.KeyKpEnter)) && game.Selected < len(game.Dests) {
+				game.act(engine.Action{Kind: engine.ActChooseDest, Tile: game.Dests[game.Selected]})
 			}
-			// cancel
+			// nowhere to land: pass
 			if rl.IsKeyPressed(rl.KeyEscape) {
-				game.Phase = PhaseIdle
-				game.Dests = nil
+				game.act(engine.Action{Kind: engine.ActCancelDest})
+			}
+			// stretch the roll with a stride spell
+			if slot := game.Current().SpellSlot(engine.SpellStride); rl.IsKeyPressed(rl.KeyC) && slot >= 0 {
+				if game.act(engine.Action{Kind: engine.ActCastSpell, Slot: slot}) {
+					game.Selected = 0
+				}
+			}
+			// a guide rerolls the die
+			if rl.IsKeyPressed(rl.KeyG) && game.CanReroll() {
+				if game.act(engine.Action{Kind: engine.ActReroll}) {
+					game.Selected = 0
+				}
 			}
 
-		case PhaseAnimating:
+		case engine.PhaseAnimating:
 			// no input while animating
 		}
 	AFTER_INPUT:
 		// Auto-resolve movement for the current roll
-		game.Update(dt, &world)
+		game.Update(dt, game.World)
 		// Smoothly follow player
-		updateCamera(&cam, playerPos(&world, &game), dt)
+		updateCamera(&cam, playerPos(game.World, &game), dt)
 		// Draw
-		drawWorld(&world, &game)
+		drawWorld(game.World, &game)
 	}
 }
 
-// BFS over Links only (zero-cost moves)
-func collectLinkClosure(start TileID, w *World) []TileID {
-	q := []TileID{start}
-	seen := map[TileID]bool{start: true}
-	out := []TileID{start}
-	for len(q) > 0 {
-		cur := q[0]
-		q = q[1:]
-		t := &w.Loops[cur.Loop].Tiles[cur.Index]
-		for _, nb := range t.Links {
-			if !seen[nb] {
-				seen[nb] = true
-				out = append(out, nb)
-				q = append(q, nb)
-			}
-		}
-	}
-	return out
-}
-
-func drawLoops(w *World, highlights map[TileID]rl.Color) {
+func drawLoops(w *engine.World, highlights map[engine.TileID]rl.Color) {
 	for li, loop := range w.Loops {
 		// base fill from LoopType, with safe defaults
 		baseFill := loop.Type.Color
@@ -508,7 +657,7 @@ func drawLoops(w *World, highlights map[TileID]rl.Color) {
 			}
 
 			// highlights win visually
-			if c, ok := highlights[TileID{Loop: li, Index: i}]; ok {
+			if c, ok := highlights[engine.TileID{Loop: li, Index: i}]; ok {
 				fill = c
 			}
 
@@ -527,6 +676,19 @@ func drawLoops(w *World, highlights map[TileID]rl.Color) {
 			// Draw outline
 			rl.DrawRectangleLines(x, y, int32(tileSize), int32(tileSize), outline)
 
+			// the region's boss waits here
+			if t.Boss {
+				drawBossGlyph(t.Pos.X, t.Pos.Y, tileSize*0.8)
+			}
+			// a monster somebody walked away from, or lost to, is still here
+			if c := t.Card; c != nil && c.FightStat() != "" {
+				col := monsterRed
+				if c.FightStat() == engine.StatMagic {
+					col = monsterViolet
+				}
+				drawMonsterGlyph(t.Pos.X, t.Pos.Y, tileSize*0.8, col, fmt.Sprint(c.Power()))
+			}
+
 			// Special effects for shops
 			if t.Shop {
 				centerX := int32(t.Pos.X)
@@ -572,31 +734,103 @@ func drawLoops(w *World, highlights map[TileID]rl.Color) {
 				}
 			}
 		}
+
+		if loop.Type.Crown {
+			// the crown sits in the hollow middle of the loop
+			lo, hi := loop.Tiles[0].Pos, loop.Tiles[0].Pos
+			for _, t := range loop.Tiles {
+				if t.Pos.X < lo.X {
+					lo.X = t.Pos.X
+				}
+				if t.Pos.Y < lo.Y {
+					lo.Y = t.Pos.Y
+				}
+				if t.Pos.X > hi.X {
+					hi.X = t.Pos.X
+				}
+				if t.Pos.Y > hi.Y {
+					hi.Y = t.Pos.Y
+				}
+			}
+			size := (hi.X - lo.X) * 0.6
+			if hi.Y-lo.Y < hi.X-lo.X {
+				size = (hi.Y - lo.Y) * 0.6
+			}
+			if size < tileSize {
+				size = tileSize
+			}
+			drawCrownGlyph((lo.X+hi.X)/2, (lo.Y+hi.Y)/2, size)
+		}
 	}
 }
-func drawPlayer(p Player, w *World) {
-	pos := w.Loops[p.At.Loop].Tiles[p.At.Index].Pos
+func drawPlayer(p engine.Player, pos rl.Vector2, scale float32, active bool) {
 	x, y := int32(pos.X), int32(pos.Y)
+	r := func(v float32) float32 { return v * scale }
+	playerColor := p.Color
 
+	// Whoever's turn it is gets a gold ring
+	if active {
+		rl.DrawCircleLines(x, y, r(15), hudAccent)
+		rl.DrawCircleLines(x, y, r(16), hudAccent)
+	}
 	// Draw player as a more detailed character token
 	// Outer glow
-	rl.DrawCircle(x, y, 13, rl.Fade(playerColor, 0.3))
+	rl.DrawCircle(x, y, r(13), rl.Fade(playerColor, 0.3))
 	// Main body
-	rl.DrawCircle(x, y, 10, playerColor)
+	rl.DrawCircle(x, y, r(10), playerColor)
 	// Inner highlight
-	rl.DrawCircle(x-2, y-2, 4, lighten(playerColor, 0.4))
+	rl.DrawCircle(x-int32(r(2)), y-int32(r(2)), r(4), lighten(playerColor, 0.4))
 	// Outline
-	rl.DrawCircleLines(x, y, 10, darken(playerColor, 0.5))
+	rl.DrawCircleLines(x, y, r(10), darken(playerColor, 0.5))
 }
 
-func drawWorld(w *World, g *Game) {
+// drawPlayers draws every hero. Heroes sharing a tile shrink and spread out
+// around its centre so all tokens stay visible; the active one is drawn last.
+func drawPlayers(g *Game, w *engine.World) {
+	byTile := map[engine.TileID][]int{}
+	for i, p := range g.Players {
+		byTile[p.At] = append(byTile[p.At], i)
+	}
+	order := make([]int, 0, len(g.Players))
+	for i := range g.Players {
+		if i != g.Active {
+			order = append(order, i)
+		}
+	}
+	order = append(order, g.Active)
+
+	for _, i := range order {
+		p := g.Players[i]
+		pos := vec(w.Loops[p.At.Loop].Tiles[p.At.Index].Pos)
+		scale := float32(1)
+		if here := byTile[p.At]; len(here) > 1 {
+			slot := slices.Index(here, i)
+			angle := 2*math.Pi*float64(slot)/float64(len(here)) - math.Pi/4
+			pos.X += float32(math.Cos(angle)) * tileSize / 4
+			pos.Y += float32(math.Sin(angle)) * tileSize / 4
+			scale = 0.6
+		}
+		drawPlayer(p, pos, scale, i == g.Active)
+	}
+}
+
+func drawWorld(w *engine.World, g *Game) {
 	rl.BeginDrawing()
 	// Talisman-inspired parchment background
 	rl.ClearBackground(rl.NewColor(240, 235, 220, 255))
 
 	// Build highlight map (only when choosing a direction)
-	hi := map[TileID]rl.Color{}
-	if g.Phase == PhaseTargetSelect && len(g.Dests) > 0 {
+	hi := map[engine.TileID]rl.Color{}
+	if g.Teleporting {
+		for i, id := range g.TeleportDests {
+			col := rl.NewColor(150, 100, 200, 180) // arcane purple for teleport targets
+			if i == g.TeleportSelected {
+				col = rl.NewColor(255, 215, 0, 220)
+			}
+			hi[id] = col
+		}
+	}
+	if g.Phase == engine.PhaseTargetSelect && len(g.Dests) > 0 {
 		for i, id := range g.Dests {
 			// Mystical blue glow for possible destinations
 			col := rl.NewColor(100, 150, 220, 180)
@@ -613,7 +847,7 @@ func drawWorld(w *World, g *Game) {
 	rl.BeginMode2D(cam)
 	drawLoops(w, hi)
 	// Draw selection circle for currently selected destination
-	if g.Phase == PhaseTargetSelect && len(g.Dests) > 0 && g.Selected < len(g.Dests) {
+	if g.Phase == engine.PhaseTargetSelect && len(g.Dests) > 0 && g.Selected < len(g.Dests) {
 		selectedTile := g.Dests[g.Selected]
 		pos := w.Loops[selectedTile.Loop].Tiles[selectedTile.Index].Pos
 		centerX := int32(pos.X)
@@ -623,7 +857,7 @@ func drawWorld(w *World, g *Game) {
 			rl.DrawCircleLines(centerX, centerY, float32(28+int32(thickness)), rl.NewColor(255, 255, 255, 200))
 		}
 	}
-	drawPlayer(g.Player, w)
+	drawPlayers(g, w)
 	rl.EndMode2D()
 	drawCard(g)
 	drawLog(g)
@@ -633,6 +867,11 @@ func drawWorld(w *World, g *Game) {
 	drawInventory(g)
 	// --- Draw shop menu ---
 	drawShop(g)
+	// --- Draw load-game screen ---
+	drawLoadScreen(g)
+	// --- Draw defeat / victory screens ---
+	drawDefeat(g)
+	drawVictory(g)
 
 	rl.EndDrawing()
 }
@@ -645,7 +884,7 @@ func drawLog(g *Game) {
 	// panel geometry
 	pad := int32(10)
 	lineH := int32(20)
-	lines := int32(min(len(g.Log), logMax))
+	lines := int32(min(len(g.Log), engine.LogMax))
 	w := int32(520)
 	h := lines*lineH + pad*2
 
@@ -682,8 +921,12 @@ func max(a, b int) int {
 
 func drawCard(g *Game) {
 	// --- card modal on top (if any) ---
+	if g.CardActive && g.Battle != nil {
+		drawBattle(g)
+		return
+	}
 	if g.CardActive {
-		g.Card.Display() // Just draw the card, input is handled in main loop
+		displayCard(&g.Card, g.Current(), g.FightBonus) // Just draw the card, input is handled in main loop
 
 		// Show result message if card is resolved
 		if g.CardResolved {
@@ -713,7 +956,7 @@ func drawInventory(g *Game) {
 	// Title bar
 	rl.DrawRectangle(x, y, w, 50, rl.NewColor(30, 25, 20, 200))
 	drawText("INVENTORY", x+20, y+15, 24, hudAccent)
-	drawText("ESC/Q=close • ←→=navigate • Enter=use/exchange", w+x-370, y+15, 16, hudSub)
+	drawText("ESC/Q=close • ←→=navigate • Enter=use/equip/exchange/cast • X=drop", w+x-530, y+15, 16, hudSub)
 
 	// 4-quadrant layout with different proportions
 	leftW := int32(float32(w) * 0.6) // 60% width for left side
@@ -732,19 +975,31 @@ func drawInventory(g *Game) {
 	// BOTTOM LEFT: Exchange Buttons (smaller)
 	drawExchangeButtons(g, x+10, contentY+topH+10, leftW-20, bottomH-20)
 
-	// RIGHT SIDE: Cool Visual
-	drawInventoryVisual(g, x+leftW+10, contentY+10, rightW-20, h-70)
+	// RIGHT SIDE: worn equipment and followers above the cool visual
+	first := len(g.Current().Cards) + 3 + len(g.Current().Spells)
+	equipH := drawEquipment(g, x+leftW+10, contentY+10, rightW-20, first)
+	equipH += drawFollowers(g, x+leftW+10, contentY+20+equipH, rightW-20, first+len(engine.EquipSlots)) + 10
+	drawInventoryVisual(g, x+leftW+10, contentY+20+equipH, rightW-20, h-80-equipH)
+	if g.Status != "" {
+		drawText(g.Status, x+20, y+h-26, 16, hpWarn)
+	}
 }
 
 func drawCardGrid(g *Game, x, y, w, h int32) {
 	// Navigation instructions
 	drawText("COLLECTION - Arrow keys to scroll", x, y, 18, hudText)
+	pack := fmt.Sprintf("PACK %d/%d", g.Current().PackItems(), g.Current().PackCapacity())
+	packCol := hudSub
+	if g.Current().PackRoom() <= 0 {
+		packCol = hpWarn
+	}
+	drawText(pack, x+w-rl.MeasureText(pack, 16)-10, y+2, 16, packCol)
 
 	// Collect all cards (monsters, magic monsters, and shop items)
-	allCards := []Card{}
+	allCards := []engine.Card{}
 
 	// Add all collected cards
-	for _, card := range g.Player.Cards {
+	for _, card := range g.Current().Cards {
 		allCards = append(allCards, card)
 	}
 
@@ -791,23 +1046,17 @@ func drawCardGrid(g *Game, x, y, w, h int32) {
 		var labelText string
 		isUsable := false
 		switch card.Type {
-		case monsterType:
+		case engine.MonsterType:
 			cardColor = rl.NewColor(180, 80, 60, 180)
 			labelText = fmt.Sprintf("STR\n%d", card.Strength)
-		case magicMonsterType:
+		case engine.MagicMonsterType:
 			cardColor = rl.NewColor(120, 110, 140, 180)
 			labelText = fmt.Sprintf("MAG\n%d", card.Magic)
-		case shopItemType:
+		case engine.ShopItemType:
 			cardColor = rl.NewColor(255, 215, 0, 200) // Brighter for usable items
 			isUsable = true
-			if card.Strength > 0 && card.Magic > 0 {
-				labelText = fmt.Sprintf("+%d/+%d", card.Strength, card.Magic)
-			} else if card.Strength > 0 {
-				labelText = fmt.Sprintf("+%dS", card.Strength)
-			} else {
-				labelText = fmt.Sprintf("+%dM", card.Magic)
-			}
-		case buffType:
+			labelText = gearLabel(card)
+		case engine.BuffType:
 			cardColor = rl.NewColor(120, 180, 100, 180) // Normal opacity for non-usable items
 			isUsable = false // Buffs are not usable from inventory
 			if card.Strength > 0 && card.Magic > 0 {
@@ -817,6 +1066,19 @@ func drawCardGrid(g *Game, x, y, w, h int32) {
 			} else {
 				labelText = fmt.Sprintf("+%dM", card.Magic)
 			}
+		case engine.TalismanType:
+			cardColor = crownGold
+			labelText = "KEY"
+		case engine.CrownType:
+			cardColor = crownGold
+			labelText = "CROWN"
+		case engine.PotionType:
+			cardColor = potionRed
+			isUsable = true
+			labelText = fmt.Sprintf("+%dHP", card.Heal)
+			if card.Status.Kind != "" {
+				labelText = strings.ToUpper(string(card.Status.Kind))
+			}
 		default:
 			cardColor = rl.NewColor(100, 100, 100, 180)
 			labelText = "?"
@@ -839,7 +1101,11 @@ func drawCardGrid(g *Game, x, y, w, h int32) {
 
 		// Add "USE" indicator for usable items
 		if isUsable {
-			drawText("USE", currentX+5, currentY+5, 10, rl.NewColor(255, 255, 255, 200))
+			use := "USE"
+			if card.Type == engine.ShopItemType {
+				use = "EQUIP"
+			}
+			drawText(use, currentX+5, currentY+5, 10, rl.NewColor(255, 255, 255, 200))
 		}
 
 		currentX += cardSize + padding
@@ -862,16 +1128,17 @@ func drawExchangeButtons(g *Game, x, y, w, h int32) {
 	drawText("EXCHANGE - Arrow keys + Enter", x, y, 18, hudText)
 
 	// Side by side buttons - thinner
-	buttonW := (w - 40) / 2 // Two buttons with gaps
+	buttonW := (w - 60) / 3 // Three buttons with gaps
 	buttonH := int32(40)    // Thinner height
 	button1X := x + 10
 	button2X := button1X + buttonW + 20
+	button3X := button2X + buttonW + 20
 	buttonY := y + 40
 
 	// Calculate totals
-	monsterTotal := g.Player.GetMonsterStrengthTotal()
-	magicTotal := g.Player.GetMagicMonsterTotal()
-	totalCards := len(g.Player.Cards)
+	monsterTotal := g.Current().GetMonsterStrengthTotal()
+	magicTotal := g.Current().GetMagicMonsterTotal()
+	totalCards := len(g.Current().Cards)
 
 	// Strength Exchange Button
 	canExchangeStr := monsterTotal >= 7
@@ -913,15 +1180,131 @@ func drawExchangeButtons(g *Game, x, y, w, h int32) {
 	textW = rl.MeasureText(btn2Text, 14)
 	drawText(btn2Text, button2X+(buttonW-textW)/2, buttonY+12, 14, btn2Color)
 
+	// Spell Exchange Button - trophies of either kind
+	trophyTotal := g.Current().TrophyValue()
+	btn3Color := hudAccent
+	if trophyTotal < engine.SpellTrophyCost || len(g.Current().Spells) >= g.Current().SpellSlots() {
+		btn3Color = hudSub
+	}
+	if g.InventorySelectedIndex == totalCards+2 {
+		rl.DrawRectangle(button3X-3, buttonY-3, buttonW+6, buttonH+6, rl.Fade(hudAccent, 0.4))
+	}
+	rl.DrawRectangle(button3X, buttonY, buttonW, buttonH, rl.Fade(btn3Color, 0.3))
+	rl.DrawRectangleLines(button3X, buttonY, buttonW, buttonH, btn3Color)
+	btn3Text := fmt.Sprintf("SPELL (%d/%d)", trophyTotal, engine.SpellTrophyCost)
+	textW = rl.MeasureText(btn3Text, 14)
+	drawText(btn3Text, button3X+(buttonW-textW)/2, buttonY+12, 14, btn3Color)
+
 	// Store button bounds for click detection
 	g.StrengthButtonBounds = rl.NewRectangle(float32(button1X), float32(buttonY), float32(buttonW), float32(buttonH))
 	g.MagicButtonBounds = rl.NewRectangle(float32(button2X), float32(buttonY), float32(buttonW), float32(buttonH))
+	g.SpellButtonBounds = rl.NewRectangle(float32(button3X), float32(buttonY), float32(buttonW), float32(buttonH))
+
+	drawSpellSlots(g, x, buttonY+buttonH+12, w, totalCards+3)
+}
+
+// drawSpellSlots lists the hero's spells, empty slots included. Spell i is
+// inventory item first+i; Enter casts it.
+func drawSpellSlots(g *Game, x, y, w int32, first int) {
+	p := g.Current()
+	drawText(fmt.Sprintf("SPELLS %d/%d - Enter casts", len(p.Spells), p.SpellSlots()), x, y, 18, hudText)
+	slots := p.SpellSlots()
+	slotW := min32(150, (w-20-int32(slots-1)*10)/int32(slots))
+	slotH := int32(44)
+	for i := 0; i < slots; i++ {
+		sx := x + 10 + int32(i)*(slotW+10)
+		sy := y + 24
+		if i >= len(p.Spells) {
+			rl.DrawRectangleLines(sx, sy, slotW, slotH, rl.Fade(hudSub, 0.5))
+			drawText("empty", sx+8, sy+16, 14, rl.Fade(hudSub, 0.6))
+			continue
+		}
+		s := p.Spells[i]
+		if g.InventorySelectedIndex == first+i {
+			rl.DrawRectangle(sx-3, sy-3, slotW+6, slotH+6, rl.Fade(hudAccent, 0.4))
+		}
+		rl.DrawRectangle(sx, sy, slotW, slotH, rl.NewColor(120, 90, 170, 120))
+		rl.DrawRectangleLines(sx, sy, slotW, slotH, rl.NewColor(170, 140, 230, 255))
+		drawText(s.Title, sx+8, sy+6, 14, hudText)
+		drawText(s.Spell.Effect(), sx+8, sy+26, 12, hudSub)
+	}
+}
+
+// drawEquipment shows what the hero wears, one box per slot, and returns
+// the height it took. Slot i is inventory item first+i; Enter takes it off.
+func drawEquipment(g *Game, x, y, w int32, first int) int32 {
+	p := g.Current()
+	drawText("EQUIPMENT - Enter takes off", x, y, 18, hudText)
+	boxW, boxH := (w-10)/2, int32(44)
+	for i, slot := range engine.EquipSlots {
+		bx := x + int32(i%2)*(boxW+10)
+		by := y + 26 + int32(i/2)*(boxH+8)
+		if g.InventorySelectedIndex == first+i {
+			rl.DrawRectangle(bx-3, by-3, boxW+6, boxH+6, rl.Fade(hudAccent, 0.4))
+		}
+		c, worn := p.Worn(slot)
+		if !worn {
+			rl.DrawRectangleLines(bx, by, boxW, boxH, rl.Fade(hudSub, 0.5))
+			drawText(strings.ToUpper(string(slot)), bx+8, by+6, 12, rl.Fade(hudSub, 0.6))
+			drawText("empty", bx+8, by+24, 14, rl.Fade(hudSub, 0.6))
+			continue
+		}
+		rl.DrawRectangle(bx, by, boxW, boxH, rl.NewColor(255, 215, 0, 60))
+		rl.DrawRectangleLines(bx, by, boxW, boxH, hudAccent)
+		drawText(strings.ToUpper(string(slot))+"  "+gearLabel(c), bx+8, by+6, 12, hudSub)
+		drawText(c.Title, bx+8, by+24, 14, hudText)
+	}
+	return 26 + 2*(boxH+8)
+}
+
+// drawFollowers lists the hero's followers, empty places included, and
+// returns the height it took. Follower i is inventory item first+i; Enter
+// dismisses it.
+func drawFollowers(g *Game, x, y, w int32, first int) int32 {
+	p := g.Current()
+	drawText(fmt.Sprintf("FOLLOWERS %d/%d - Enter dismisses", len(p.Followers), engine.MaxFollowers), x, y, 18, hudText)
+	boxW, boxH := (w-20)/engine.MaxFollowers, int32(44)
+	for i := 0; i < engine.MaxFollowers; i++ {
+		bx := x + int32(i)*(boxW+10)
+		by := y + 26
+		if i >= len(p.Followers) {
+			rl.DrawRectangleLines(bx, by, boxW, boxH, rl.Fade(hudSub, 0.5))
+			drawText("empty", bx+8, by+16, 14, rl.Fade(hudSub, 0.6))
+			continue
+		}
+		c := p.Followers[i]
+		if g.InventorySelectedIndex == first+i {
+			rl.DrawRectangle(bx-3, by-3, boxW+6, boxH+6, rl.Fade(hudAccent, 0.4))
+		}
+		rl.DrawRectangle(bx, by, boxW, boxH, rl.Fade(followerTeal, 0.4))
+		rl.DrawRectangleLines(bx, by, boxW, boxH, followerTeal)
+		drawText(c.Title, bx+8, by+6, 14, hudText)
+		drawText(c.Follower.Effect(), bx+8, by+26, 11, hudSub)
+	}
+	return 26 + boxH
+}
+
+// gearLabel is a shop item's bonus in short, like "+2S" or "+1/+2".
+func gearLabel(c engine.Card) string {
+	if c.Strength > 0 && c.Magic > 0 {
+		return fmt.Sprintf("+%d/+%d", c.Strength, c.Magic)
+	} else if c.Strength > 0 {
+		return fmt.Sprintf("+%dS", c.Strength)
+	}
+	return fmt.Sprintf("+%dM", c.Magic)
+}
+
+func min32(a, b int32) int32 {
+	if a < b {
+		return a
+	}
+	return b
 }
 
 func drawInventoryVisual(g *Game, x, y, w, h int32) {
-	// Cool mystical visual on the right side
+	// Cool mystical visual on the right side, above the stats
 	centerX := x + w/2
-	centerY := y + h/2
+	centerY := y + (h-150)/2
 
 	// Draw animated mystical orbs
 	time := float32(rl.GetTime())
@@ -950,16 +1333,16 @@ func drawInventoryVisual(g *Game, x, y, w, h int32) {
 
 		switch i {
 		case 0: // Health crystal - red glow
-			intensity := float32(g.Player.Health) / 10.0
+			intensity := float32(g.Current().Health) / 10.0
 			crystalColor = rl.NewColor(220, 80, 60, uint8(255*intensity*glowIntensity))
 		case 1: // Gold crystal - golden glow
-			intensity := float32(min(g.Player.Gold, 50)) / 50.0 // Cap at 50 for visualization
+			intensity := float32(min(g.Current().Gold, 50)) / 50.0 // Cap at 50 for visualization
 			crystalColor = rl.NewColor(255, 215, 0, uint8(255*intensity*glowIntensity))
 		case 2: // Magic crystal - purple glow
-			intensity := float32(g.Player.Magic) / 30.0 // Assume max 10 for now
+			intensity := float32(g.Current().Stat(engine.StatMagic)) / 30.0 // Assume max 10 for now
 			crystalColor = rl.NewColor(120, 110, 140, uint8(255*intensity*glowIntensity))
 		case 3: // Strength crystal - green glow
-			intensity := float32(g.Player.Strength) / 30.0 // Assume max 10 for now
+			intensity := float32(g.Current().Stat(engine.StatStrength)) / 30.0 // Assume max 10 for now
 			crystalColor = rl.NewColor(120, 180, 100, uint8(255*intensity*glowIntensity))
 		}
 
@@ -987,10 +1370,170 @@ func drawInventoryVisual(g *Game, x, y, w, h int32) {
 
 	strengthColor := rl.NewColor(120, 180, 100, uint8(255*0.8))
 
-	drawText(fmt.Sprintf("Strength: %d", g.Player.Strength), x+10, statsY, 16, strengthColor)
-	drawText(fmt.Sprintf("Magic: %d", g.Player.Magic), x+10, statsY+25, 16, magicColor)
-	drawText(fmt.Sprintf("Health: %d/10", g.Player.Health), x+10, statsY+50, 16, healthColor)
-	drawText(fmt.Sprintf("Gold: %d", g.Player.Gold), x+10, statsY+75, 16, goldColor)
+	drawText("Strength: "+statText(g.Current(), engine.StatStrength), x+10, statsY, 16, strengthColor)
+	drawText("Magic: "+statText(g.Current(), engine.StatMagic), x+10, statsY+25, 16, magicColor)
+	drawText(fmt.Sprintf("Health: %d/10", g.Current().Health), x+10, statsY+50, 16, healthColor)
+	drawText(fmt.Sprintf("Gold: %d", g.Current().Gold), x+10, statsY+75, 16, goldColor)
+}
+
+func drawLoadScreen(g *Game) {
+	if !g.LoadActive {
+		return
+	}
+
+	margin := int32(200)
+	x := margin
+	y := margin - 60
+	w := int32(screenWidth) - 2*margin
+	h := int32(screenHeight) - 2*margin
+
+	// Background overlay
+	rl.DrawRectangle(0, 0, screenWidth, screenHeight, rl.NewColor(0, 0, 0, 180))
+	rl.DrawRectangle(x-6, y-6, w+12, h+12, rl.NewColor(0, 0, 0, 120))
+	rl.DrawRectangle(x, y, w, h, rl.NewColor(45, 35, 25, 250))
+	rl.DrawRectangleLines(x, y, w, h, hudAccent)
+
+	// Title bar
+	rl.DrawRectangle(x, y, w, 50, rl.NewColor(30, 25, 20, 200))
+	drawText("LOAD GAME", x+20, y+15, 24, hudAccent)
+	drawText("↑↓=choose • Enter=load • Esc/F9=close • F5 saves", w+x-360, y+15, 16, hudSub)
+
+	if len(g.LoadSaves) == 0 {
+		drawText(fmt.Sprintf("No saves in %s/ yet - press F5 while idle to save.", engine.SaveDir), x+20, y+80, 20, hudSub)
+		return
+	}
+
+	rowH := int32(40)
+	visible := int((h - 80) / rowH)
+	start := max(0, g.LoadSelected-visible+1)
+	rowY := y + 70
+	for i := start; i < len(g.LoadSaves) && i < start+visible; i++ {
+		s := g.LoadSaves[i]
+		if i == g.LoadSelected {
+			rl.DrawRectangle(x+10, rowY-4, w-20, rowH-4, rl.Fade(hudAccent, 0.25))
+			rl.DrawRectangleLines(x+10, rowY-4, w-20, rowH-4, hudAccent)
+		}
+		col := hudText
+		label := fmt.Sprintf("Turn %d   Seed %d   %s", s.Turn, s.Seed, s.Saved.Format("2006-01-02 15:04"))
+		if s.Err != nil {
+			col = hpWarn
+			label = fmt.Sprintf("unreadable: %v", s.Err)
+		}
+		drawText(label, x+24, rowY+4, 20, col)
+		drawText(s.Path, x+w-360, rowY+6, 16, hudSub)
+		rowY += rowH
+	}
+}
+
+// drawDefeat covers the board when the active hero has fallen: how the run
+// went, and what happens next under the game's death rule.
+func drawDefeat(g *Game) {
+	p := g.Current()
+	if !p.Dead || g.CardActive || g.LoadActive || g.Winner() != nil {
+		return
+	}
+
+	w := int32(640)
+	h := int32(420)
+	x := (int32(screenWidth) - w) / 2
+	y := (int32(screenHeight-menuHeight) - h) / 2
+
+	rl.DrawRectangle(0, 0, screenWidth, screenHeight, rl.NewColor(20, 0, 0, 170))
+	rl.DrawRectangle(x-6, y-6, w+12, h+12, rl.NewColor(0, 0, 0, 120))
+	rl.DrawRectangle(x, y, w, h, rl.NewColor(45, 30, 25, 250))
+	rl.DrawRectangleLines(x, y, w, h, hpWarn)
+
+	title := "DEFEAT"
+	if g.Over() {
+		title = "GAME OVER"
+	}
+	drawText(title, x+(w-rl.MeasureText(title, 40))/2, y+20, 40, hpWarn)
+	sub := fmt.Sprintf("%s has fallen in %s", p.Name, p.Stats.KilledIn)
+	drawText(sub, x+(w-rl.MeasureText(sub, 20))/2, y+70, 20, p.Color)
+	if p.Stats.KilledBy != "" {
+		by := "Slain by: " + p.Stats.KilledBy
+		drawText(by, x+(w-rl.MeasureText(by, 18))/2, y+96, 18, hudSub)
+	}
+
+	drawRunSummary(p, x+60, y+135)
+
+	var prompt string
+	switch {
+	case g.Over() && g.Net != nil:
+		prompt = "Every hero has fallen."
+	case g.Over():
+		prompt = "Enter: start a new run"
+	case g.DeathRule == engine.Respawn:
+		gold, trophies := p.RespawnCost()
+		prompt = fmt.Sprintf("Enter: rise again at the start (lose %d gold, %d trophies)", gold, trophies)
+	default:
+		prompt = "Enter: pass the dice - this hero is out of the run"
+	}
+	if g.Net != nil && !g.Net.MyTurn() && !g.Over() {
+		prompt = fmt.Sprintf("Waiting for %s…", p.Name)
+	}
+	drawText(prompt, x+(w-rl.MeasureText(prompt, 18))/2, y+h-40, 18, hudAccent)
+}
+
+// drawVictory crowns the winner once the final encounter has been closed.
+func drawVictory(g *Game) {
+	p := g.Winner()
+	if p == nil || g.CardActive || g.LoadActive {
+		return
+	}
+
+	w := int32(640)
+	h := int32(420)
+	x := (int32(screenWidth) - w) / 2
+	y := (int32(screenHeight-menuHeight) - h) / 2
+
+	rl.DrawRectangle(0, 0, screenWidth, screenHeight, rl.NewColor(30, 20, 0, 170))
+	rl.DrawRectangle(x-6, y-6, w+12, h+12, rl.NewColor(0, 0, 0, 120))
+	rl.DrawRectangle(x, y, w, h, rl.NewColor(45, 35, 25, 250))
+	rl.DrawRectangleLines(x, y, w, h, crownGold)
+	drawCrownGlyph(float32(x+w/2), float32(y+40), 56)
+
+	title := "VICTORY"
+	drawText(title, x+(w-rl.MeasureText(title, 40))/2, y+66, 40, crownGold)
+	sub := fmt.Sprintf("%s claims The Crown", p.Name)
+	drawText(sub, x+(w-rl.MeasureText(sub, 20))/2, y+112, 20, p.Color)
+
+	drawRunSummary(p, x+60, y+150)
+
+	prompt := "Enter: start a new run"
+	if g.Net != nil {
+		prompt = "The run is over."
+	}
+	drawText(prompt, x+(w-rl.MeasureText(prompt, 18))/2, y+h-40, 18, hudAccent)
+}
+
+// fightsWon is the hero's won fights, with the bosses among them.
+func fightsWon(s engine.RunStats) string {
+	if s.BossesBeaten > 0 {
+		return fmt.Sprintf("%d (%d bosses)", s.FightsWon, s.BossesBeaten)
+	}
+	return fmt.Sprintf("%d", s.FightsWon)
+}
+
+// drawRunSummary lists a hero's run stats in two columns.
+func drawRunSummary(p *engine.Player, x, y int32) {
+	s := p.Stats
+	rows := [][2]string{
+		{"Turns", fmt.Sprintf("%d", s.Turns)},
+		{"Fights won", fightsWon(s)},
+		{"Fights lost", fmt.Sprintf("%d", s.FightsLost)},
+		{"Gold earned", fmt.Sprintf("%d", s.GoldEarned)},
+		{"Gold spent", fmt.Sprintf("%d", s.GoldSpent)},
+		{"Deepest tier", fmt.Sprintf("%d", s.DeepestTier)},
+		{"Deaths", fmt.Sprintf("%d", s.Deaths)},
+		{"STR / MAG", fmt.Sprintf("%d / %d", p.Stat(engine.StatStrength), p.Stat(engine.StatMagic))},
+	}
+	for i, r := range rows {
+		cx := x + int32(i%2)*270
+		cy := y + int32(i/2)*44
+		drawText(r[0], cx, cy, 16, hudSub)
+		drawText(r[1], cx, cy+18, 22, hudText)
+	}
 }
 
 func drawShop(g *Game) {
@@ -999,17 +1542,7 @@ func drawShop(g *Game) {
 	}
 
 	// Find the current shop data to get shopkeeper type
-	var currentShop *ShopType
-	cur := g.World.Loops[g.Player.At.Loop].Tiles[g.Player.At.Index]
-	for _, link := range cur.Links {
-		if isShopTile(g.World, link) {
-			shopTile := &g.World.Loops[link.Loop].Tiles[link.Index]
-			if shopTile.ShopData != nil {
-				currentShop = shopTile.ShopData
-				break
-			}
-		}
-	}
+	currentShop := g.AdjacentShop()
 
 	// Larger margins for shop overlay
 	margin := int32(100)
@@ -1052,6 +1585,9 @@ func drawShop(g *Game) {
 		keeperType = currentShop.KeeperType
 	}
 	drawShopBottom(g, x+20, bottomY+20, w-40, bottomH-40, keeperType)
+	if g.Status != "" {
+		drawText(g.Status, x+20, y+h-26, 16, hpWarn)
+	}
 }
 
 func drawShopCards(g *Game, x, y, w, h int32) {
@@ -1071,6 +1607,10 @@ func drawShopCards(g *Game, x, y, w, h int32) {
 
 		rl.DrawRectangle(cardX, y, cardW, cardH, cardColor)
 		rl.DrawRectangleLines(cardX, y, cardW, cardH, shopGold)
+		if col, ok := rarityColor(card.Rarity); ok {
+			rl.DrawRectangleLinesEx(rl.NewRectangle(float32(cardX+2), float32(y+2), float32(cardW-4), float32(cardH-4)), 3, col)
+			drawText(strings.ToUpper(string(card.Rarity)), cardX+10, y+cardH-74, 14, col)
+		}
 
 		// Selected indicator
 		if i == g.ShopSelected {
@@ -1087,8 +1627,8 @@ func drawShopCards(g *Game, x, y, w, h int32) {
 		// Card type and stats
 		typeY := y + 35
 		switch card.Type {
-		case shopItemType:
-			drawText("MAGIC ITEM", cardX+10, typeY, 16, rl.NewColor(255, 215, 0, 255))
+		case engine.ShopItemType:
+			drawText(strings.ToUpper(string(card.Slot)), cardX+10, typeY, 16, rl.NewColor(255, 215, 0, 255))
 			if card.Strength > 0 && card.Magic > 0 {
 				drawText(fmt.Sprintf("STR: +%d, MAG: +%d", card.Strength, card.Magic), cardX+10, typeY+20, 14, hudText)
 			} else if card.Strength > 0 {
@@ -1096,24 +1636,37 @@ func drawShopCards(g *Game, x, y, w, h int32) {
 			} else if card.Magic > 0 {
 				drawText(fmt.Sprintf("Magic: +%d", card.Magic), cardX+10, typeY+20, 14, hudText)
 			}
-		case monsterType:
+		case engine.MonsterType:
 			drawText("MONSTER", cardX+10, typeY, 18, rl.NewColor(180, 80, 60, 255))
 			drawText(fmt.Sprintf("Strength: %d", card.Strength), cardX+10, typeY+25, 16, hudText)
-		case magicMonsterType:
+		case engine.MagicMonsterType:
 			drawText("MAGIC MONSTER", cardX+10, typeY, 16, rl.NewColor(120, 110, 140, 255))
 			drawText(fmt.Sprintf("Magic: %d", card.Magic), cardX+10, typeY+25, 16, hudText)
-		case buffType:
+		case engine.BuffType:
 			drawText("BLESSING", cardX+10, typeY, 18, rl.NewColor(120, 180, 100, 255))
 			if card.Strength > 0 {
 				drawText(fmt.Sprintf("Strength: +%d", card.Strength), cardX+10, typeY+25, 16, hudText)
 			} else {
 				drawText(fmt.Sprintf("Magic: +%d", card.Magic), cardX+10, typeY+25, 16, hudText)
 			}
+		case engine.TalismanType:
+			drawText("TALISMAN", cardX+10, typeY, 18, crownGold)
+			drawText("Opens The Crown", cardX+10, typeY+25, 16, hudText)
+		case engine.SpellType:
+			drawText("SPELL", cardX+10, typeY, 18, rl.NewColor(170, 140, 230, 255))
+			drawText(card.Spell.Effect(), cardX+10, typeY+25, 14, hudText)
+			drawText("cast "+card.Spell.When(), cardX+10, typeY+45, 14, hudSub)
+		case engine.PotionType:
+			drawText("POTION", cardX+10, typeY, 18, potionRed)
+			drawText(card.PotionEffect(), cardX+10, typeY+25, 14, hudText)
+		case engine.FollowerType:
+			drawText("FOLLOWER", cardX+10, typeY, 18, followerTeal)
+			drawText(card.Follower.Effect(), cardX+10, typeY+25, 14, hudText)
 		}
 
 		// Price display
 		priceY := y + cardH - 50
-		affordable := g.Player.Gold >= price
+		affordable := g.Current().Gold >= price
 		priceColor := hudAccent
 		if !affordable {
 			priceColor = rl.NewColor(180, 80, 60, 255) // Red if can't afford
@@ -1486,7 +2039,10 @@ func drawMenuBar(g *Game) {
 	rightX := int32(screenWidth) - 520
 
 	// --- LEFT: turn / steps / phase or hint ---
-	drawText(fmt.Sprintf("Turn %d", g.Turn), leftX, y+12, 26, hudText)
+	turnTxt := fmt.Sprintf("Turn %d", g.Turn)
+	drawText(turnTxt, leftX, y+12, 26, hudText)
+	// seed next to the turn counter so screenshots carry what's needed to reproduce a run
+	drawText(fmt.Sprintf("Seed %d", g.Seed), leftX+rl.MeasureText(turnTxt, 26)+16, y+18, 18, hudSub)
 
 	leftX = drawStat(leftX, y+44, "Steps", fmt.Sprintf("%d", g.StepsRemaining)) + colGap
 	if g.LastRoll > 0 {
@@ -1498,13 +2054,46 @@ func drawMenuBar(g *Game) {
 	// phase hint
 	hint := ""
 	switch g.Phase {
-	case PhaseIdle:
-		hint = "R to roll • Q for inventory"
-	case PhaseTargetSelect:
-		hint = "←/→ pick • Enter confirm • Esc cancel"
-	case PhaseAnimating:
+	case engine.PhaseIdle:
+		hint = "R to roll • Q for inventory • F5 save • F9 load • F6 recording"
+		if g.Net != nil {
+			hint = "R to roll • Q for inventory"
+		}
+		if g.Current().SpellSlot(engine.SpellTeleport) >= 0 && !g.Moved {
+			hint += " • T teleport"
+		}
+		if g.Moved {
+			hint = "E shop • Q inventory • N end turn"
+		}
+		if g.Current().Dead || g.Winner() != nil {
+			hint = "Enter to continue"
+		}
+	case engine.PhaseTargetSelect:
+		hint = "←/→ pick • Enter confirm"
+		if len(g.Dests) == 0 {
+			hint = "Nowhere to land • Esc pass"
+		}
+		if g.Current().SpellSlot(engine.SpellStride) >= 0 {
+			hint += " • C stride"
+		}
+		if g.CanReroll() {
+			hint += fmt.Sprintf(" • G reroll (%d left)", g.Current().Rerolls()-g.RerollsUsed)
+		}
+	case engine.PhaseAnimating:
 		hint = "Resolving…"
 	}
+	if g.Teleporting {
+		hint = "←/→ pick a shop • Enter teleport • Esc cancel"
+	}
+	if g.Replay != nil {
+		hint = fmt.Sprintf("Replaying %d/%d…", g.Replay.Next, len(g.Replay.Actions))
+	}
+	switch {
+	case g.Status != "":
+		hint = g.Status
+	case g.Net != nil && !g.Net.MyTurn():
+		hint = fmt.Sprintf("You are %s • waiting for %s…", g.Players[g.Net.Seat].Name, g.Current().Name)
+	}
 	if hint != "" {
 		drawText(hint, padding, y+menuHeight-28, 20, hudSub)
 	}
@@ -1515,29 +2104,80 @@ func drawMenuBar(g *Game) {
 	statX := midX
 
 	// Strength
-	statX = drawStat(statX, y+10, "STR", fmt.Sprintf("%d", g.Player.Strength))
+	statX = drawStat(statX, y+10, "STR", statText(g.Current(), engine.StatStrength))
 
 	// Magic (NEW)
-	statX = drawStat(statX, y+10, "MAG", fmt.Sprintf("%d", g.Player.Magic))
+	statX = drawStat(statX, y+10, "MAG", statText(g.Current(), engine.StatMagic))
 
 	// Gold
-	statX = drawStat(statX, y+10, "GOLD", fmt.Sprintf("%d", g.Player.Gold))
+	statX = drawStat(statX, y+10, "GOLD", fmt.Sprintf("%d", g.Current().Gold))
 
 	// HP block (unchanged)
 	lbl := "HP"
-	if g.Player.Health <= 3 {
+	if g.Current().Health <= 3 {
 		lbl = "HP (low)"
 	}
 	drawText(lbl, statX, y+10, 18, hudSub)
-	drawHPBar(statX, y+32, 200, 18, g.Player.Health, 10)
-	drawText(fmt.Sprintf("%d/10", g.Player.Health), statX+210, y+28, 22, hudText)
+	drawHPBar(statX, y+32, 200, 18, g.Current().Health, 10)
+	drawText(fmt.Sprintf("%d/10", g.Current().Health), statX+210, y+28, 22, hudText)
+
+	// timed effects and the turns they have left
+	drawStatuses(g.Current(), midX, y+64)
 
 	// separator
 	drawHairlineY(int32(screenWidth)/2+240, y+10, y+menuHeight-10)
 
 	// --- RIGHT: collected cards (disabled - now shown in inventory) ---
-	drawText("Cards: Use Q to view inventory", rightX, y+12, 18, hudSub)
-	// drawCardStrip(rightX, y+36, 500, g.Player.Cards) // Disabled
+	if len(g.Players) == 1 {
+		drawText("Cards: Use Q to view inventory", rightX, y+12, 18, hudSub)
+		// drawCardStrip(rightX, y+36, 500, g.Current().Cards) // Disabled
+		return
+	}
+
+	// whose turn it is, under the stats
+	cur := g.Current()
+	drawText(fmt.Sprintf("%s's turn", cur.Name), midX, y+menuHeight-30, 20, cur.Color)
+
+	// hot-seat: one small panel per hero, two columns of up to three
+	panelW := int32(250)
+	panelH := int32(32)
+	for i, p := range g.Players {
+		px := rightX + int32(i/3)*(panelW+10)
+		py := y + 10 + int32(i%3)*(panelH+4)
+		drawPlayerPanel(p, px, py, panelW, panelH, i == g.Active)
+	}
+}
+
+func drawPlayerPanel(p engine.Player, x, y, w, h int32, active bool) {
+	name := p.Name
+	if p.Bot != "" {
+		name += " (" + string(p.Bot) + ")"
+	}
+	rl.DrawRectangle(x, y, w, h, rl.NewColor(255, 255, 255, 10))
+	border := hudLine
+	if active {
+		border = hudAccent
+	}
+	rl.DrawRectangleLines(x, y, w, h, border)
+	// colour swatch matches the token on the board
+	rl.DrawCircle(x+14, y+h/2, 8, p.Color)
+	rl.DrawCircleLines(x+14, y+h/2, 8, darken(p.Color, 0.5))
+	if p.HasTalisman() {
+		rl.DrawCircleLines(x+14, y+h/2, 11, crownGold) // may enter The Crown
+	}
+	drawText(name, x+28, y+7, 18, hudText)
+	stats := fmt.Sprintf("S%d M%d G%d HP%d", p.Stat(engine.StatStrength), p.Stat(engine.StatMagic), p.Gold, p.Health)
+	col := hudSub
+	if p.Health <= 3 {
+		col = hpWarn
+	}
+	if p.Dead {
+		stats = "fallen"
+	}
+	if p.Won {
+		stats, col = "crowned", crownGold
+	}
+	drawText(stats, x+w-rl.MeasureText(stats, 16)-8, y+9, 16, col)
 }
 
 // --- HUD palette - Talisman-inspired ---
@@ -1557,6 +2197,44 @@ func drawHairlineX(x1, x2, y int32) {
 	rl.DrawLine(x1, y, x2, y, hudLine)
 }
 
+// statText is the hero's value for s, with what its worn items and curses
+// add or take away.
+func statText(p *engine.Player, s engine.Stat) string {
+	base := p.Strength
+	if s == engine.StatMagic {
+		base = p.Magic
+	}
+	if d := p.Stat(s) - base; d != 0 {
+		return fmt.Sprintf("%d (%+d)", p.Stat(s), d)
+	}
+	return fmt.Sprintf("%d", p.Stat(s))
+}
+
+// drawStatuses lists the hero's timed effects as chips, each with the turns
+// it has left.
+func drawStatuses(p *engine.Player, x, y int32) {
+	for _, s := range p.Statuses {
+		col := statusColor(s.Kind)
+		txt := fmt.Sprintf("%s %d", strings.ToUpper(string(s.Kind)), s.Turns)
+		w := rl.MeasureText(txt, 16) + 16
+		rl.DrawRectangle(x, y, w, 22, rl.Fade(col, 0.25))
+		rl.DrawRectangleLines(x, y, w, 22, col)
+		drawText(txt, x+8, y+3, 16, hudText)
+		x += w + 6
+	}
+}
+
+// statusColor is the chip colour for a kind of status.
+func statusColor(k engine.StatusKind) rl.Color {
+	switch k {
+	case engine.StatusPoison:
+		return hpOK
+	case engine.StatusCurse:
+		return rl.NewColor(150, 90, 170, 255)
+	}
+	return crownGold
+}
+
 func drawStat(x, y int32, label string, value string) int32 {
 	lh := int32(18)
 	drawText(label, x, y, 18, hudSub)
@@ -1585,17 +2263,23 @@ func drawHPBar(x, y, w, h int32, hp, maxHP int) {
 	rl.DrawRectangle(x, y, fill, h, col)
 }
 
-func drawCardStrip(x, y int32, maxW int32, cards []Card) {
+func drawCardStrip(x, y int32, maxW int32, cards []engine.Card) {
 	cursor := x
 	const chipH int32 = 26
 	for i, c := range cards {
 		title := ""
-		if c.Type == monsterType {
+		if c.Type == engine.MonsterType {
 			title = fmt.Sprintf("Monster %d", c.Strength)
-		} else if c.Type == buffType {
+		} else if c.Type == engine.BuffType {
 			title = fmt.Sprintf("Buff +%d", c.Strength)
-		} else if c.Type == magicMonsterType {
+		} else if c.Type == engine.MagicMonsterType {
 			title = fmt.Sprintf("Magic Monster %d", c.Magic)
+		} else if c.Type == engine.TalismanType {
+			title = "Talisman"
+		} else if c.Type == engine.CrownType {
+			title = "The Crown"
+		} else if c.Type == engine.PotionType {
+			title = fmt.Sprintf("Potion +%d", c.Heal)
 		}
 		w := int32(rl.MeasureText(title, 18)) + 20
 		if cursor+w > x+maxW {
@@ -1607,12 +2291,16 @@ func drawCardStrip(x, y int32, maxW int32, cards []Card) {
 		}
 		// chip with Talisman-inspired colors
 		bg := rl.NewColor(160, 140, 100, 40) // Default bronze
-		if c.Type == buffType {
+		if c.Type == engine.BuffType {
 			bg = rl.NewColor(120, 180, 100, 60) // Forest green for buffs
-		} else if c.Type == monsterType {
+		} else if c.Type == engine.MonsterType {
 			bg = rl.NewColor(180, 80, 60, 60) // Danger red for monsters
-		} else if c.Type == magicMonsterType {
+		} else if c.Type == engine.MagicMonsterType {
 			bg = rl.NewColor(120, 110, 140, 60) // Mystical purple for magic monsters
+		} else if c.Type == engine.TalismanType || c.Type == engine.CrownType {
+			bg = rl.Fade(crownGold, 0.4)
+		} else if c.Type == engine.PotionType {
+			bg = rl.Fade(potionRed, 0.4)
 		}
 		rl.DrawRectangleRounded(rl.NewRectangle(float32(cursor), float32(y), float32(w), float32(chipH)), 0.35, 8, bg)
 		rl.DrawRectangleRoundedLines(rl.NewRectangle(float32(cursor), float32(y), float32(w), float32(chipH)), 0.35, 8, rl.NewColor(255, 255, 255, 32))
@@ -1628,280 +2316,10 @@ func maxI32(a, b int32) int32 {
 	return b
 }
 
-func BuildWorldCountsRandom(counts []int) World {
-	// even counts
-	for i, n := range counts {
-		if n%2 != 0 {
-			counts[i] = n + 1
-		}
-	}
-
-	g := Grid{Origin: rl.NewVector2(screenWidth/2, screenHeight/2), Cell: tileSize}
-
-	// dims
-	cols := make([]int, len(counts))
-	rows := make([]int, len(counts))
-	for i, n := range counts {
-		c, r, ok := rectDimsForPerimeter(n)
-		if !ok {
-			panic("cannot factor even tile count")
-		}
-		cols[i], rows[i] = c, r
-	}
-
-	// placement specs + which earlier loop each loop is anchored to
-	specs := make([]rectSpec, len(counts))
-	parent := make([]int, len(counts)) // parent[i] = anchor loop index; -1 for the first
-	occ := map[cell]bool{}
-
-	// seed: first loop at (0,0)
-	specs[0] = rectSpec{gx: 0, gy: 0, cols: cols[0], rows: rows[0]}
-	parent[0] = -1
-	markRectPerimeter(occ, specs[0].gx, specs[0].gy, specs[0].cols, specs[0].rows)
-
-	// place the rest; sometimes attach to a random previous loop instead of (i-1)
-	for i := 1; i < len(counts); i++ {
-		// candidate anchors in priority order
-		anchors := []int{i - 1}
-		if i >= 2 && rand.Float32() < crossLinkChance {
-			anchors = append([]int{rand.Intn(i - 1)}, anchors...) // pick from 0..i-2
-		}
-
-		placed := false
-		for _, aidx := range anchors {
-			A := specs[aidx]
-			for _, d := range shuffledDirs() {
-				pr := placeAround(A, cols[i], rows[i], d)
-
-				// corridor must be free; B perimeter must be free
-				if anyOccupied(occ, pr.bridge1, pr.bridge2) {
-					continue
-				}
-				if wouldCollideRectPerimeter(occ, pr.specB.gx, pr.specB.gy, pr.specB.cols, pr.specB.rows) {
-					continue
-				}
-
-				// accept
-				specs[i] = pr.specB
-				parent[i] = aidx
-				markRectPerimeter(occ, pr.specB.gx, pr.specB.gy, pr.specB.cols, pr.specB.rows)
-				markCells(occ, pr.bridge1, pr.bridge2)
-				placed = true
-				break
-			}
-			if placed {
-				break
-			}
-		}
-
-		if !placed {
-			// fallback: attach to previous on the right
-			pr := placeAround(specs[i-1], cols[i], rows[i], Right)
-			specs[i] = pr.specB
-			parent[i] = i - 1
-			markRectPerimeter(occ, pr.specB.gx, pr.specB.gy, pr.specB.cols, pr.specB.rows)
-			markCells(occ, pr.bridge1, pr.bridge2)
-		}
-	}
-
-	// build loops
-	world := World{Loops: make([]Loop, 0, len(counts)+(len(counts)-1))}
-	for i := range counts {
-		world.Loops = append(world.Loops, buildRectPerimeterLoopAtGrid(
-			g, specs[i].gx, specs[i].gy, specs[i].cols, specs[i].rows, i))
-	}
-	finalizeLoopIndices(&world)
-
-	// connect each loop i to its chosen anchor parent[i] with a 2-tile bridge
-	for i := 1; i < len(specs); i++ {
-		j := parent[i]
-		if j < 0 {
-			continue
-		}
-
-		// recompute the exact corridor cells and facing mids
-		dir := inferDir(specs[j], specs[i])
-		pr := placeAround(specs[j], specs[i].cols, specs[i].rows, dir)
-
-		ai := findTileIndexAtCell(g, world.Loops[j], pr.aMidX, pr.aMidY)
-		bi := findTileIndexAtCell(g, world.Loops[i], pr.bMidX, pr.bMidY)
-
-		bridgeLoop := makeBridge(g, pr.bridge1.X, pr.bridge1.Y, pr.bridge2.X, pr.bridge2.Y)
-		world.Loops = append(world.Loops, bridgeLoop)
-		bridgeLoopIdx := len(world.Loops) - 1
-		finalizeLoopIndices(&world)
-
-		// one-to-one links
-		world.Loops[bridgeLoopIdx].Tiles[0].Links = []TileID{{Loop: j, Index: ai}}
-		world.Loops[bridgeLoopIdx].Tiles[1].Links = []TileID{{Loop: i, Index: bi}}
-		world.Loops[j].Tiles[ai].Links = []TileID{{Loop: bridgeLoopIdx, Index: 0}}
-		world.Loops[i].Tiles[bi].Links = []TileID{{Loop: bridgeLoopIdx, Index: 1}}
-	}
-	addExtraCycleBridges(g, specs, &world, occ)
-
-	spawnShops(g, specs, &world, occ)
-	return world
-}
-
-// infer which cardinal placement produced b from a
-func inferDir(a, b rectSpec) Dir {
-	_, ayR := sideMidCell(a.gx, a.gy, a.cols, a.rows, "right")
-	if b.gx == a.gx+a.cols+2 && b.gy == ayR-b.rows/2 {
-		return Right
-	}
-	_, ayL := sideMidCell(a.gx, a.gy, a.cols, a.rows, "left")
-	if b.gx == a.gx-2-b.cols && b.gy == ayL-b.rows/2 {
-		return Left
-	}
-	axB, _ := sideMidCell(a.gx, a.gy, a.cols, a.rows, "bottom")
-	if b.gx == axB-b.cols/2 && b.gy == a.gy+a.rows+2 {
-		return Down
-	}
-	axT, _ := sideMidCell(a.gx, a.gy, a.cols, a.rows, "top")
-	if b.gx == axT-b.cols/2 && b.gy == a.gy-2-b.rows {
-		return Up
-	}
-	return Right // fallback
-}
-func spawnShops(g Grid, specs []rectSpec, world *World, occ map[cell]bool) {
-	shopCounter := 0
-	for li := range specs {
-		// only consider the original loops (skip bridge loops added later)
-		if li >= len(specs) {
-			break
-		}
-
-		loop := &world.Loops[li]
-		sp := specs[li]
-
-		for ti := range loop.Tiles {
-			pt := &loop.Tiles[ti]
-			if pt.Bridge { // skip bridge endpoints
-				continue
-			}
-			if rand.Float32() >= shopChance {
-				continue
-			}
-
-			cx, cy := g.CellOf(pt.Pos)
-			dx, dy, ok := outwardDirForCellOnRect(sp, cx, cy)
-			if !ok {
-				continue // safety: tile not recognized as perimeter
-			}
-			shopCell := cell{cx + dx, cy + dy}
-			if anyOccupied(occ, shopCell) {
-				continue // don't overlap existing things
-			}
-
-			// create the 1-tile shop loop with persistent inventory
-			shop := makeShop(g, shopCell.X, shopCell.Y)
-
-			// Initialize shop with unique inventory
-			keeperType := rand.Intn(5) // Random shopkeeper type (0-4)
-			var shopName string
-			switch keeperType {
-			case 0:
-				shopNames := []string{"Mystic Emporium", "Arcane Artifacts", "Crystal Cave", "Wizard's Workshop", "Magic Mirror Shop"}
-				shopName = shopNames[rand.Intn(len(shopNames))]
-			case 1:
-				shopNames := []string{"Ogre's Armory", "Beast & Bone", "Iron Fist Trading", "Brutal Bargains", "Stone Club Store"}
-				shopName = shopNames[rand.Intn(len(shopNames))]
-			case 2:
-				shopNames := []string{"Banana Bazaar", "Jungle Goods", "Monkey Business", "Vine & Vine", "Treetop Treasures"}
-				shopName = shopNames[rand.Intn(len(shopNames))]
-			case 3:
-				shopNames := []string{"Pig & Whistle", "Truffle Traders", "Muddy Boots", "Farm Fresh Finds", "Snort & Shop"}
-				shopName = shopNames[rand.Intn(len(shopNames))]
-			case 4:
-				shopNames := []string{"Ocean's Bounty", "Tidal Treasures", "Deep Sea Depot", "Whale Song Shop", "Coral Curiosities"}
-				shopName = shopNames[rand.Intn(len(shopNames))]
-			}
-			shopData := &ShopType{
-				Name:       shopName,
-				Discovered: false,
-				KeeperType: keeperType,
-			}
-			// Generate 3 random shop cards
-			for i := 0; i < 3; i++ {
-				shopData.Cards[i] = RandShopCard(shopData.KeeperType)
-				// Calculate price based on card attributes
-				baseCost := 0
-				if shopData.Cards[i].Strength > 0 && shopData.Cards[i].Magic > 0 {
-					baseCost = (shopData.Cards[i].Strength + shopData.Cards[i].Magic) * 4 // Mixed items cost more
-				} else {
-					baseCost = max(shopData.Cards[i].Strength, shopData.Cards[i].Magic) * 3
-				}
-				shopData.Prices[i] = baseCost + rand.Intn(5) + 1 // Add 1-5 gold randomness
-			}
-
-			shop.Tiles[0].ShopData = shopData
-			world.Loops = append(world.Loops, shop)
-			shopIdx := len(world.Loops) - 1
-			finalizeLoopIndices(world)
-
-			// link shop <-> perimeter tile
-			world.Loops[shopIdx].Tiles[0].Links = []TileID{{Loop: li, Index: ti}}
-			pt.Links = append(pt.Links, TileID{Loop: shopIdx, Index: 0})
-
-			// reserve the cell so nothing else spawns here
-			markCells(occ, shopCell)
-			shopCounter++
-		}
-	}
-}
-
-func cardChipColor(t CardType) rl.Color {
-	if t == monsterType || t == magicMonsterType {
+func cardChipColor(t engine.CardType) rl.Color {
+	if t == engine.MonsterType || t == engine.MagicMonsterType {
 		return rl.NewColor(235, 214, 186, 255) // light sand
 	}
 	return rl.NewColor(204, 231, 204, 255) // light green
 }
 
-// Try to add extra 2-tile bridges between any two original loops (not bridge loops)
-// when they are already placed with the exact 2-cell corridor alignment.
-func addExtraCycleBridges(g Grid, specs []rectSpec, world *World, occ map[cell]bool) {
-	n := len(specs) // only original rectangles
-DIRS:
-	for i := 0; i < n; i++ {
-		for j := i + 1; j < n; j++ {
-			// If they are placed with a 2-cell gap on any side, placeAround(spec[i], specs[j], d)
-			// will reproduce specs[j] exactly. Use that to detect adjacency.
-			for _, d := range []Dir{Right, Left, Up, Down} {
-				pr := placeAround(specs[i], specs[j].cols, specs[j].rows, d)
-				// exact match?
-				if pr.specB.gx != specs[j].gx || pr.specB.gy != specs[j].gy ||
-					pr.specB.cols != specs[j].cols || pr.specB.rows != specs[j].rows {
-					continue
-				}
-				// corridor must be free and not already used by another bridge/shop
-				if anyOccupied(occ, pr.bridge1, pr.bridge2) {
-					continue
-				}
-				// mid tiles on each loop
-				ai := findTileIndexAtCell(g, world.Loops[i], pr.aMidX, pr.aMidY)
-				bi := findTileIndexAtCell(g, world.Loops[j], pr.bMidX, pr.bMidY)
-				if ai < 0 || bi < 0 {
-					continue
-				}
-
-				// build the bridge loop
-				bridge := makeBridge(g, pr.bridge1.X, pr.bridge1.Y, pr.bridge2.X, pr.bridge2.Y)
-				world.Loops = append(world.Loops, bridge)
-				bridgeIdx := len(world.Loops) - 1
-				finalizeLoopIndices(world)
-
-				// link ends: i <-> bridge[0], j <-> bridge[1]
-				world.Loops[bridgeIdx].Tiles[0].Links = []TileID{{Loop: i, Index: ai}}
-				world.Loops[bridgeIdx].Tiles[1].Links = []TileID{{Loop: j, Index: bi}}
-				world.Loops[i].Tiles[ai].Links = append(world.Loops[i].Tiles[ai].Links, TileID{Loop: bridgeIdx, Index: 0})
-				world.Loops[j].Tiles[bi].Links = append(world.Loops[j].Tiles[bi].Links, TileID{Loop: bridgeIdx, Index: 1})
-
-				// reserve the corridor cells so nothing else uses them
-				markCells(occ, pr.bridge1, pr.bridge2)
-
-				// We found/added the closing bridge for (i,j); don't try other dirs for this pair.
-				continue DIRS
-			}
-		}
-	}
-}
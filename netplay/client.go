@@ -0,0 +1,156 @@
+package netplay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"time"
+
+	"github.com/Meduza3/talisman/engine"
+)
+
+// Client plays one hero in a game hosted by a Server.
+type Client struct {
+	Hello
+	Game *engine.Game // mirror of the server's game; only Poll and Wait change it
+
+	conn net.Conn
+	enc  *json.Encoder
+	raw  map[string]json.RawMessage // the latest value of every State field
+	msgs chan Message
+	err  error // why the connection ended; valid once msgs is closed
+}
+
+// Dial connects to a server and waits for the current state of the game.
+func Dial(addr string) (*Client, error) {
+	nc, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(nc)
+	var hello Message
+	if err := dec.Decode(&hello); err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("%s: %w", addr, err)
+	}
+	if hello.Hello == nil {
+		nc.Close()
+		return nil, fmt.Errorf("%s: %s", addr, hello.Error)
+	}
+	c := &Client{
+		Hello: *hello.Hello,
+		Game:  &engine.Game{},
+		conn:  nc,
+		enc:   json.NewEncoder(nc),
+		raw:   map[string]json.RawMessage{},
+		msgs:  make(chan Message, 64),
+	}
+	if err := c.update(hello); err != nil {
+		nc.Close()
+		return nil, err
+	}
+	go c.read(dec)
+	return c, nil
+}
+
+func (c *Client) read(dec *json.Decoder) {
+	defer close(c.msgs)
+	for {
+		var m Message
+		if err := dec.Decode(&m); err != nil {
+			c.err = err
+			return
+		}
+		c.msgs <- m
+	}
+}
+
+// Send asks the server to apply a. The answer arrives through Poll or Wait.
+func (c *Client) Send(a engine.Action) error {
+	return c.enc.Encode(request{Action: a})
+}
+
+// MyTurn reports whether this client's hero is the one to act.
+func (c *Client) MyTurn() bool { return c.Game.Active == c.Seat }
+
+// Poll applies every message that has arrived without blocking. It returns
+// the server's rejections, which wrap engine.ErrIllegalAction when the rules
+// refused the action, or the reason the connection was lost.
+func (c *Client) Poll() error {
+	var errs []error
+	for {
+		select {
+		case m, ok := <-c.msgs:
+			if !ok {
+				return errors.Join(append(errs, c.closed())...)
+			}
+			errs = append(errs, c.update(m))
+		default:
+			return errors.Join(errs...)
+		}
+	}
+}
+
+// Wait blocks until the next message arrives and applies it.
+func (c *Client) Wait(timeout time.Duration) error {
+	select {
+	case m, ok := <-c.msgs:
+		if !ok {
+			return c.closed()
+		}
+		return c.update(m)
+	case <-time.After(timeout):
+		return errors.New("timed out waiting for the server")
+	}
+}
+
+func (c *Client) Close() error { return c.conn.Close() }
+
+// update applies a message from the server. A diff replaces whole fields,
+// so it is laid over the last value of each field and the State decoded
+// afresh: decoding it over the old State would keep whatever the new value
+// leaves out.
+func (c *Client) update(m Message) error {
+	if m.State != nil {
+		var changed map[string]json.RawMessage
+		if err := json.Unmarshal(m.State, &changed); err != nil {
+			return fmt.Errorf("bad state from server: %w", err)
+		}
+		maps.Copy(c.raw, changed)
+		data, err := json.Marshal(c.raw)
+		if err != nil {
+			return err
+		}
+		var s State
+		if err := json.Unmarshal(data, &s); err != nil {
+			return fmt.Errorf("bad state from server: %w", err)
+		}
+		s.restore(c.Game)
+	}
+	if m.Error != "" {
+		return &RejectedError{Reason: m.Error, Illegal: m.Illegal}
+	}
+	return nil
+}
+
+// RejectedError is the server's answer to an action it would not apply.
+// Only rejections by the rules wrap engine.ErrIllegalAction.
+type RejectedError struct {
+	Reason  string
+	Illegal bool
+}
+
+func (e *RejectedError) Error() string { return e.Reason }
+
+func (e *RejectedError) Unwrap() error {
+	if e.Illegal {
+		return engine.ErrIllegalAction
+	}
+	return nil
+}
+
+func (c *Client) closed() error {
+	return fmt.Errorf("disconnected from server: %w", c.err)
+}
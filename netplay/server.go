@@ -0,0 +1,241 @@
+package netplay
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/Meduza3/talisman/engine"
+)
+
+// writeTimeout drops a client that stops reading rather than stalling
+// everyone else's game.
+const writeTimeout = 5 * time.Second
+
+// botLimit caps how many actions bots take in a row, so a table with no
+// humans at it cannot spin the server forever.
+const botLimit = 1000
+
+// ErrNotYourTurn is returned for actions sent by anyone but the active hero.
+var ErrNotYourTurn = fmt.Errorf("not your turn: %w", engine.ErrIllegalAction)
+
+// Server owns a game and lets one client play each hero in it. Every action
+// goes through engine.Game.Apply on the server, so clients cannot do
+// anything the rules would not let a local player do.
+type Server struct {
+	Logf func(format string, args ...any) // where connection events go; log.Printf by default
+
+	mu     sync.Mutex
+	game   *engine.Game
+	seats  []*conn // one per hero; nil while nobody has taken it
+	last   map[string]json.RawMessage
+	ln     net.Listener
+	closed bool
+}
+
+type conn struct {
+	seat int
+	c    net.Conn
+	enc  *json.Encoder
+}
+
+// NewServer hosts g. The game must not be touched by anyone else while the
+// server runs.
+func NewServer(g *engine.Game) *Server {
+	return &Server{
+		Logf:  log.Printf,
+		game:  g,
+		seats: make([]*conn, len(g.Players)),
+	}
+}
+
+// Listen opens the server's socket. Use "127.0.0.1:0" to pick a free port.
+func (s *Server) Listen(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.ln = ln
+	return nil
+}
+
+// Addr is the address clients should dial.
+func (s *Server) Addr() net.Addr { return s.ln.Addr() }
+
+// Serve accepts clients until Close is called.
+func (s *Server) Serve() error {
+	s.mu.Lock()
+	s.runBots() // in case a bot goes first
+	s.mu.Unlock()
+	for {
+		c, err := s.ln.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		go s.handle(c)
+	}
+}
+
+// Close stops accepting clients and disconnects everyone.
+func (s *Server) Close() error {
+	err := s.ln.Close()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for _, c := range s.seats {
+		if c != nil {
+			c.c.Close()
+		}
+	}
+	return err
+}
+
+// Game returns the hosted game. Callers must not use it until Close has
+// returned.
+func (s *Server) Game() *engine.Game { return s.game }
+
+func (s *Server) handle(nc net.Conn) {
+	defer nc.Close()
+	c, err := s.join(nc)
+	if err != nil {
+		s.Logf("%s: %v", nc.RemoteAddr(), err)
+		return
+	}
+	defer s.leave(c)
+
+	sc := bufio.NewScanner(nc)
+	for sc.Scan() {
+		var req request
+		if err := json.Unmarshal(sc.Bytes(), &req); err != nil {
+			s.Logf("%s: bad request: %v", nc.RemoteAddr(), err)
+			return
+		}
+		s.act(c, req.Action)
+	}
+}
+
+// join seats a new client at the first free hero and sends it the full
+// state.
+func (s *Server) join(nc net.Conn) (*conn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := &conn{seat: -1, c: nc, enc: json.NewEncoder(nc)}
+	for i, taken := range s.seats {
+		if taken == nil && s.game.Players[i].Bot == "" {
+			c.seat = i
+			break
+		}
+	}
+	if c.seat < 0 {
+		c.send(Message{Error: "game is full"})
+		return nil, errors.New("game is full")
+	}
+
+	cur, err := capture(s.game).fields()
+	if err != nil {
+		return nil, err
+	}
+	full, err := diff(nil, cur)
+	if err != nil {
+		return nil, err
+	}
+	s.last = cur
+	if err := c.send(Message{Hello: &Hello{Seat: c.seat, Seats: len(s.seats)}, State: full}); err != nil {
+		return nil, err
+	}
+	s.seats[c.seat] = c
+	s.Logf("%s joined as %s", nc.RemoteAddr(), s.game.Players[c.seat].Name)
+	return c, nil
+}
+
+func (s *Server) leave(c *conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seats[c.seat] == c {
+		s.seats[c.seat] = nil
+		s.Logf("%s left; %s is free", c.c.RemoteAddr(), s.game.Players[c.seat].Name)
+	}
+}
+
+// act applies a client's action. The sender always gets exactly one reply:
+// the rejection or the resulting diff (which may be empty). Everyone else
+// only hears about actions that changed something.
+func (s *Server) act(c *conn, a engine.Action) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	if err := s.check(c, a); err != nil {
+		c.send(Message{Error: err.Error(), Illegal: errors.Is(err, engine.ErrIllegalAction)})
+		return
+	}
+	// nobody watches the walk on the server, so land straight away
+	s.game.FinishMove()
+	s.runBots()
+
+	cur, err := capture(s.game).fields()
+	if err != nil {
+		c.send(Message{Error: err.Error()})
+		return
+	}
+	d, err := diff(s.last, cur)
+	if err != nil {
+		c.send(Message{Error: err.Error()})
+		return
+	}
+	s.last = cur
+	for _, other := range s.seats {
+		if other == nil || (other != c && d == nil) {
+			continue
+		}
+		if err := other.send(Message{State: d}); err != nil {
+			other.c.Close() // its reader notices and frees the seat
+		}
+	}
+}
+
+func (s *Server) check(c *conn, a engine.Action) error {
+	switch a.Kind {
+	case engine.ActNewWorld, engine.ActStep, engine.ActFollowLink:
+		return fmt.Errorf("%s: debug actions are disabled online: %w", a, engine.ErrIllegalAction)
+	}
+	if c.seat != s.game.Active {
+		return ErrNotYourTurn
+	}
+	return s.game.Apply(a)
+}
+
+// runBots plays the computer heroes' turns. Clients see the result as part
+// of the diff for the action that handed them the dice. A bot that picks an
+// action the rules refuse would hold the dice for good, so its seat is
+// handed to the humans: its turn ends if it can, and otherwise waits for
+// whoever joins in its place.
+func (s *Server) runBots() {
+	for {
+		err := s.game.RunBots(botLimit)
+		if err == nil {
+			return
+		}
+		p := s.game.Current()
+		s.Logf("%v; %s is free for a human", err, p.Name)
+		p.Bot = ""
+		if s.game.Apply(engine.Action{Kind: engine.ActEndTurn}) != nil {
+			return
+		}
+	}
+}
+
+func (c *conn) send(m Message) error {
+	c.c.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return c.enc.Encode(m)
+}
@@ -0,0 +1,214 @@
+package netplay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Meduza3/talisman/engine"
+)
+
+// serve hosts g on a free loopback port and seats one client per hero.
+func serve(t *testing.T, g *engine.Game) (*Server, []*Client) {
+	t.Helper()
+	s := NewServer(g)
+	s.Logf = t.Logf
+	if err := s.Listen("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve()
+	t.Cleanup(func() { s.Close() })
+
+	clients := make([]*Client, len(g.Players))
+	for i := range clients {
+		c, err := Dial(s.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { c.Close() })
+		if c.Seat != i || c.Seats != len(g.Players) {
+			t.Fatalf("client %d got seat %d of %d", i, c.Seat, c.Seats)
+		}
+		clients[i] = c
+	}
+	return s, clients
+}
+
+// synced waits for every client's mirror to catch up with the server's
+// game and fails if one does not.
+func synced(t *testing.T, s *Server, clients []*Client) {
+	t.Helper()
+	s.mu.Lock()
+	want, err := capture(s.game).fields()
+	s.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range clients {
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			got, err := capture(c.Game).fields()
+			if err != nil {
+				t.Fatal(err)
+			}
+			bad := differing(want, got)
+			if bad == "" {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("client %d: %s is %s, server has %s", i, bad, got[bad], want[bad])
+			}
+			if err := c.Poll(); err != nil {
+				t.Fatalf("client %d: %v", i, err)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+// differing names a field that is not the same in a and b, or "".
+func differing(a, b map[string]json.RawMessage) string {
+	for k, v := range a {
+		if !bytes.Equal(b[k], v) {
+			return k
+		}
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			return k
+		}
+	}
+	return ""
+}
+
+// rejected sends a from c and fails unless the server refuses it.
+func rejected(t *testing.T, c *Client, a engine.Action) *RejectedError {
+	t.Helper()
+	if err := c.Send(a); err != nil {
+		t.Fatal(err)
+	}
+	err := c.Wait(2 * time.Second)
+	var rej *RejectedError
+	if !errors.As(err, &rej) || !errors.Is(err, engine.ErrIllegalAction) {
+		t.Fatalf("%s: want a rejection, got %v", a, err)
+	}
+	return rej
+}
+
+func TestLoopbackPlay(t *testing.T) {
+	s, clients := serve(t, engine.NewGame(3, 2))
+	synced(t, s, clients)
+
+	bad := engine.TileID{Loop: -1}
+	for step := range 40 {
+		mirror := clients[0].Game
+		if mirror.Over() && !mirror.CardActive {
+			break
+		}
+		me := clients[mirror.Active]
+		other := clients[(mirror.Active+1)%len(clients)]
+		a := engine.Cautious.NextAction(me.Game)
+
+		if rej := rejected(t, other, a); rej.Reason != ErrNotYourTurn.Error() {
+			t.Fatalf("step %d: out of turn %s rejected with %q", step, a, rej.Reason)
+		}
+		if mirror.Phase == engine.PhaseTargetSelect {
+			rejected(t, me, engine.Action{Kind: engine.ActChooseDest, Tile: bad})
+		}
+		synced(t, s, clients) // rejections change nothing
+
+		if err := me.Send(a); err != nil {
+			t.Fatal(err)
+		}
+		if err := me.Wait(2 * time.Second); err != nil {
+			t.Fatalf("step %d: %s: %v", step, a, err)
+		}
+		synced(t, s, clients)
+	}
+	if clients[0].Game.Turn < 3 {
+		t.Fatalf("only %d turns were played", clients[0].Game.Turn)
+	}
+	for i, c := range clients {
+		if c.Game.World.Piles != nil {
+			t.Fatalf("client %d was sent the encounter piles", i)
+		}
+	}
+}
+
+// A diff replaces whole fields: fields the new value leaves out must not
+// survive from the old one.
+func TestUpdateReplacesFields(t *testing.T) {
+	g := engine.NewGame(1, 2)
+	c := &Client{Game: &engine.Game{}, raw: map[string]json.RawMessage{}}
+	var last map[string]json.RawMessage
+	send := func() {
+		t.Helper()
+		cur, err := capture(g).fields()
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, err := diff(last, cur)
+		if err != nil {
+			t.Fatal(err)
+		}
+		last = cur
+		if err := c.update(Message{State: d}); err != nil {
+			t.Fatal(err)
+		}
+		got, err := capture(c.Game).fields()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k := differing(cur, got); k != "" {
+			t.Fatalf("%s is %s, want %s", k, got[k], cur[k])
+		}
+	}
+
+	g.Card = engine.NewPlace("Crossroads", []engine.TableEntry{{From: 1, Effect: engine.Effect{Text: "a coin", Gold: 1}}})
+	g.Players[0].Equipped[0] = engine.Card{Type: engine.ShopItemType, Title: "Sword", Strength: 2, Slot: engine.Weapon}
+	send()
+	g.Card = engine.NewTreasure("Purse", 3, nil)
+	g.Players[0].Equipped[0] = engine.Card{}
+	send()
+	if c.Game.Card.Table != nil || c.Game.Players[0].Equipped[0].Type != "" {
+		t.Fatalf("stale fields kept: %+v", c.Game.Card)
+	}
+}
+
+func TestRejectedError(t *testing.T) {
+	c := &Client{Game: &engine.Game{}, raw: map[string]json.RawMessage{}}
+	for _, illegal := range []bool{false, true} {
+		err := c.update(Message{Error: "no", Illegal: illegal})
+		var rej *RejectedError
+		if !errors.As(err, &rej) || errors.Is(err, engine.ErrIllegalAction) != illegal {
+			t.Errorf("illegal %v: got %v", illegal, err)
+		}
+	}
+}
+
+// A bot the rules refuse hands its seat to whoever joins next instead of
+// holding the dice forever.
+func TestBrokenBotFreesSeat(t *testing.T) {
+	g := engine.NewGame(1, 2)
+	g.DeathRule = engine.Permadeath
+	g.Players[0].Bot = engine.Cautious
+	g.Players[0].Dead = true // and it has not moved, so it cannot end the turn
+	s := NewServer(g)
+	s.Logf = t.Logf
+	if err := s.Listen("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve()
+	t.Cleanup(func() { s.Close() })
+
+	c, err := Dial(s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if c.Seat != 0 || c.Game.Players[0].Bot != "" {
+		t.Fatalf("seated at %d beside bot %q, want the broken bot's seat", c.Seat, c.Game.Players[0].Bot)
+	}
+}
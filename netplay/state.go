@@ -0,0 +1,155 @@
+// Package netplay runs a game over the network. A Server owns the only real
+// engine.Game; clients send it actions and get back the parts of the state
+// that changed. Messages are JSON, one per line.
+package netplay
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/Meduza3/talisman/engine"
+)
+
+// DefaultPort is what -serve and -connect use when no port is given.
+const DefaultPort = "7777"
+
+// State is the part of a game clients can see. Each top-level field is the
+// unit of a diff: when anything inside it changes the whole field is resent.
+type State struct {
+	Seed        int64
+	Turn        int
+	Round       int
+	LastRoll    int
+	RerollsUsed int
+	Log         []string
+
+	Players   []engine.Player
+	Active    int
+	Moved     bool
+	DeathRule engine.DeathRule
+	Phase     engine.Phase
+	Dests     []engine.TileID
+
+	CardActive   bool
+	Card         engine.Card
+	CardResolved bool
+	CardMsg      string
+	FightBonus   int
+	Battle       *engine.InteractResult
+
+	ShopActive bool
+	ShopCards  [3]engine.Card
+	ShopPrices [3]int
+
+	World *engine.World // the board only: the encounter piles stay on the server
+}
+
+func capture(g *engine.Game) State {
+	return State{
+		Seed:         g.Seed,
+		Turn:         g.Turn,
+		Round:        g.Round,
+		LastRoll:     g.LastRoll,
+		RerollsUsed:  g.RerollsUsed,
+		Log:          g.Log,
+		Players:      g.Players,
+		Active:       g.Active,
+		Moved:        g.Moved,
+		DeathRule:    g.DeathRule,
+		Phase:        g.Phase,
+		Dests:        g.Dests,
+		CardActive:   g.CardActive,
+		Card:         g.Card,
+		CardResolved: g.CardResolved,
+		CardMsg:      g.CardMsg,
+		FightBonus:   g.FightBonus,
+		Battle:       g.Battle,
+		ShopActive:   g.ShopActive,
+		ShopCards:    g.ShopCards,
+		ShopPrices:   g.ShopPrices,
+		World:        board(g.World),
+	}
+}
+
+// board is w without its encounter piles. Players must not see the order
+// the cards will come up in, and a draw should not resend the whole world.
+func board(w *engine.World) *engine.World {
+	if w == nil {
+		return nil
+	}
+	b := *w
+	b.Piles = nil
+	return &b
+}
+
+// restore copies s onto a client's mirror of the game.
+func (s *State) restore(g *engine.Game) {
+	g.Seed = s.Seed
+	g.Turn = s.Turn
+	g.Round = s.Round
+	g.LastRoll = s.LastRoll
+	g.RerollsUsed = s.RerollsUsed
+	g.Log = s.Log
+	g.Players = s.Players
+	g.Active = s.Active
+	g.Moved = s.Moved
+	g.DeathRule = s.DeathRule
+	g.Phase = s.Phase
+	g.Dests = s.Dests
+	g.CardActive = s.CardActive
+	g.Card = s.Card
+	g.CardResolved = s.CardResolved
+	g.CardMsg = s.CardMsg
+	g.FightBonus = s.FightBonus
+	g.Battle = s.Battle
+	g.ShopActive = s.ShopActive
+	g.ShopCards = s.ShopCards
+	g.ShopPrices = s.ShopPrices
+	g.World = s.World
+}
+
+// fields encodes s keyed by top-level field name.
+func (s State) fields() (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]json.RawMessage
+	return out, json.Unmarshal(data, &out)
+}
+
+// diff returns the fields of cur that differ from prev as a JSON object, or
+// nil when nothing changed. Each field in it replaces the old value whole.
+func diff(prev, cur map[string]json.RawMessage) (json.RawMessage, error) {
+	changed := map[string]json.RawMessage{}
+	for k, v := range cur {
+		if !bytes.Equal(prev[k], v) {
+			changed[k] = v
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(changed)
+}
+
+// Message is one line from the server.
+type Message struct {
+	Hello *Hello          `json:",omitempty"` // first message on a new connection
+	State json.RawMessage `json:",omitempty"` // changed fields of State
+	Error string          `json:",omitempty"` // why this client's last action was rejected
+	// Illegal marks an Error that is the rules refusing the action, as
+	// opposed to the server failing to carry it out.
+	Illegal bool `json:",omitempty"`
+}
+
+// Hello tells a client which hero it plays.
+type Hello struct {
+	Seat  int // index into State.Players
+	Seats int
+}
+
+// request is one line from a client.
+type request struct {
+	Action engine.Action
+}
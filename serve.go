@@ -0,0 +1,44 @@
+package main
+
+import (
+	"log"
+	"net"
+	"os"
+	"os/signal"
+
+	"github.com/Meduza3/talisman/engine"
+	"github.com/Meduza3/talisman/netplay"
+)
+
+// runServer hosts a LAN game until interrupted, then writes its recording
+// so the session can be replayed locally.
+func runServer(addr string, g *engine.Game) {
+	srv := netplay.NewServer(g)
+	if err := srv.Listen(withPort(addr)); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("serving seed %d for %d heroes on %s", g.Seed, len(g.Players), srv.Addr())
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	go func() {
+		<-stop
+		srv.Close()
+	}()
+	if err := srv.Serve(); err != nil {
+		log.Print(err)
+	}
+	if path, err := g.SaveRecording(engine.ReplayDir, "server.json"); err != nil {
+		log.Printf("writing recording: %v", err)
+	} else {
+		log.Printf("recording written to %s", path)
+	}
+}
+
+// withPort adds netplay.DefaultPort to addresses given without one.
+func withPort(addr string) string {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return net.JoinHostPort(addr, netplay.DefaultPort)
+	}
+	return addr
+}
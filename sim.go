@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Meduza3/talisman/engine"
+)
+
+// runSim is `talisman sim`: play many headless games with a bot policy and
+// print the balance numbers as a table, optionally also as CSV.
+func runSim(args []string) {
+	fs := flag.NewFlagSet("sim", flag.ExitOnError)
+	games := fs.Int("games", 1000, "number of games to play")
+	seed := fs.Int64("seed", 1, "seed of the first game; game i uses seed+i")
+	turns := fs.Int("turns", 300, "give up on a game that has not been won or lost after this many rolls")
+	policy := fs.String("policy", string(engine.Cautious), "bot personality that plays: cautious, greedy or reckless")
+	csvPath := fs.String("csv", "", "also write the report as CSV to this file (- for stdout)")
+	dataDir := fs.String("data", "", "load decks and regions from this directory instead of the built-in data")
+	fs.Parse(args)
+
+	if *dataDir != "" {
+		if err := engine.LoadData(*dataDir); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	p, err := engine.ParsePersonality(*policy)
+	if err != nil {
+		log.Fatal(err)
+	}
+	start := time.Now()
+	rep := engine.Simulate(engine.SimConfig{Games: *games, Seed: *seed, MaxTurns: *turns, Policy: p})
+	writeSimTable(os.Stdout, rep)
+	fmt.Printf("\n%d games in %s\n", rep.Games, time.Since(start).Round(time.Millisecond))
+	for _, err := range rep.Errors {
+		log.Printf("game stopped early: %v", err)
+	}
+
+	if *csvPath == "" {
+		return
+	}
+	out := io.Writer(os.Stdout)
+	if *csvPath != "-" {
+		f, err := os.Create(*csvPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := writeSimCSV(out, rep); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func writeSimTable(w io.Writer, rep *engine.SimReport) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	cfg := rep.Config
+	fmt.Fprintf(tw, "Policy\t%s\n", cfg.Policy)
+	fmt.Fprintf(tw, "Games\t%d (seeds %d..%d)\n", rep.Games, cfg.Seed, cfg.Seed+int64(cfg.Games)-1)
+	fmt.Fprintf(tw, "Win rate\t%.1f%% (claimed The Crown)\n", 100*rep.WinRate())
+	fmt.Fprintf(tw, "Given up\t%d (still going after %d rolls)\n", rep.Timeouts, cfg.MaxTurns)
+	fmt.Fprintf(tw, "Talisman found\t%.1f%% of games\n", 100*perGame(rep.Talismans, rep.Games))
+	fmt.Fprintf(tw, "Turns played\tavg %.1f, median %d\n", rep.AvgTurns(), rep.MedianTurns())
+	fmt.Fprintf(tw, "Gold earned\t%d (%.1f per game)\n", rep.GoldEarned, perGame(rep.GoldEarned, rep.Games))
+	fmt.Fprintf(tw, "Gold spent\t%d (%.1f per game)\n", rep.GoldSpent, perGame(rep.GoldSpent, rep.Games))
+
+	fmt.Fprintln(tw, "\nDied in\tHeroes\tShare")
+	for _, name := range sortedByCount(rep.Deaths) {
+		fmt.Fprintf(tw, "%s\t%d\t%.1f%%\n", name, rep.Deaths[name], 100*float64(rep.Deaths[name])/float64(rep.Games))
+	}
+
+	fmt.Fprintln(tw, "\nDeck\tFights\tWon\tLost\tFled\tWin %\tRounds\tGold")
+	for _, name := range slices.Sorted(maps.Keys(rep.Decks)) {
+		d := rep.Decks[name]
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%.1f%%\t%.1f\t%d\n", name, d.Fights, d.Wins, d.Losses, d.Fled, 100*d.WinRate(), d.AvgRounds(), d.GoldEarned)
+	}
+	tw.Flush()
+}
+
+// writeSimCSV writes the report in long form, one number per row, so it can
+// be pivoted or diffed between tuning runs.
+func writeSimCSV(w io.Writer, rep *engine.SimReport) error {
+	cw := csv.NewWriter(w)
+	row := func(section, key, stat string, v any) {
+		cw.Write([]string{section, key, stat, fmt.Sprint(v)})
+	}
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 4, 64) }
+
+	cw.Write([]string{"section", "key", "stat", "value"})
+	row("summary", string(rep.Config.Policy), "games", rep.Games)
+	row("summary", string(rep.Config.Policy), "wins", rep.Wins)
+	row("summary", string(rep.Config.Policy), "win_rate", f(rep.WinRate()))
+	row("summary", string(rep.Config.Policy), "timeouts", rep.Timeouts)
+	row("summary", string(rep.Config.Policy), "talismans", rep.Talismans)
+	row("summary", string(rep.Config.Policy), "turns_avg", f(rep.AvgTurns()))
+	row("summary", string(rep.Config.Policy), "turns_median", rep.MedianTurns())
+	row("summary", string(rep.Config.Policy), "gold_earned", rep.GoldEarned)
+	row("summary", string(rep.Config.Policy), "gold_spent", rep.GoldSpent)
+	for _, name := range sortedByCount(rep.Deaths) {
+		row("death", name, "heroes", rep.Deaths[name])
+	}
+	for _, name := range slices.Sorted(maps.Keys(rep.Decks)) {
+		d := rep.Decks[name]
+		row("deck", name, "fights", d.Fights)
+		row("deck", name, "wins", d.Wins)
+		row("deck", name, "losses", d.Losses)
+		row("deck", name, "fled", d.Fled)
+		row("deck", name, "rounds_avg", f(d.AvgRounds()))
+		row("deck", name, "win_rate", f(d.WinRate()))
+		row("deck", name, "gold_earned", d.GoldEarned)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func perGame(n, games int) float64 {
+	if games == 0 {
+		return 0
+	}
+	return float64(n) / float64(games)
+}
+
+// sortedByCount lists m's keys, biggest count first.
+func sortedByCount(m map[string]int) []string {
+	keys := slices.Sorted(maps.Keys(m))
+	slices.SortStableFunc(keys, func(a, b string) int { return m[b] - m[a] })
+	return keys
+}